@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/gitscope"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runAnalyze(args []string, stdout, stderr io.Writer) int {
+	var af analysisFlags
+	fs := newFlagSet("analyze", "analyze [flags] [FILE...]  (default: the inputs of the configuration file)\n       docline analyze -git-range RANGE [flags] [PATH...]", stderr)
+	af.register(fs)
+	format := fs.String("format", "html", "report format (default: the configured formats, else html)")
+	output := fs.String("o", "", "report path for a single file and format (default: <results-dir>/<file>.<format>; configured inputs keep their directory relative to the configuration file)")
+	var reportParams paramFlag
+	fs.Var(&reportParams, "report-param", "report parameter as key=value, e.g. template_dir=./tmpl (repeatable)")
+	stream := fs.Bool("stream", false, "write each group to the report as the finder hands it over (with -format ndjson)")
+	gitRange := fs.String("git-range", "", "analyse the documents changed in a git revision range (e.g. main...HEAD) and keep groups touching changed lines; each document is analysed on its own, so text copied from another file is not reported; FILE arguments limit the paths")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := af.loadConfig(fs); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	formats := af.reportFormats(fs, *format)
+	params := af.reportParams(reportParams)
+	if *gitRange != "" {
+		if *output != "" || *stream {
+			fmt.Fprintln(stderr, "docline: -o and -stream cannot be combined with -git-range")
+			return exitError
+		}
+		return analyzeGitRange(&af, *gitRange, fs.Args(), formats, params, stdout, stderr)
+	}
+
+	inputs := fs.Args()
+	relDir := ""
+	if len(inputs) == 0 && af.settings != nil && len(af.settings.Inputs) > 0 {
+		var err error
+		if inputs, err = af.settings.Files(); err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+		relDir = af.configDir
+	}
+	if len(inputs) == 0 {
+		fs.Usage()
+		return exitError
+	}
+	if *output != "" && len(inputs)*len(formats) > 1 {
+		fmt.Fprintln(stderr, "docline: -o needs a single input and report format")
+		return exitError
+	}
+	if *stream && len(formats) > 1 {
+		fmt.Fprintln(stderr, "docline: -stream writes a single report format")
+		return exitError
+	}
+
+	reports := [][]string{{*output}}
+	if *output == "" {
+		var err error
+		if reports, err = reportPaths(af.resultsDir, inputs, formats, relDir); err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+	}
+
+	d := af.newDocline()
+	status := exitOK
+	for i, input := range inputs {
+		outPaths := reports[i]
+
+		if *stream {
+			result, err := d.AnalyzeDocumentStream(input, af.finder, af.finderConfig(), formats[0], outPaths[0], params)
+			if err != nil {
+				fmt.Fprintf(stderr, "docline: analyze %s: %v\n", input, err)
+				status = exitError
+				continue
+			}
+			printAnalysisSummary(stdout, input, result, outPaths[0])
+			continue
+		}
+
+		result, err := d.AnalyzeDocumentWithConfig(input, af.finder, af.finderConfig())
+		if err != nil {
+			fmt.Fprintf(stderr, "docline: analyze %s: %v\n", input, err)
+			status = exitError
+			continue
+		}
+		var written []string
+		for i, f := range formats {
+			if err := d.GenerateReportWithParams(result, f, outPaths[i], params); err != nil {
+				fmt.Fprintf(stderr, "docline: %v\n", err)
+				status = exitError
+				continue
+			}
+			written = append(written, outPaths[i])
+		}
+		printAnalysisSummary(stdout, input, result, strings.Join(written, ", "))
+	}
+	return status
+}
+
+// analyzeGitRange analyses every document changed in revRange below paths
+// (the whole repository when empty) and writes one report per document with
+// the groups that touch changed lines. Reports keep the document's directory
+// below the results directory. Each document is analysed on its own, like
+// any other input, so duplication shared with other files is not found.
+func analyzeGitRange(af *analysisFlags, revRange string, paths []string, formats []string, reportParams map[string]interface{}, stdout, stderr io.Writer) int {
+	repo, err := gitscope.Open(".", revRange)
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	changed, err := repo.ChangedLines()
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+
+	var prefixes []string
+	for _, p := range paths {
+		rel, err := repoRelative(repo.Root, p)
+		if err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+		prefixes = append(prefixes, rel)
+	}
+	documents := extensionSet(documentExtensions)
+	var files []string
+	for file := range changed {
+		if documents[strings.ToLower(filepath.Ext(file))] && underAny(file, prefixes) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	// Binary documents (.doc, .docx, .odt) have no changed lines to scope to.
+	binaries, err := repo.BinaryFiles()
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	for _, file := range binaries {
+		if documents[strings.ToLower(filepath.Ext(file))] && underAny(file, prefixes) {
+			fmt.Fprintf(stderr, "docline: %s: binary document not supported with -git-range, skipped\n", file)
+		}
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(stdout, "no documents changed in %s\n", revRange)
+		return exitOK
+	}
+
+	d := af.newDocline()
+	status := exitOK
+	for _, file := range files {
+		source, err := repo.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "docline: %s: %v\n", file, err)
+			status = exitError
+			continue
+		}
+		result, err := gitscope.Analyze(d, file, source, changed[file], af.finder, af.finderConfig())
+		if err != nil {
+			fmt.Fprintf(stderr, "docline: analyze %s: %v\n", file, err)
+			status = exitError
+			continue
+		}
+		result.Metadata[gitscope.RangeMetadataKey] = revRange
+
+		var outPaths []string
+		for _, format := range formats {
+			outPath := nestedReportPath(af.resultsDir, file, format)
+			if err := d.GenerateReportWithParams(result, format, outPath, reportParams); err != nil {
+				fmt.Fprintf(stderr, "docline: %v\n", err)
+				status = exitError
+				continue
+			}
+			outPaths = append(outPaths, outPath)
+		}
+		printAnalysisSummary(stdout, file, result, strings.Join(outPaths, ", "))
+	}
+	return status
+}
+
+// repoRelative returns path relative to the repository root, slash-separated.
+func repoRelative(root, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository %s", path, root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// underAny reports whether the slash-separated file is one of prefixes or
+// below one of them; no prefixes match everything.
+func underAny(file string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if p == "." || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func printAnalysisSummary(w io.Writer, input string, result *docline.AnalysisResult, outPath string) {
+	fmt.Fprintf(w, "%s: %d clone groups, %d fragments", input, result.Statistics.TotalGroups, result.Statistics.TotalFragments)
+	if result.Statistics.SuppressedGroups > 0 {
+		fmt.Fprintf(w, " (%d accepted)", result.Statistics.SuppressedGroups)
+	}
+	fmt.Fprintln(w)
+	if outPath != "" {
+		fmt.Fprintf(w, "report: %s\n", outPath)
+	}
+}
+
+// defaultReportPath builds "<dir>/<input base name>.<extension of format>".
+func defaultReportPath(dir, input, format string) string {
+	base := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	return filepath.Join(dir, base+"."+docline.ReportFileExtension(format))
+}
+
+// reportPaths returns the report path of every input for every format. Inputs
+// below relDir, the configuration directory for configured inputs, keep their
+// path relative to it so that documents with the same base name do not
+// overwrite each other's reports; other inputs are named by their base name.
+// Two inputs that would still share a report are an error.
+func reportPaths(dir string, inputs, formats []string, relDir string) ([][]string, error) {
+	paths := make([][]string, len(inputs))
+	owners := map[string]string{}
+	for i, input := range inputs {
+		rel := ""
+		if relDir != "" {
+			if r, err := filepath.Rel(relDir, input); err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+				rel = filepath.ToSlash(r)
+			}
+		}
+		for _, format := range formats {
+			p := defaultReportPath(dir, input, format)
+			if rel != "" {
+				p = nestedReportPath(dir, rel, format)
+			}
+			if owner, ok := owners[p]; ok && owner != input {
+				return nil, fmt.Errorf("%s and %s would both write the report %s", owner, input, p)
+			}
+			owners[p] = input
+			paths[i] = append(paths[i], p)
+		}
+	}
+	return paths, nil
+}
+
+// nestedReportPath builds "<dir>/<rel without extension>.<extension of
+// format>" for the slash-separated relative path rel, so that documents with
+// the same base name in different directories get separate reports.
+func nestedReportPath(dir, rel, format string) string {
+	rel = filepath.FromSlash(rel)
+	return filepath.Join(dir, strings.TrimSuffix(rel, filepath.Ext(rel))+"."+docline.ReportFileExtension(format))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	var af analysisFlags
+	fs := newFlagSet("check", "check -policy FILE [flags] DOCUMENT", stderr)
+	af.register(fs)
+	policyPath := fs.String("policy", "", "JSON policy file (required unless the configuration sets policy)")
+	against := fs.String("against", "", "previous version of the document (or its JSON result) for the no_new_groups rule")
+	junitPath := fs.String("junit", "", "write a JUnit XML report to this path")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := af.loadConfig(fs); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	*policyPath = af.policyPath(*policyPath)
+	if fs.NArg() != 1 || *policyPath == "" {
+		fs.Usage()
+		return exitError
+	}
+
+	policy, err := docline.LoadPolicy(*policyPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+
+	d := af.newDocline()
+	result, err := af.analyze(d, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: analyze %s: %v\n", fs.Arg(0), err)
+		return exitError
+	}
+
+	var previous *docline.AnalysisResult
+	if *against != "" {
+		previous, err = af.analyze(d, *against)
+		if err != nil {
+			fmt.Fprintf(stderr, "docline: analyze %s: %v\n", *against, err)
+			return exitError
+		}
+	}
+
+	verdict := docline.EvaluatePolicy(policy, result, previous)
+	printVerdict(stdout, verdict)
+
+	if *junitPath != "" {
+		if err := d.GenerateReport(result, "junit", *junitPath); err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+	}
+
+	if !verdict.Passed {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func printVerdict(w io.Writer, verdict docline.PolicyVerdict) {
+	for _, c := range verdict.Checks {
+		status := "ok  "
+		if !c.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s %-28s %s\n", status, c.Rule, c.Message)
+	}
+	if verdict.Passed {
+		fmt.Fprintln(w, "policy: passed")
+	} else {
+		fmt.Fprintf(w, "policy: failed (%d violations)\n", len(verdict.Violations()))
+	}
+}
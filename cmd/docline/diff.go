@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	var af analysisFlags
+	fs := newFlagSet("diff", "diff [flags] OLD NEW  (documents or JSON results)", stderr)
+	af.register(fs)
+	format := fs.String("format", "", "also render the changed groups as a report in this format")
+	output := fs.String("o", "", "report path (default: <results-dir>/diff.<format>)")
+	failOnNew := fs.Bool("fail-on-new", false, "exit with status 1 when new or grown groups are found")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := af.loadConfig(fs); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitError
+	}
+
+	d := af.newDocline()
+	oldResult, err := af.analyze(d, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: analyze %s: %v\n", fs.Arg(0), err)
+		return exitError
+	}
+	newResult, err := af.analyze(d, fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: analyze %s: %v\n", fs.Arg(1), err)
+		return exitError
+	}
+
+	cmp := docline.CompareResults(oldResult, newResult)
+	printComparison(stdout, cmp)
+
+	if *format != "" {
+		outPath := *output
+		if outPath == "" {
+			outPath = defaultReportPath(af.resultsDir, "diff", *format)
+		}
+		if err := d.GenerateReport(cmp.AsAnalysisResult(), *format, outPath); err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintf(stdout, "report: %s\n", outPath)
+	}
+
+	if *failOnNew && len(cmp.New)+len(cmp.Grown) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// printComparison writes a one-line summary followed by one line per changed group.
+func printComparison(w io.Writer, cmp *docline.ResultComparison) {
+	fmt.Fprintf(w, "new: %d, removed: %d, grown: %d, shrunk: %d, unchanged: %d\n",
+		len(cmp.New), len(cmp.Removed), len(cmp.Grown), len(cmp.Shrunk), len(cmp.Unchanged))
+
+	markers := map[string]string{
+		docline.GroupNew:     "+",
+		docline.GroupRemoved: "-",
+		docline.GroupGrown:   ">",
+		docline.GroupShrunk:  "<",
+	}
+	for _, ch := range cmp.Changes() {
+		fmt.Fprintf(w, "%s %s %-8s power %d -> %d  %s\n",
+			markers[ch.Kind], ch.ID, ch.Kind, ch.OldPower, ch.NewPower, truncate(ch.Group.Archetype, 60))
+	}
+}
+
+// truncate shortens s to at most n runes, appending "..." when cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
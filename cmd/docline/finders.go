@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runFinders(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("finders", "finders [-json] [FINDER]", stderr)
+	asJSON := fs.Bool("json", false, "print the finders and their parameters as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	finders := docline.New(&docline.Config{}).CloneFinders()
+	if fs.NArg() == 1 {
+		var selected []docline.FinderInfo
+		for _, f := range finders {
+			if f.Name == fs.Arg(0) {
+				selected = append(selected, f)
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Fprintf(stderr, "docline: unknown finder %q\n", fs.Arg(0))
+			return exitError
+		}
+		finders = selected
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(finders); err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	for i, f := range finders {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "%s - %s\n", f.Name, f.Description)
+		if !f.Declared {
+			fmt.Fprintln(stdout, "  parameters not declared; -param values are passed unchecked")
+			continue
+		}
+		if len(f.Params) == 0 {
+			fmt.Fprintln(stdout, "  no parameters")
+			continue
+		}
+		width := 0
+		for _, p := range f.Params {
+			width = max(width, len(p.Name))
+		}
+		for _, p := range f.Params {
+			fmt.Fprintf(stdout, "  %-*s  %-6s  %s (%s)\n", width, p.Name, p.Type, p.Description, paramDetails(p))
+		}
+	}
+	return exitOK
+}
+
+// paramDetails describes the default and range of p, e.g. "default 5, >= 1".
+func paramDetails(p docline.ParamSpec) string {
+	def := fmt.Sprintf("%v", p.Default)
+	if s, ok := p.Default.(string); ok {
+		def = fmt.Sprintf("%q", s)
+	}
+	details := []string{"default " + def}
+	switch {
+	case p.Min != nil && p.Max != nil:
+		details = append(details, fmt.Sprintf("%v-%v", *p.Min, *p.Max))
+	case p.Min != nil:
+		details = append(details, fmt.Sprintf(">= %v", *p.Min))
+	case p.Max != nil:
+		details = append(details, fmt.Sprintf("<= %v", *p.Max))
+	}
+	return strings.Join(details, ", ")
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+// documentExtensions are the document types picked up when commands select
+// files themselves (see "Supported file formats" in the README).
+const documentExtensions = ".xml,.dbk,.docbook,.drl,.md,.txt,.html,.htm,.doc,.docx,.odt,.rtf"
+
+// extensionSet parses comma-separated extensions into lower-case ".ext" keys.
+func extensionSet(exts string) map[string]bool {
+	set := map[string]bool{}
+	for _, ext := range strings.Split(exts, ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		set[ext] = true
+	}
+	return set
+}
+
+// analysisFlags holds the flags shared by every command that runs a finder.
+type analysisFlags struct {
+	finder              string
+	minCloneLength      int
+	maxCloneLength      int
+	minGroupPower       int
+	similarityThreshold float64
+	similarityCalc      string
+	params              paramFlag
+	baseline            string
+	resultsDir          string
+	configPath          string
+	profile             string
+	filters             filterFlag
+
+	// Loaded by loadConfig; nil and empty without a configuration file.
+	settings  *docline.ProjectSettings
+	configDir string
+}
+
+func (a *analysisFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&a.finder, "finder", "automatic", "clone finder to use")
+	fs.IntVar(&a.minCloneLength, "min-length", 0, "minimum clone length in tokens (0 = finder default)")
+	fs.IntVar(&a.maxCloneLength, "max-length", 0, "maximum clone length in tokens (0 = unlimited)")
+	fs.IntVar(&a.minGroupPower, "min-power", 0, "minimum number of fragments per group (0 = finder default)")
+	fs.Float64Var(&a.similarityThreshold, "similarity", 0, "minimum similarity score 0.0-1.0 (0 = finder default)")
+	fs.StringVar(&a.similarityCalc, "similarity-calc", "", "similarity calculator: jaccard, dice, levenshtein, lcs or cosine (default jaccard)")
+	fs.Var(&a.params, "param", "finder-specific parameter as key=value (repeatable; see 'docline finders')")
+	fs.StringVar(&a.baseline, "baseline", "", "JSON baseline of accepted clone groups")
+	fs.StringVar(&a.resultsDir, "results-dir", "./results", "directory for generated reports")
+	fs.Var(&a.filters, "filter", "post-processing filter as NAME[:key=value,...], applied in order after the finder (repeatable; replaces the configured filters), e.g. length:min_tokens=10")
+	fs.StringVar(&a.configPath, "config", "", "project configuration file (default: docline.yaml, docline.yml or docline.json if present)")
+	fs.StringVar(&a.profile, "profile", "", "configuration profile (default: the file's default_profile)")
+}
+
+// loadConfig applies the project configuration to the flags that were not
+// set on the command line. Finder parameters are merged, -param winning.
+// Call it after fs.Parse.
+func (a *analysisFlags) loadConfig(fs *flag.FlagSet) error {
+	path := a.configPath
+	if path == "" {
+		path = docline.FindConfig(".")
+	}
+	if path == "" {
+		if a.profile != "" {
+			return fmt.Errorf("-profile %s: no configuration file found", a.profile)
+		}
+		return nil
+	}
+	cfg, err := docline.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	settings, err := cfg.Profile(a.profile)
+	if err != nil {
+		return fmt.Errorf("config %s: %v", path, err)
+	}
+	a.settings = settings
+	a.configDir = cfg.Dir()
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	fc := settings.FinderConfig()
+	if !set["finder"] && settings.Finder != "" {
+		a.finder = settings.Finder
+	}
+	if !set["min-length"] && settings.MinCloneLength != nil {
+		a.minCloneLength = fc.MinCloneLength
+	}
+	if !set["max-length"] && settings.MaxCloneLength != nil {
+		a.maxCloneLength = fc.MaxCloneLength
+	}
+	if !set["min-power"] && settings.MinGroupPower != nil {
+		a.minGroupPower = fc.MinGroupPower
+	}
+	if !set["similarity"] && settings.SimilarityThreshold != nil {
+		a.similarityThreshold = fc.SimilarityThreshold
+	}
+	if !set["similarity-calc"] && settings.SimilarityCalc != "" {
+		a.similarityCalc = settings.SimilarityCalc
+	}
+	if !set["baseline"] && settings.Baseline != "" {
+		a.baseline = settings.Baseline
+	}
+	if !set["results-dir"] {
+		a.resultsDir = settings.Reports.OutputDir
+	}
+	for k, v := range fc.CustomParams {
+		if _, ok := a.params[k]; !ok {
+			if a.params == nil {
+				a.params = paramFlag{}
+			}
+			a.params[k] = v
+		}
+	}
+	return nil
+}
+
+// reportFormats returns format when the -format flag was set or the
+// configuration lists none, and the configured formats otherwise.
+func (a *analysisFlags) reportFormats(fs *flag.FlagSet, format string) []string {
+	set := false
+	fs.Visit(func(f *flag.Flag) { set = set || f.Name == "format" })
+	if set || a.settings == nil || len(a.settings.Reports.Formats) == 0 {
+		return []string{format}
+	}
+	return a.settings.Reports.Formats
+}
+
+// reportParams merges the configured report parameters with -report-param.
+func (a *analysisFlags) reportParams(params paramFlag) map[string]interface{} {
+	merged := map[string]interface{}{}
+	if a.settings != nil {
+		for k, v := range a.settings.Reports.Params {
+			merged[k] = v
+		}
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	return merged
+}
+
+// policyPath returns flagValue, or the configured policy when it is empty.
+func (a *analysisFlags) policyPath(flagValue string) string {
+	if flagValue == "" && a.settings != nil {
+		return a.settings.Policy
+	}
+	return flagValue
+}
+
+func (a *analysisFlags) newDocline() *docline.Docline {
+	cfg := &docline.Config{
+		ResultsDirectory:    a.resultsDir,
+		DefaultReportFormat: "html",
+		DefaultTokenizer:    "space",
+		DefaultCloneFinder:  a.finder,
+		BaselineFile:        a.baseline,
+	}
+	if a.settings != nil {
+		cfg.Filters = a.settings.Filters
+		cfg.DocBookTextElements = a.settings.Parsers.DocBook.TextElements
+	}
+	if len(a.filters) > 0 {
+		cfg.Filters = a.filters
+	}
+	return docline.New(cfg)
+}
+
+func (a *analysisFlags) finderConfig() docline.CloneFinderConfig {
+	var params map[string]interface{}
+	if len(a.params) > 0 {
+		params = make(map[string]interface{}, len(a.params))
+		for k, v := range a.params {
+			params[k] = v
+		}
+	}
+	return docline.CloneFinderConfig{
+		MinCloneLength:      a.minCloneLength,
+		MaxCloneLength:      a.maxCloneLength,
+		MinGroupPower:       a.minGroupPower,
+		SimilarityThreshold: a.similarityThreshold,
+		SimilarityCalc:      a.similarityCalc,
+		CustomParams:        params,
+	}
+}
+
+// analyze runs the finder on path, or loads the result when path is a JSON
+// result saved with "-format json".
+func (a *analysisFlags) analyze(d *docline.Docline, path string) (*docline.AnalysisResult, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return docline.LoadResult(path)
+	}
+	return d.AnalyzeDocumentWithConfig(path, a.finder, a.finderConfig())
+}
+
+// paramFlag collects repeatable key=value flags. Values are converted to
+// bool, int or float64 when they parse as such and kept as strings otherwise.
+type paramFlag map[string]interface{}
+
+func (p *paramFlag) String() string {
+	if p == nil || len(*p) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(*p))
+	for k, v := range *p {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (p *paramFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	if *p == nil {
+		*p = paramFlag{}
+	}
+	(*p)[strings.TrimSpace(key)] = parseParamValue(strings.TrimSpace(value))
+	return nil
+}
+
+// filterFlag collects repeatable -filter NAME[:key=value,...] flags. The
+// strict filter's min_archetype_length and remove_overlaps set the
+// FilterSpec fields; other keys become filter parameters. A comma only
+// separates parameters when key= follows, so patterns may contain commas.
+type filterFlag []docline.FilterSpec
+
+var filterParamStart = regexp.MustCompile(`^\s*\w+=`)
+
+func (f *filterFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	names := make([]string, len(*f))
+	for i, spec := range *f {
+		names[i] = spec.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *filterFlag) Set(s string) error {
+	name, rest, _ := strings.Cut(s, ":")
+	spec := docline.FilterSpec{Name: strings.TrimSpace(name)}
+	if spec.Name == "" {
+		return fmt.Errorf("expected NAME[:key=value,...], got %q", s)
+	}
+
+	var pairs []string
+	for _, part := range strings.Split(rest, ",") {
+		if len(pairs) > 0 && !filterParamStart.MatchString(part) {
+			pairs[len(pairs)-1] += "," + part
+			continue
+		}
+		if strings.TrimSpace(part) != "" {
+			pairs = append(pairs, part)
+		}
+	}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value, got %q", pair)
+		}
+		switch v := parseParamValue(strings.TrimSpace(value)); key {
+		case "min_archetype_length":
+			n, ok := v.(int)
+			if !ok {
+				return fmt.Errorf("min_archetype_length must be an integer, got %q", value)
+			}
+			spec.MinArchetypeLength = n
+		case "remove_overlaps":
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("remove_overlaps must be true or false, got %q", value)
+			}
+			spec.RemoveOverlaps = b
+		default:
+			if spec.Params == nil {
+				spec.Params = map[string]interface{}{}
+			}
+			if key == "pattern" {
+				v = strings.TrimSpace(value) // Always text, even "123"
+			}
+			spec.Params[key] = v
+		}
+	}
+	*f = append(*f, spec)
+	return nil
+}
+
+func parseParamValue(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// newFlagSet creates a flag set that reports errors instead of exiting.
+func newFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: docline %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/gitscope"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+// skipHookEnv bypasses the pre-commit hook when set to a non-empty value.
+const skipHookEnv = "DOCLINE_SKIP_HOOK"
+
+// hookMarker identifies hooks written by "docline hook install".
+const hookMarker = "# Installed by \"docline hook install\"."
+
+func runHook(args []string, stdout, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: docline hook install [-force] [-- RUN FLAGS...]")
+		fmt.Fprintln(stderr, "       docline hook uninstall")
+		fmt.Fprintln(stderr, "       docline hook run [flags]")
+	}
+	if len(args) == 0 {
+		usage()
+		return exitError
+	}
+	switch args[0] {
+	case "install":
+		return runHookInstall(args[1:], stdout, stderr)
+	case "uninstall":
+		return runHookUninstall(args[1:], stdout, stderr)
+	case "run":
+		return runHookRun(args[1:], stdout, stderr)
+	}
+	usage()
+	return exitError
+}
+
+// hookRunFlags declares the flags of "hook run"; install validates the
+// flags it records with the same set.
+func hookRunFlags(stderr io.Writer) (*analysisFlags, *string, *flag.FlagSet) {
+	var af analysisFlags
+	fs := newFlagSet("hook run", "hook run [flags]", stderr)
+	af.register(fs)
+	policyPath := fs.String("policy", "", "JSON policy file (default: fail on new clone groups only)")
+	return &af, policyPath, fs
+}
+
+func runHookInstall(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("hook install", "hook install [-force] [-- RUN FLAGS...]", stderr)
+	force := fs.Bool("force", false, "replace an existing pre-commit hook not written by docline")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	runArgs := fs.Args()
+	if _, _, runFS := hookRunFlags(stderr); runFS.Parse(runArgs) != nil || runFS.NArg() != 0 {
+		fmt.Fprintln(stderr, "docline: invalid flags for \"hook run\"")
+		return exitError
+	}
+
+	path, err := gitscope.HookPath(".", "pre-commit")
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	if existing, err := os.ReadFile(path); err == nil && !bytes.Contains(existing, []byte(hookMarker)) && !*force {
+		fmt.Fprintf(stderr, "docline: %s exists and was not written by docline; use -force to replace it\n", path)
+		return exitError
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "docline"
+	}
+	words := append([]string{exe, "hook", "run"}, runArgs...)
+	for i, w := range words {
+		words[i] = shellQuote(w)
+	}
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		hookMarker,
+		"# Checks staged documents for new duplication; bypass with " + skipHookEnv + "=1 git commit ...",
+		"exec " + strings.Join(words, " "),
+		"",
+	}, "\n")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "installed pre-commit hook: %s\n", path)
+	return exitOK
+}
+
+func runHookUninstall(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "Usage: docline hook uninstall")
+		return exitError
+	}
+	path, err := gitscope.HookPath(".", "pre-commit")
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Fprintln(stdout, "no pre-commit hook installed")
+		return exitOK
+	}
+	if err != nil || !bytes.Contains(existing, []byte(hookMarker)) {
+		fmt.Fprintf(stderr, "docline: %s was not written by docline; leaving it in place\n", path)
+		return exitError
+	}
+	if err := os.Remove(path); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "removed pre-commit hook: %s\n", path)
+	return exitOK
+}
+
+// runHookRun checks every staged document against the policy, comparing it
+// with its HEAD version for the no_new_groups rule. It exits with status 1,
+// blocking the commit, when a policy is violated.
+func runHookRun(args []string, stdout, stderr io.Writer) int {
+	af, policyPath, fs := hookRunFlags(stderr)
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+	if os.Getenv(skipHookEnv) != "" {
+		fmt.Fprintf(stdout, "docline: %s set, skipping duplication check\n", skipHookEnv)
+		return exitOK
+	}
+	if err := af.loadConfig(fs); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	*policyPath = af.policyPath(*policyPath)
+
+	policy := &docline.Policy{NoNewGroups: true}
+	if *policyPath != "" {
+		var err error
+		if policy, err = docline.LoadPolicy(*policyPath); err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+	}
+
+	repo, err := gitscope.OpenStaged(".")
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	changed, err := repo.ChangedLines()
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	documents := extensionSet(documentExtensions)
+	var files []string
+	for file := range changed {
+		if documents[strings.ToLower(filepath.Ext(file))] {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	binaries, err := repo.BinaryFiles()
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	for _, file := range binaries {
+		if documents[strings.ToLower(filepath.Ext(file))] {
+			fmt.Fprintf(stderr, "docline: %s: binary document not checked by the hook\n", file)
+		}
+	}
+	if len(files) == 0 {
+		return exitOK
+	}
+
+	d := af.newDocline()
+	// Like -git-range, formats that cannot be converted are checked as plain text.
+	analyze := func(content []byte, file string) (*docline.AnalysisResult, error) {
+		text, err := gitscope.ExtractText(d, file, content)
+		if err != nil {
+			return nil, err
+		}
+		return d.AnalyzeReaderWithConfig(strings.NewReader(text), "", af.finder, af.finderConfig())
+	}
+
+	failed := 0
+	for _, file := range files {
+		staged, err := repo.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "docline: %s: %v\n", file, err)
+			return exitError
+		}
+		result, err := analyze(staged, file)
+		if err != nil {
+			fmt.Fprintf(stderr, "docline: analyze %s: %v\n", file, err)
+			return exitError
+		}
+		// New files have no HEAD version; all their groups are new.
+		previous := &docline.AnalysisResult{}
+		if head, err := repo.ReadRevision("HEAD", file); err == nil {
+			if previous, err = analyze(head, file); err != nil {
+				fmt.Fprintf(stderr, "docline: analyze HEAD:%s: %v\n", file, err)
+				return exitError
+			}
+		}
+
+		cmp := docline.CompareResults(previous, result)
+		verdict := docline.EvaluatePolicy(policy, result, previous)
+		status := "ok  "
+		if !verdict.Passed {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(stdout, "%s %s: %d clone groups, %d new\n", status, file, result.Statistics.TotalGroups, len(cmp.New))
+		for _, c := range verdict.Violations() {
+			fmt.Fprintf(stdout, "     %-28s %s\n", c.Rule, c.Message)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(stdout, "docline: commit blocked: %d of %d staged documents violate the duplication policy\n", failed, len(files))
+		fmt.Fprintf(stdout, "docline: fix the duplication or bypass with %s=1 git commit ...\n", skipHookEnv)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
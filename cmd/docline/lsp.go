@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/PavelMkr/docline-new/internal/lsp"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runLSP(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("lsp", "lsp [flags]", stderr)
+	baseline := fs.String("baseline", "", "JSON baseline of accepted clone groups")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	d := docline.New(&docline.Config{
+		DefaultTokenizer: "space",
+		BaselineFile:     *baseline,
+	})
+	if err := lsp.New(d).Serve(os.Stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "docline: lsp: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,89 @@
+// Command docline is the command-line interface of the DocLine duplicate finder.
+//
+// Usage:
+//
+//	docline <command> [flags] [arguments]
+//
+// Commands:
+//
+//	analyze   analyse a document and write a report
+//	diff      compare clone groups of two documents
+//	check     evaluate a duplication policy (exit status 1 on violations)
+//	report    render a saved JSON result in another format
+//	serve     run the HTTP API for asynchronous analyses
+//	lsp       run the language server for editors on stdio
+//	watch     re-analyse documents whenever they change
+//	hook      install or run the git pre-commit hook
+//	finders   list clone finders and their parameters
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Process exit codes.
+const (
+	exitOK      = 0 // Success
+	exitFailure = 1 // Command ran, but the result is a failure (e.g. new duplication)
+	exitError   = 2 // Usage or runtime error
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+func commands() []command {
+	return []command{
+		{"analyze", "analyse a document and write a report", runAnalyze},
+		{"diff", "compare clone groups of two documents", runDiff},
+		{"check", "evaluate a duplication policy (exit status 1 on violations)", runCheck},
+		{"report", "render a saved JSON result in another format", runReport},
+		{"serve", "run the HTTP API for asynchronous analyses", runServe},
+		{"lsp", "run the language server for editors on stdio", runLSP},
+		{"watch", "re-analyse documents whenever they change", runWatch},
+		{"hook", "install or run the git pre-commit hook", runHook},
+		{"finders", "list clone finders and their parameters", runFinders},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitError
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(stdout)
+		return exitOK
+	}
+
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "docline: unknown command %q\n\n", args[0])
+	printUsage(stderr)
+	return exitError
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: docline <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'docline <command> -h' for command flags.")
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runReport(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("report", "report [flags] RESULT.json", stderr)
+	format := fs.String("format", "html", "report format")
+	output := fs.String("o", "", "report path (default: <results-dir>/<result>.<format>)")
+	resultsDir := fs.String("results-dir", "./results", "directory for generated reports")
+	var reportParams paramFlag
+	fs.Var(&reportParams, "report-param", "report parameter as key=value (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+	input := fs.Arg(0)
+
+	result, err := docline.LoadResult(input)
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+
+	outPath := *output
+	if outPath == "" {
+		outPath = defaultReportPath(*resultsDir, input, *format)
+	}
+	d := docline.New(&docline.Config{ResultsDirectory: *resultsDir})
+	if err := d.GenerateReportWithParams(result, *format, outPath, reportParams); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "report: %s\n", outPath)
+	return exitOK
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/PavelMkr/docline-new/internal/server"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runServe(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("serve", "serve [flags]", stderr)
+	addr := fs.String("addr", "localhost:8080", "listen address")
+	workers := fs.Int("workers", 2, "concurrent analyses")
+	queueSize := fs.Int("queue", 16, "jobs waiting for a worker before uploads are refused")
+	retain := fs.Int("retain", 100, "finished jobs kept for status and download")
+	maxUpload := fs.Int64("max-upload", 32<<20, "largest accepted document in bytes")
+	finder := fs.String("finder", "automatic", "clone finder used when a request names none")
+	baseline := fs.String("baseline", "", "JSON baseline of accepted clone groups")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	d := docline.New(&docline.Config{
+		DefaultReportFormat: "html",
+		DefaultTokenizer:    "space",
+		DefaultCloneFinder:  *finder,
+		BaselineFile:        *baseline,
+	})
+	srv := server.New(d, server.Config{
+		Workers:        *workers,
+		QueueSize:      *queueSize,
+		Retain:         *retain,
+		MaxUploadBytes: *maxUpload,
+		DefaultFinder:  *finder,
+	})
+	defer srv.Close()
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- httpServer.ListenAndServe() }()
+	fmt.Fprintf(stdout, "docline: serving on http://%s\n", *addr)
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(stderr, "docline: serve: %v\n", err)
+		return exitError
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "docline: shutdown: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
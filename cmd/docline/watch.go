@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/PavelMkr/docline-new/internal/watch"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runWatch(args []string, stdout, stderr io.Writer) int {
+	var af analysisFlags
+	fs := newFlagSet("watch", "watch [flags] FILE|DIR...  (default: the inputs of the configuration file)", stderr)
+	af.register(fs)
+	formats := fs.String("format", "html", "comma-separated report formats regenerated after each run (empty = none)")
+	interval := fs.Duration("interval", time.Second, "time between polls")
+	debounce := fs.Duration("debounce", 500*time.Millisecond, "quiet time after the last change before re-analysing")
+	exts := fs.String("ext", documentExtensions, "comma-separated extensions of documents picked up in directories")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if err := af.loadConfig(fs); err != nil {
+		fmt.Fprintf(stderr, "docline: %v\n", err)
+		return exitError
+	}
+	paths := fs.Args()
+	if len(paths) == 0 && af.settings != nil && len(af.settings.Inputs) > 0 {
+		var err error
+		if paths, err = af.settings.Files(); err != nil {
+			fmt.Fprintf(stderr, "docline: %v\n", err)
+			return exitError
+		}
+	}
+	if len(paths) == 0 {
+		fs.Usage()
+		return exitError
+	}
+	if *debounce <= 0 {
+		*debounce = -1 // watch.Options: negative disables debouncing
+	}
+
+	var reportFormats []string
+	for _, f := range strings.Split(strings.Join(af.reportFormats(fs, *formats), ","), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			reportFormats = append(reportFormats, f)
+		}
+	}
+	reportParams := af.reportParams(nil)
+
+	d := af.newDocline()
+	w := watch.New(paths, watch.Options{
+		Interval: *interval,
+		Debounce: *debounce,
+		Match:    watchMatcher(*exts, af.resultsDir),
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(stdout, "docline: watching %s (Ctrl+C to stop)\n", strings.Join(paths, ", "))
+	previous := map[string]*docline.AnalysisResult{}
+	err := w.Run(ctx, func(c watch.Change) {
+		fmt.Fprintf(stdout, "\n[%s]\n", time.Now().Format("15:04:05"))
+		for _, path := range c.Removed {
+			delete(previous, path)
+			fmt.Fprintf(stdout, "%s: removed\n", path)
+		}
+		for _, path := range c.Modified {
+			result, err := d.AnalyzeDocumentWithConfig(path, af.finder, af.finderConfig())
+			if err != nil {
+				fmt.Fprintf(stderr, "docline: analyze %s: %v\n", path, err)
+				continue
+			}
+			for _, format := range reportFormats {
+				outPath := defaultReportPath(af.resultsDir, path, format)
+				if err := d.GenerateReportWithParams(result, format, outPath, reportParams); err != nil {
+					fmt.Fprintf(stderr, "docline: %s: %v\n", path, err)
+				}
+			}
+
+			fmt.Fprintf(stdout, "%s: %d clone groups, %d fragments\n", path, result.Statistics.TotalGroups, result.Statistics.TotalFragments)
+			if old, ok := previous[path]; ok {
+				printComparison(stdout, docline.CompareResults(old, result))
+			}
+			previous[path] = result
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "docline: watch: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// watchMatcher accepts files with one of the comma-separated extensions,
+// except generated reports below resultsDir, which would otherwise trigger
+// a new run every time they are written.
+func watchMatcher(exts, resultsDir string) func(string) bool {
+	allowed := extensionSet(exts)
+	results, err := filepath.Abs(resultsDir)
+	if err != nil {
+		results = filepath.Clean(resultsDir)
+	}
+	return func(path string) bool {
+		if !allowed[strings.ToLower(filepath.Ext(path))] {
+			return false
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return true
+		}
+		rel, err := filepath.Rel(results, abs)
+		return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+}
@@ -4,30 +4,23 @@ import (
 	"fmt"
 	"log"
 
-	alg "github.com/PavelMkr/docline-new/internal/algorithms"
-	rep "github.com/PavelMkr/docline-new/internal/report"
-	"github.com/PavelMkr/docline-new/internal/framework"
+	"github.com/PavelMkr/docline-new/pkg/docline"
 )
 
 func main() {
-	// Create framework instance
-	config := &framework.Config{
+	// Create a Docline instance with the built-in finders, parsers and reports
+	d := docline.New(&docline.Config{
 		ResultsDirectory:    "./results",
-		DefaultReportFormat:  "html",
-		DefaultTokenizer:     "space",
-		DefaultCloneFinder:   "automatic",
-	}
-
-	fw := framework.NewFramework(config)
-
-	// Register built-in components
-	registerBuiltins(fw)
+		DefaultReportFormat: "html",
+		DefaultTokenizer:    "space",
+		DefaultCloneFinder:  "automatic",
+	})
 
 	// Analyze a document
-	result, err := fw.AnalyzeDocument(
+	result, err := d.AnalyzeDocumentWithConfig(
 		"example.xml",
 		"automatic",
-		framework.CloneFinderConfig{
+		docline.CloneFinderConfig{
 			MinCloneLength:      20,
 			MinGroupPower:       2,
 			SimilarityThreshold: 0.9,
@@ -42,22 +35,10 @@ func main() {
 	fmt.Printf("Total fragments: %d\n", result.Statistics.TotalFragments)
 
 	// Generate report
-	err = fw.GenerateReport(result, "html", "./results/report.html")
+	err = d.GenerateReport(result, "html", "./results/report.html")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	fmt.Println("Analysis complete!")
 }
-
-func registerBuiltins(fw *framework.Framework) {
-	registry := fw.GetRegistry()
-
-	// Core framework utilities
-	_ = framework.RegisterBuiltInPlugins(registry)
-
-	// Built-in algorithms, parser/converter and report generators
-	_ = alg.RegisterCloneFinders(registry)
-	_ = rep.RegisterDocumentPlugins(registry)
-	_ = rep.RegisterReportGenerators(registry)
-}
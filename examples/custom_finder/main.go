@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/PavelMkr/docline-new/internal/framework"
+	"github.com/PavelMkr/docline-new/pkg/docline"
 )
 
 // CustomCloneFinder demonstrates how to create a custom clone finder
@@ -20,12 +20,12 @@ func (c *CustomCloneFinder) Description() string {
 	return "Custom clone finder that finds exact duplicate sentences"
 }
 
-func (c *CustomCloneFinder) FindClones(text string, config framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
+func (c *CustomCloneFinder) FindClones(text string, config docline.CloneFinderConfig) ([]docline.CloneGroup, error) {
 	// Split text into sentences
 	sentences := strings.Split(text, ".")
 
 	// Find duplicate sentences
-	sentenceMap := make(map[string][]framework.TextFragment)
+	sentenceMap := make(map[string][]docline.TextFragment)
 	for i, sentence := range sentences {
 		sentence = strings.TrimSpace(sentence)
 		if len(sentence) < config.MinCloneLength {
@@ -47,7 +47,7 @@ func (c *CustomCloneFinder) FindClones(text string, config framework.CloneFinder
 			}
 		}
 
-		frag := framework.TextFragment{
+		frag := docline.TextFragment{
 			Content:  sentence,
 			StartPos: startPos,
 			EndPos:   startPos + len(tokens),
@@ -57,10 +57,10 @@ func (c *CustomCloneFinder) FindClones(text string, config framework.CloneFinder
 	}
 
 	// Build groups
-	var groups []framework.CloneGroup
+	var groups []docline.CloneGroup
 	for sentence, fragments := range sentenceMap {
 		if len(fragments) >= config.MinGroupPower {
-			groups = append(groups, framework.CloneGroup{
+			groups = append(groups, docline.CloneGroup{
 				Fragments: fragments,
 				Power:     len(fragments),
 				Archetype: sentence,
@@ -72,21 +72,21 @@ func (c *CustomCloneFinder) FindClones(text string, config framework.CloneFinder
 }
 
 func main() {
-	// Create framework
-	fw := framework.NewFramework(nil)
+	// Create a Docline instance with the built-in plugins
+	d := docline.New(&docline.Config{ResultsDirectory: "./results", DefaultTokenizer: "space"})
 
 	// Register custom finder
 	customFinder := &CustomCloneFinder{name: "custom-sentence"}
-	err := fw.GetRegistry().RegisterCloneFinder(customFinder)
+	err := d.RegisterCloneFinder(customFinder)
 	if err != nil {
 		panic(err)
 	}
 
 	// Use custom finder
-	result, err := fw.AnalyzeDocument(
+	result, err := d.AnalyzeDocumentWithConfig(
 		"example.txt",
 		"custom-sentence",
-		framework.CloneFinderConfig{
+		docline.CloneFinderConfig{
 			MinCloneLength: 5,
 			MinGroupPower:  2,
 		},
@@ -5,40 +5,41 @@ import (
 	"os"
 	"strings"
 
-	"github.com/PavelMkr/docline-new/internal/framework"
+	"github.com/PavelMkr/docline-new/pkg/docline"
 )
 
-// MarkdownReportGenerator implements a custom report generator for Markdown format
-type MarkdownReportGenerator struct{}
+// TextReportGenerator implements a custom report generator for plain text.
+// (A production Markdown report is built in: format "md".)
+type TextReportGenerator struct{}
 
-func (m *MarkdownReportGenerator) Name() string {
-	return "markdown"
+func (t *TextReportGenerator) Name() string {
+	return "text"
 }
 
-func (m *MarkdownReportGenerator) Format() string {
-	return "md"
+func (t *TextReportGenerator) Format() string {
+	return "txt"
 }
 
-func (m *MarkdownReportGenerator) Generate(groups []framework.CloneGroup, config framework.ReportConfig, outputPath string) error {
+func (t *TextReportGenerator) Generate(groups []docline.CloneGroup, config docline.ReportConfig, outputPath string) error {
 	var sb strings.Builder
 
 	// Write header
-	sb.WriteString("# " + config.Title + "\n\n")
-	sb.WriteString(fmt.Sprintf("**Source:** %s\n\n", config.SourceFile))
+	sb.WriteString(config.Title + "\n")
+	sb.WriteString(strings.Repeat("=", len(config.Title)) + "\n\n")
+	sb.WriteString(fmt.Sprintf("Source: %s\n\n", config.SourceFile))
 
 	// Write groups
-	sb.WriteString(fmt.Sprintf("## Found %d Clone Groups\n\n", len(groups)))
+	sb.WriteString(fmt.Sprintf("Found %d clone groups\n\n", len(groups)))
 
 	for i, group := range groups {
-		sb.WriteString(fmt.Sprintf("### Group %d (Power: %d)\n\n", i+1, group.Power))
-		sb.WriteString(fmt.Sprintf("**Archetype:** `%s`\n\n", group.Archetype))
-		sb.WriteString("**Fragments:**\n\n")
+		sb.WriteString(fmt.Sprintf("Group %d (Power: %d)\n", i+1, group.Power))
+		sb.WriteString(fmt.Sprintf("  Archetype: %s\n", group.Archetype))
 
 		for j, frag := range group.Fragments {
-			sb.WriteString(fmt.Sprintf("%d. [%d-%d] `%s`\n", j+1, frag.StartPos, frag.EndPos, frag.Content))
+			sb.WriteString(fmt.Sprintf("  %d. [%d-%d] %s\n", j+1, frag.StartPos, frag.EndPos, frag.Content))
 		}
 
-		sb.WriteString("\n---\n\n")
+		sb.WriteString("\n")
 	}
 
 	// Write to file
@@ -46,21 +47,21 @@ func (m *MarkdownReportGenerator) Generate(groups []framework.CloneGroup, config
 }
 
 func main() {
-	// Create framework
-	fw := framework.NewFramework(nil)
+	// Create a Docline instance with the built-in plugins
+	d := docline.New(&docline.Config{ResultsDirectory: "./results", DefaultTokenizer: "space"})
 
 	// Register custom report generator
-	mdGen := &MarkdownReportGenerator{}
-	err := fw.GetRegistry().RegisterReportGenerator(mdGen)
+	txtGen := &TextReportGenerator{}
+	err := d.RegisterReportGenerator(txtGen)
 	if err != nil {
 		panic(err)
 	}
 
 	// Create sample result
-	result := &framework.AnalysisResult{
-		Groups: []framework.CloneGroup{
+	result := &docline.AnalysisResult{
+		Groups: []docline.CloneGroup{
 			{
-				Fragments: []framework.TextFragment{
+				Fragments: []docline.TextFragment{
 					{Content: "This is a duplicate", StartPos: 0, EndPos: 4},
 					{Content: "This is a duplicate", StartPos: 100, EndPos: 104},
 				},
@@ -68,17 +69,17 @@ func main() {
 				Archetype: "This is a duplicate",
 			},
 		},
-		Statistics: framework.AnalysisStatistics{
+		Statistics: docline.AnalysisStatistics{
 			TotalGroups:    1,
 			TotalFragments: 2,
 		},
 	}
 
-	// Generate markdown report
-	err = fw.GenerateReport(result, "md", "./results/report.md")
+	// Generate text report
+	err = d.GenerateReport(result, "txt", "./results/report.txt")
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Println("Markdown report generated!")
+	fmt.Println("Text report generated!")
 }
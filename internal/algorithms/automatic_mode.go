@@ -15,6 +15,11 @@ type AutomaticModeSettings struct {
 	ArchetypeLength int    `json:"archetypeLength"`
 	StrictFilter    bool   `json:"strictFilter"`
 	FilePath        string `json:"filePath,omitempty"`
+
+	// Similarity merges groups whose archetypes score at least
+	// SimilarityThreshold (nil = Jaccard, 0 = 0.9)
+	Similarity          framework.SimilarityCalculator `json:"-"`
+	SimilarityThreshold float64                        `json:"similarityThreshold,omitempty"`
 }
 
 // AutomaticModeResponse represents the response for automatic mode analysis
@@ -94,12 +99,12 @@ func findClones(text string, settings AutomaticModeSettings) []framework.CloneGr
 		})
 	}
 
-	// Merge groups with similar archetypes using isSimilar
+	// Merge groups with similar archetypes
 	var merged []framework.CloneGroup
 	for _, g := range groups {
 		mergedIntoExisting := false
 		for mi := range merged {
-			if isSimilar(g.Archetype, merged[mi].Archetype) {
+			if settings.isSimilar(g.Archetype, merged[mi].Archetype) {
 				merged[mi].Fragments = append(merged[mi].Fragments, g.Fragments...)
 				merged[mi].Power = len(merged[mi].Fragments)
 				mergedIntoExisting = true
@@ -120,44 +125,14 @@ func findClones(text string, settings AutomaticModeSettings) []framework.CloneGr
 	return groups
 }
 
-// isSimilar checks if two text fragments are similar enough
-func isSimilar(a, b string) bool {
-	// Token-level Jaccard similarity of unigrams
-	aTokens := strings.Fields(a)
-	bTokens := strings.Fields(b)
-	if len(aTokens) == 0 && len(bTokens) == 0 {
-		return true
-	}
-	aSet := make(map[string]struct{})
-	for _, t := range aTokens {
-		aSet[t] = struct{}{}
-	}
-	bSet := make(map[string]struct{})
-	for _, t := range bTokens {
-		bSet[t] = struct{}{}
-	}
-	intersection := 0
-	union := 0
-	seen := make(map[string]struct{})
-	for t := range aSet {
-		if _, ok := bSet[t]; ok {
-			intersection++
-		}
-		seen[t] = struct{}{}
-		union++
-	}
-	for t := range bSet {
-		if _, ok := seen[t]; ok {
-			continue
-		}
-		union++
-	}
-	if union == 0 {
-		return false
+// isSimilar checks if two text fragments are similar enough to merge
+func (s AutomaticModeSettings) isSimilar(a, b string) bool {
+	threshold := s.SimilarityThreshold
+	if threshold == 0 {
+		// Conservative default since windows are exact-length token slices
+		threshold = 0.9
 	}
-	jaccard := float64(intersection) / float64(union)
-	// Conservative threshold since windows are exact-length token slices
-	return jaccard >= 0.9
+	return framework.SimilarityOrDefault(s.Similarity).CalculateSimilarity(a, b) >= threshold
 }
 
 // filterCloneGroups applies strict filtering to clone groups
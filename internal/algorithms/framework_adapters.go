@@ -20,12 +20,23 @@ func (a *AutomaticModeAdapter) Description() string {
 	return "Automatic mode clone finder using window-based exact matching"
 }
 
+func (a *AutomaticModeAdapter) Params() []framework.ParamSpec {
+	return []framework.ParamSpec{
+		{Name: "convert_to_drl", Type: framework.ParamBool, Default: true, Description: "merge overlapping clones into DRL groups with a common archetype"},
+		{Name: "archetype_length", Type: framework.ParamInt, Default: 5, Min: framework.Bound(1), Description: "minimum archetype length in tokens kept by the strict filter"},
+		{Name: "strict_filter", Type: framework.ParamBool, Default: true, Description: "drop groups with short archetypes and overlapping fragments"},
+	}
+}
+
 func (a *AutomaticModeAdapter) FindClones(text string, cfg framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
 	settings := AutomaticModeSettings{
 		MinCloneLength:  defaultInt(cfg.MinCloneLength, 20),
 		ConvertToDRL:    getBool(cfg.CustomParams, "convert_to_drl", true),
 		ArchetypeLength: getInt(cfg.CustomParams, "archetype_length", 5),
 		StrictFilter:    getBool(cfg.CustomParams, "strict_filter", true),
+
+		Similarity:          cfg.Similarity,
+		SimilarityThreshold: cfg.SimilarityThreshold,
 	}
 
 	groups, err := ProcessAutomaticMode(text, settings)
@@ -59,12 +70,22 @@ func (a *InteractiveModeAdapter) Description() string {
 	return "Interactive mode clone finder with configurable length ranges"
 }
 
+func (a *InteractiveModeAdapter) Params() []framework.ParamSpec {
+	return []framework.ParamSpec{
+		{Name: "max_clone_length", Type: framework.ParamInt, Default: 0, Min: framework.Bound(0), Description: "maximum clone length in tokens (0 = unlimited)"},
+		{Name: "use_archetype", Type: framework.ParamBool, Default: false, Description: "compute an archetype for every group"},
+	}
+}
+
 func (a *InteractiveModeAdapter) FindClones(text string, cfg framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
 	settings := InteractiveModeSettings{
 		MinCloneLength: defaultInt(cfg.MinCloneLength, 10),
 		MaxCloneLength: getInt(cfg.CustomParams, "max_clone_length", 0),
 		MinGroupPower:  defaultInt(cfg.MinGroupPower, 2),
 		UseArchetype:   getBool(cfg.CustomParams, "use_archetype", false),
+
+		Similarity:          cfg.Similarity,
+		SimilarityThreshold: cfg.SimilarityThreshold,
 	}
 
 	groups, err := ProcessInteractiveMode(text, settings)
@@ -87,6 +108,14 @@ func (a *NGramAdapter) Description() string {
 	return "N-gram based duplicate finder using similarity metrics"
 }
 
+func (a *NGramAdapter) Params() []framework.ParamSpec {
+	return []framework.ParamSpec{
+		{Name: "max_edit", Type: framework.ParamInt, Default: 1, Min: framework.Bound(0), Description: "maximum edit distance between sentences (currently unused)"},
+		{Name: "max_fuzzy", Type: framework.ParamInt, Default: 1, Min: framework.Bound(0), Max: framework.Bound(100), Description: "minimum n-gram similarity of two sentences in percent"},
+		{Name: "file_path", Type: framework.ParamString, Default: "", Description: "source document path (informational)"},
+	}
+}
+
 func (a *NGramAdapter) FindClones(text string, cfg framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
 	minClone := defaultInt(cfg.MinCloneLength, 2)
 	if minClone < 1 {
@@ -133,6 +162,13 @@ func (a *HeuristicModeAdapter) Description() string {
 	return "Heuristic n-gram based clone finder"
 }
 
+func (a *HeuristicModeAdapter) Params() []framework.ParamSpec {
+	return []framework.ParamSpec{
+		{Name: "extension_point_checkbox", Type: framework.ParamBool, Default: false, Description: "run the analysis; without it the finder reports nothing"},
+		{Name: "file_path", Type: framework.ParamString, Default: "", Description: "source document path (informational)"},
+	}
+}
+
 func (a *HeuristicModeAdapter) FindClones(text string, cfg framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
 	data := HeuristicNgramFinderData{
 		ExtensionPointCheckbox: getBool(cfg.CustomParams, "extension_point_checkbox", false),
@@ -145,6 +181,80 @@ func (a *HeuristicModeAdapter) FindClones(text string, cfg framework.CloneFinder
 	return groups, nil
 }
 
+// TerminologyAdapter adapts FindTerminology to the framework.CloneFinder
+// interface: every term cluster becomes a group whose fragments are the term
+// occurrences and whose archetype is the preferred spelling.
+type TerminologyAdapter struct{}
+
+func (a *TerminologyAdapter) Name() string {
+	return "terminology"
+}
+
+func (a *TerminologyAdapter) Description() string {
+	return "Frequent 1-5 token terms with casing, hyphenation and spacing variants"
+}
+
+func (a *TerminologyAdapter) Params() []framework.ParamSpec {
+	return []framework.ParamSpec{
+		{Name: "inconsistent_only", Type: framework.ParamBool, Default: false, Description: "report only terms spelled in more than one way"},
+		{Name: "max_terms", Type: framework.ParamInt, Default: 0, Min: framework.Bound(0), Description: "report at most this many terms, most frequent first (0 = all)"},
+	}
+}
+
+func (a *TerminologyAdapter) FindClones(text string, cfg framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
+	clusters := FindTerminology(text, terminologySettings(cfg))
+	groups := make([]framework.CloneGroup, len(clusters))
+	for i, c := range clusters {
+		groups[i] = termGroup(c)
+	}
+	return groups, nil
+}
+
+// FindClonesStream implements framework.StreamingCloneFinder: each term is
+// emitted as soon as its last occurrence has been read (see
+// StreamTerminology), so the groups arrive in text order rather than in the
+// inconsistent-first order of FindClones.
+func (a *TerminologyAdapter) FindClonesStream(text string, cfg framework.CloneFinderConfig, emit func(framework.CloneGroup) error) error {
+	return StreamTerminology(text, terminologySettings(cfg), func(c TermCluster) error {
+		return emit(termGroup(c))
+	})
+}
+
+// terminologySettings maps the finder config onto TerminologySettings.
+func terminologySettings(cfg framework.CloneFinderConfig) TerminologySettings {
+	return TerminologySettings{
+		MinPhraseLength:  defaultInt(cfg.MinCloneLength, 1),
+		MaxPhraseLength:  defaultInt(cfg.MaxCloneLength, 5),
+		MinFrequency:     defaultInt(cfg.MinGroupPower, 2),
+		InconsistentOnly: getBool(cfg.CustomParams, "inconsistent_only", false),
+		MaxTerms:         getInt(cfg.CustomParams, "max_terms", 0),
+	}
+}
+
+// termGroup converts a term cluster into a clone group whose fragments are
+// the term occurrences.
+func termGroup(c TermCluster) framework.CloneGroup {
+	g := framework.CloneGroup{
+		Archetype: c.Preferred,
+		Power:     len(c.Occurrences),
+		Fragments: make([]framework.TextFragment, len(c.Occurrences)),
+		Metadata: map[string]interface{}{
+			"term_key":          c.Key,
+			"term_variants":     c.Variants,
+			"inconsistent_term": c.Inconsistent(),
+		},
+	}
+	for i, o := range c.Occurrences {
+		g.Fragments[i] = framework.TextFragment{
+			Content:  o.Text,
+			StartPos: o.StartPos,
+			EndPos:   o.EndPos,
+			Metadata: map[string]interface{}{"term_variant": o.Variant},
+		}
+	}
+	return g
+}
+
 // RegisterCloneFinders registers all built-in clone finders in the given registry.
 func RegisterCloneFinders(reg *framework.PluginRegistry) error {
 	if err := reg.RegisterCloneFinder(&AutomaticModeAdapter{}); err != nil {
@@ -159,6 +269,9 @@ func RegisterCloneFinders(reg *framework.PluginRegistry) error {
 	if err := reg.RegisterCloneFinder(&NGramAdapter{}); err != nil {
 		return fmt.Errorf("register ngram finder: %w", err)
 	}
+	if err := reg.RegisterCloneFinder(&TerminologyAdapter{}); err != nil {
+		return fmt.Errorf("register terminology finder: %w", err)
+	}
 	return nil
 }
 
@@ -206,7 +319,9 @@ func convertNGramResultsToGroups(ngramResults map[string][]string) []framework.C
 	return groups
 }
 
-// Helper accessors for CloneFinderConfig.CustomParams.
+// Helper accessors for CloneFinderConfig.CustomParams. The framework
+// validates the values against the finder's Params before it runs; the
+// defaults here must match the declared ones.
 
 func getBool(m map[string]interface{}, key string, def bool) bool {
 	if m == nil {
@@ -14,6 +14,11 @@ type InteractiveModeSettings struct {
 	MinGroupPower  int    `json:"minGroupPower"`
 	UseArchetype   bool   `json:"useArchetype"`
 	FilePath       string `json:"filePath,omitempty"`
+
+	// Similarity merges candidates whose texts score at least
+	// SimilarityThreshold (nil = Jaccard, 0 = 0.8) and picks archetypes
+	Similarity          framework.SimilarityCalculator `json:"-"`
+	SimilarityThreshold float64                        `json:"similarityThreshold,omitempty"`
 }
 
 // InteractiveModeResponse represents the response for interactive mode analysis
@@ -27,16 +32,11 @@ type InteractiveModeResponse struct {
 
 // findInteractiveClones finds similar text fragments using interactive mode settings
 func findInteractiveClones(text string, settings InteractiveModeSettings) []framework.CloneGroup {
-	fmt.Printf("Starting clone search with settings: minLength=%d, maxLength=%d, minPower=%d\n",
-		settings.MinCloneLength, settings.MaxCloneLength, settings.MinGroupPower)
-
 	// Split text into tokens
 	tokens := strings.Fields(text)
 	tokenCount := len(tokens)
-	fmt.Printf("Text split into %d tokens\n", tokenCount)
 
 	if tokenCount < settings.MinCloneLength {
-		fmt.Printf("Text is too short (less than %d tokens)\n", settings.MinCloneLength)
 		return nil
 	}
 
@@ -50,32 +50,23 @@ func findInteractiveClones(text string, settings InteractiveModeSettings) []fram
 		return nil
 	}
 
-	fmt.Printf("Processing windows of size %d (two-pass) ...\n", length)
-
 	// frequency count
 	freq := make(map[string]int, windowCount)
 	for i := 0; i <= tokenCount-length; i++ {
 		window := tokens[i : i+length]
 		windowText := strings.Join(window, " ")
 		freq[windowText]++
-		if i%5000 == 0 && i > 0 {
-			fmt.Printf("Counted %d/%d windows...\n", i, windowCount)
-		}
 	}
 
 	// Prepare container only for candidates meeting min power
 	potentialClones := make(map[string][]framework.TextFragment)
-	candidates := 0
 	for k, c := range freq {
 		if c >= settings.MinGroupPower {
 			potentialClones[k] = nil // mark as candidate
-			candidates++
 		}
 	}
-	fmt.Printf("Candidates meeting MinGroupPower=%d: %d (of %d uniques)\n", settings.MinGroupPower, candidates, len(freq))
 
 	// collect positions only for candidates
-	processed := 0
 	for i := 0; i <= tokenCount-length; i++ {
 		window := tokens[i : i+length]
 		windowText := strings.Join(window, " ")
@@ -86,21 +77,15 @@ func findInteractiveClones(text string, settings InteractiveModeSettings) []fram
 				EndPos:   i + length,
 			})
 		}
-		processed++
-		if processed%5000 == 0 {
-			fmt.Printf("Collected %d/%d windows...\n", processed, windowCount)
-		}
 	}
 
-	fmt.Printf("Collected positions for %d candidate fragments\n", len(potentialClones))
-
 	// Merge potential clones into groups using fuzzy similarity
 	var groups []framework.CloneGroup
 	for text, fragments := range potentialClones {
 		// Try to find an existing group with similar archetype
 		placed := false
 		for gi := range groups {
-			if isSimilarInteractive(text, groups[gi].Archetype) {
+			if settings.isSimilar(text, groups[gi].Archetype) {
 				groups[gi].Fragments = append(groups[gi].Fragments, fragments...)
 				groups[gi].Power = len(groups[gi].Fragments)
 				placed = true
@@ -119,55 +104,22 @@ func findInteractiveClones(text string, settings InteractiveModeSettings) []fram
 	// Apply standard interactive filtering
 	groups = filterInteractiveGroups(groups, settings)
 
-	fmt.Printf("Found %d clone groups after filtering\n", len(groups))
-
 	// Calculate archetypes if enabled
 	if settings.UseArchetype {
-		fmt.Printf("Calculating archetypes...\n")
-		calculateArchetypes(&groups)
+		calculateArchetypes(&groups, framework.SimilarityOrDefault(settings.Similarity))
 	}
 
 	return groups
 }
 
-// isSimilarInteractive checks if two text fragments are similar enough for interactive mode
-func isSimilarInteractive(a, b string) bool {
-	// Use token-level Jaccard similarity with a slightly lower threshold for
-	// interactive exploration
-	aTokens := strings.Fields(a)
-	bTokens := strings.Fields(b)
-	if len(aTokens) == 0 && len(bTokens) == 0 {
-		return true
-	}
-	aSet := make(map[string]struct{})
-	for _, t := range aTokens {
-		aSet[t] = struct{}{}
-	}
-	bSet := make(map[string]struct{})
-	for _, t := range bTokens {
-		bSet[t] = struct{}{}
-	}
-	intersection := 0
-	union := 0
-	seen := make(map[string]struct{})
-	for t := range aSet {
-		if _, ok := bSet[t]; ok {
-			intersection++
-		}
-		seen[t] = struct{}{}
-		union++
-	}
-	for t := range bSet {
-		if _, ok := seen[t]; ok {
-			continue
-		}
-		union++
-	}
-	if union == 0 {
-		return false
+// isSimilar checks if two text fragments are similar enough for interactive mode
+func (s InteractiveModeSettings) isSimilar(a, b string) bool {
+	threshold := s.SimilarityThreshold
+	if threshold == 0 {
+		// Slightly lower than automatic mode for interactive exploration
+		threshold = 0.8
 	}
-	jaccard := float64(intersection) / float64(union)
-	return jaccard >= 0.8
+	return framework.SimilarityOrDefault(s.Similarity).CalculateSimilarity(a, b) >= threshold
 }
 
 // filterInteractiveGroups filters clone groups based on interactive mode settings
@@ -216,59 +168,26 @@ func filterInteractiveGroups(groups []framework.CloneGroup, settings Interactive
 }
 
 // calculateArchetypes calculates archetypes for clone groups
-func calculateArchetypes(groups *[]framework.CloneGroup) {
+func calculateArchetypes(groups *[]framework.CloneGroup, similarity framework.SimilarityCalculator) {
 	for i := range *groups {
 		group := &(*groups)[i]
 		if len(group.Fragments) == 0 {
 			continue
 		}
 
-		// Choose fragment with highest average Jaccard similarity to others
+		// Choose fragment with highest average similarity to others
 		bestIdx := 0
 		bestScore := -1.0
 		for idx, candidate := range group.Fragments {
 			total := 0.0
-			count := 0.0
 			for j, other := range group.Fragments {
-				if j == idx {
-					continue
-				}
-				// Reuse interactive similarity as a proxy score
-				// But compute raw Jaccard score to average
-				aTokens := strings.Fields(candidate.Content)
-				bTokens := strings.Fields(other.Content)
-				aSet := make(map[string]struct{})
-				for _, t := range aTokens {
-					aSet[t] = struct{}{}
-				}
-				bSet := make(map[string]struct{})
-				for _, t := range bTokens {
-					bSet[t] = struct{}{}
-				}
-				inter := 0
-				uni := 0
-				seen := make(map[string]struct{})
-				for t := range aSet {
-					if _, ok := bSet[t]; ok {
-						inter++
-					}
-					seen[t] = struct{}{}
-					uni++
-				}
-				for t := range bSet {
-					if _, ok := seen[t]; ok {
-						continue
-					}
-					uni++
-				}
-				if uni > 0 {
-					total += float64(inter) / float64(uni)
-					count++
+				if j != idx {
+					total += similarity.CalculateSimilarity(candidate.Content, other.Content)
 				}
 			}
 			score := 0.0
-			if count > 0 {
-				score = total / count
+			if len(group.Fragments) > 1 {
+				score = total / float64(len(group.Fragments)-1)
 			}
 			if score > bestScore {
 				bestScore = score
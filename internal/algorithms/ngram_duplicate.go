@@ -1,9 +1,6 @@
 package internal
 
-import (
-	"fmt"
-	"strings"
-)
+import "strings"
 
 // GenerateNGrams creates n-grams from input text.
 func GenerateNGrams(text string, n int) []string {
@@ -73,7 +70,6 @@ func FindDuplicatesByNGram(data NgramDuplicateFinderData, texts []string) map[st
 	for i := 0; i < len(texts); i++ {
 		for j := i + 1; j < len(texts); j++ {
 			similarity := CalculateNGramSimilarity(ngramMaps[i], ngramMaps[j])
-			fmt.Printf("Similarity between text %d and text %d: %.2f\n", i, j, similarity)
 
 			if similarity >= float64(data.MaxFuzzySlider)/100 {
 				duplicates[texts[i]] = append(duplicates[texts[i]], texts[j])
@@ -0,0 +1,292 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TerminologySettings configures FindTerminology.
+type TerminologySettings struct {
+	MinPhraseLength  int  // Minimum phrase length in tokens (>= 1)
+	MaxPhraseLength  int  // Maximum phrase length in tokens
+	MinFrequency     int  // Minimum occurrences of a term cluster
+	InconsistentOnly bool // Keep only clusters with more than one spelling
+	MaxTerms         int  // Limit on reported clusters; 0 means no limit
+}
+
+// TermOccurrence is one occurrence of a term in the analysed text.
+type TermOccurrence struct {
+	Text     string // Phrase as written in the document
+	Variant  string // Spelling used for clustering (sentence-initial capital removed)
+	StartPos int    // Starting token index (strings.Fields of the text)
+	EndPos   int    // Token index after the phrase
+}
+
+// TermCluster groups spelling and casing variants of one term, e.g.
+// "log-in", "login" and "Log in".
+type TermCluster struct {
+	Key         string         // Normalised key shared by all variants
+	Preferred   string         // Most frequent variant
+	Variants    map[string]int // Occurrences per variant
+	Occurrences []TermOccurrence
+
+	anchored bool // At least one variant is a term on its own
+	count    int  // Occurrences found by the counting pass
+}
+
+// Inconsistent reports whether the term is spelled in more than one way.
+func (c TermCluster) Inconsistent() bool {
+	return len(c.Variants) > 1
+}
+
+// termStopwords are function words that never start or end a term.
+var termStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "do": true, "does": true, "for": true, "from": true, "has": true, "have": true,
+	"if": true, "in": true, "into": true, "is": true, "it": true, "its": true, "may": true, "must": true,
+	"not": true, "of": true, "on": true, "or": true, "should": true, "so": true, "that": true,
+	"the": true, "then": true, "this": true, "these": true, "those": true, "to": true, "was": true,
+	"were": true, "when": true, "which": true, "will": true, "with": true, "you": true, "your": true,
+}
+
+// termToken is a whitespace-separated token stripped of surrounding punctuation.
+type termToken struct {
+	word          string
+	sentenceStart bool // First word of a sentence
+	breakAfter    bool // A phrase must not continue past this token
+}
+
+// FindTerminology finds frequent phrases of MinPhraseLength..MaxPhraseLength
+// tokens and clusters variants that differ only in case, hyphenation or
+// spacing. Phrases never cross sentence or clause punctuation. Clusters are
+// ordered inconsistent first, then by frequency.
+func FindTerminology(text string, settings TerminologySettings) []TermCluster {
+	var out []TermCluster
+	StreamTerminology(text, settings, func(c TermCluster) error {
+		out = append(out, c)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return termRanksBefore(&out[i], &out[j]) })
+	return out
+}
+
+// StreamTerminology finds the same clusters as FindTerminology but calls
+// emit for each one as soon as its last occurrence has been read, so clusters
+// arrive in the order they end in the text. A first pass only counts the
+// occurrences and variants of every phrase; the second pass keeps the
+// occurrences of the clusters that are not yet complete. An error returned
+// by emit stops the search and is returned unchanged.
+func StreamTerminology(text string, settings TerminologySettings, emit func(TermCluster) error) error {
+	minFreq := settings.MinFrequency
+	if minFreq < 1 {
+		minFreq = 1
+	}
+	tokens := tokenizeTerms(text)
+
+	counted := map[string]*TermCluster{}
+	eachTermPhrase(tokens, settings, func(key string, o TermOccurrence, anchored bool) bool {
+		c, ok := counted[key]
+		if !ok {
+			c = &TermCluster{Key: key, Variants: map[string]int{}}
+			counted[key] = c
+		}
+		c.anchored = c.anchored || anchored
+		c.Variants[o.Variant]++
+		c.count++
+		return true
+	})
+
+	var selected []*TermCluster
+	for _, c := range counted {
+		if !c.anchored || c.count < minFreq {
+			continue
+		}
+		if settings.InconsistentOnly && !c.Inconsistent() {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	if settings.MaxTerms > 0 && len(selected) > settings.MaxTerms {
+		sort.Slice(selected, func(i, j int) bool { return termRanksBefore(selected[i], selected[j]) })
+		selected = selected[:settings.MaxTerms]
+	}
+	open := make(map[string]*TermCluster, len(selected))
+	for _, c := range selected {
+		c.Preferred = preferredVariant(c.Variants)
+		open[c.Key] = c
+	}
+
+	var err error
+	eachTermPhrase(tokens, settings, func(key string, o TermOccurrence, _ bool) bool {
+		c, ok := open[key]
+		if !ok {
+			return true
+		}
+		c.Occurrences = append(c.Occurrences, o)
+		if len(c.Occurrences) < c.count {
+			return true
+		}
+		delete(open, key)
+		err = emit(*c)
+		return err == nil
+	})
+	return err
+}
+
+// eachTermPhrase calls fn for every candidate phrase of tokens in text order,
+// with its cluster key and whether it is a term on its own. It stops when fn
+// returns false.
+func eachTermPhrase(tokens []termToken, settings TerminologySettings, fn func(key string, o TermOccurrence, anchored bool) bool) {
+	minLen := settings.MinPhraseLength
+	if minLen < 1 {
+		minLen = 1
+	}
+	maxLen := settings.MaxPhraseLength
+	if maxLen < minLen {
+		maxLen = minLen
+	}
+
+	for i := range tokens {
+		for n := minLen; n <= maxLen && i+n <= len(tokens); n++ {
+			phrase := tokens[i : i+n]
+			if phrase[0].word == "" || breaksWithin(phrase) {
+				break
+			}
+			// A phrase ending in a stopword ("log in") is kept only as a
+			// variant of a cluster anchored by a proper term ("login").
+			anchored := isTermCandidate(phrase)
+			if !anchored && !isVariantCandidate(phrase) {
+				continue
+			}
+			words := make([]string, n)
+			for k, t := range phrase {
+				words[k] = t.word
+			}
+			surface := strings.Join(words, " ")
+			variant := surface
+			if phrase[0].sentenceStart {
+				variant = lowerInitial(variant)
+			}
+			if !fn(termKey(surface), TermOccurrence{Text: surface, Variant: variant, StartPos: i, EndPos: i + n}, anchored) {
+				return
+			}
+		}
+	}
+}
+
+// termRanksBefore orders clusters inconsistent first, then by frequency.
+func termRanksBefore(a, b *TermCluster) bool {
+	if a.Inconsistent() != b.Inconsistent() {
+		return a.Inconsistent()
+	}
+	if a.count != b.count {
+		return a.count > b.count
+	}
+	return a.Key < b.Key
+}
+
+// tokenizeTerms splits text like strings.Fields, so token indices match the
+// framework's positions, and records sentence and clause boundaries.
+func tokenizeTerms(text string) []termToken {
+	fields := strings.Fields(text)
+	tokens := make([]termToken, len(fields))
+	sentenceStart := true
+	for i, f := range fields {
+		word := strings.TrimFunc(f, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		lead := strings.Index(f, word)
+		trail := f[lead+len(word):]
+		if word == "" {
+			trail = f
+		}
+		tokens[i] = termToken{
+			word:          word,
+			sentenceStart: sentenceStart,
+			breakAfter:    word == "" || trail != "",
+		}
+		// Leading punctuation ("(login") separates the word from its predecessor.
+		if i > 0 && lead > 0 {
+			tokens[i-1].breakAfter = true
+		}
+		if strings.ContainsAny(trail, ".!?") {
+			sentenceStart = true
+		} else if word != "" {
+			sentenceStart = false
+		}
+	}
+	return tokens
+}
+
+// breaksWithin reports whether punctuation separates two tokens of phrase.
+func breaksWithin(phrase []termToken) bool {
+	for _, t := range phrase[:len(phrase)-1] {
+		if t.breakAfter {
+			return true
+		}
+	}
+	return false
+}
+
+// isTermCandidate rejects phrases that start or end with a stopword, consist
+// of numbers only, or are single words shorter than three letters.
+func isTermCandidate(phrase []termToken) bool {
+	first := strings.ToLower(phrase[0].word)
+	last := strings.ToLower(phrase[len(phrase)-1].word)
+	if last == "" || termStopwords[first] || termStopwords[last] {
+		return false
+	}
+	if len(phrase) == 1 && utf8.RuneCountInString(first) < 3 {
+		return false
+	}
+	for _, t := range phrase {
+		if strings.IndexFunc(t.word, unicode.IsLetter) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isVariantCandidate accepts multi-word phrases that may spell a term
+// differently even though they end with a stopword.
+func isVariantCandidate(phrase []termToken) bool {
+	first := strings.ToLower(phrase[0].word)
+	return len(phrase) > 1 && !termStopwords[first] && strings.IndexFunc(first, unicode.IsLetter) >= 0
+}
+
+// termKey normalises a phrase for clustering: lower-cased with everything but
+// letters and digits removed, so "Log in", "log-in" and "login" share a key.
+func termKey(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// lowerInitial lower-cases the first letter of s unless the first word looks
+// like an acronym or proper noun with further capitals ("API", "GitHub").
+func lowerInitial(s string) string {
+	first, _, _ := strings.Cut(s, " ")
+	r, size := utf8.DecodeRuneInString(first)
+	if !unicode.IsUpper(r) || strings.IndexFunc(first[size:], unicode.IsUpper) >= 0 {
+		return s
+	}
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// preferredVariant returns the most frequent variant, breaking ties
+// lexicographically.
+func preferredVariant(variants map[string]int) string {
+	best, bestN := "", -1
+	for v, n := range variants {
+		if n > bestN || (n == bestN && v < best) {
+			best, bestN = v, n
+		}
+	}
+	return best
+}
@@ -2,54 +2,6 @@ package framework
 
 import "strings"
 
-// JaccardSimilarityCalculator implements SimilarityCalculator using Jaccard similarity.
-// It is kept in the framework package because it does not depend on any of the
-// algorithm or report implementation packages and can be reused by plugins.
-type JaccardSimilarityCalculator struct{}
-
-func (j *JaccardSimilarityCalculator) Name() string {
-	return "jaccard"
-}
-
-func (j *JaccardSimilarityCalculator) CalculateSimilarity(text1, text2 string) float64 {
-	tokens1 := strings.Fields(text1)
-	tokens2 := strings.Fields(text2)
-
-	set1 := make(map[string]struct{})
-	for _, t := range tokens1 {
-		set1[t] = struct{}{}
-	}
-
-	set2 := make(map[string]struct{})
-	for _, t := range tokens2 {
-		set2[t] = struct{}{}
-	}
-
-	intersection := 0
-	union := 0
-	seen := make(map[string]struct{})
-
-	for t := range set1 {
-		if _, ok := set2[t]; ok {
-			intersection++
-		}
-		seen[t] = struct{}{}
-		union++
-	}
-
-	for t := range set2 {
-		if _, ok := seen[t]; !ok {
-			union++
-		}
-	}
-
-	if union == 0 {
-		return 0.0
-	}
-
-	return float64(intersection) / float64(union)
-}
-
 // SpaceTokenizer implements TextTokenizer using space-based tokenization.
 type SpaceTokenizer struct{}
 
@@ -68,6 +20,12 @@ func (s *StrictFilter) Name() string {
 	return "strict"
 }
 
+// Params declares no parameters; the filter is configured by the
+// MinArchetypeLength and RemoveOverlaps fields of FilterConfig.
+func (s *StrictFilter) Params() []ParamSpec {
+	return []ParamSpec{}
+}
+
 func (s *StrictFilter) Filter(groups []CloneGroup, config FilterConfig) []CloneGroup {
 	var filtered []CloneGroup
 
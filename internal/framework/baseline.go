@@ -0,0 +1,199 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+// Metadata keys set on clone groups matched by a baseline entry.
+const (
+	SuppressedMetadataKey         = "suppressed"
+	SuppressionReasonMetadataKey  = "suppression_reason"
+	SuppressionExpiresMetadataKey = "suppression_expires"
+)
+
+// baselineDateLayout is the date format used for BaselineEntry.Expires.
+const baselineDateLayout = "2006-01-02"
+
+// Baseline lists clone groups that were reviewed and accepted as intentional
+// repetition (legal notices, safety warnings, ...). Matching groups are kept in
+// the analysis result but marked as suppressed.
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry accepts either a single group (by stable ID) or every group
+// whose normalised archetype matches Pattern.
+type BaselineEntry struct {
+	GroupID string `json:"group_id,omitempty"` // Stable group ID, see GroupID
+	Pattern string `json:"pattern,omitempty"`  // Regular expression matched against the normalised archetype
+	Reason  string `json:"reason"`             // Why the repetition is accepted
+	Expires string `json:"expires,omitempty"`  // Optional expiry date (YYYY-MM-DD); expired entries stop suppressing
+
+	pattern *regexp.Regexp
+	expires time.Time
+}
+
+// StaleBaselineEntry reports a baseline entry that should be reviewed.
+type StaleBaselineEntry struct {
+	Entry  BaselineEntry `json:"entry"`
+	Status string        `json:"status"` // "expired" or "unmatched"
+}
+
+// LoadBaseline reads and validates a JSON baseline file.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// Validate checks every entry and compiles patterns and expiry dates.
+func (b *Baseline) Validate() error {
+	for i := range b.Entries {
+		e := &b.Entries[i]
+		if e.GroupID == "" && e.Pattern == "" {
+			return fmt.Errorf("entry %d: either group_id or pattern is required", i+1)
+		}
+		if e.Pattern != "" {
+			re, err := regexp.Compile(e.Pattern)
+			if err != nil {
+				return fmt.Errorf("entry %d: bad pattern: %v", i+1, err)
+			}
+			e.pattern = re
+		}
+		if e.Expires != "" {
+			t, err := time.Parse(baselineDateLayout, e.Expires)
+			if err != nil {
+				return fmt.Errorf("entry %d: bad expires date %q (want YYYY-MM-DD)", i+1, e.Expires)
+			}
+			e.expires = t
+		}
+	}
+	return nil
+}
+
+// Apply marks groups matched by active entries as suppressed and returns the
+// entries that are expired or did not match any group.
+func (b *Baseline) Apply(groups []CloneGroup, now time.Time) []StaleBaselineEntry {
+	if b == nil {
+		return nil
+	}
+	m := b.matcher(now)
+	for gi := range groups {
+		m.apply(&groups[gi])
+	}
+	return m.stale()
+}
+
+// baselineMatcher applies a baseline one group at a time, remembering which
+// entries matched so stale entries can be reported after the last group.
+type baselineMatcher struct {
+	b       *Baseline
+	expired []bool
+	matched []bool
+}
+
+func (b *Baseline) matcher(now time.Time) *baselineMatcher {
+	m := &baselineMatcher{
+		b:       b,
+		expired: make([]bool, len(b.Entries)),
+		matched: make([]bool, len(b.Entries)),
+	}
+	for i := range b.Entries {
+		e := &b.Entries[i]
+		if e.Pattern != "" && e.pattern == nil {
+			// Entries built in code may skip Validate; a bad pattern simply never matches.
+			if re, err := regexp.Compile(e.Pattern); err == nil {
+				e.pattern = re
+			}
+		}
+		m.expired[i] = e.isExpired(now)
+	}
+	return m
+}
+
+// apply suppresses g if an active entry matches it; the first matching entry
+// provides the reason.
+func (m *baselineMatcher) apply(g *CloneGroup) {
+	for i := range m.b.Entries {
+		e := &m.b.Entries[i]
+		if m.expired[i] || !e.matches(*g) {
+			continue
+		}
+		m.matched[i] = true
+		if IsSuppressed(*g) {
+			continue
+		}
+		if g.Metadata == nil {
+			g.Metadata = map[string]interface{}{}
+		}
+		g.Metadata[SuppressedMetadataKey] = true
+		g.Metadata[SuppressionReasonMetadataKey] = e.Reason
+		if e.Expires != "" {
+			g.Metadata[SuppressionExpiresMetadataKey] = e.Expires
+		}
+	}
+}
+
+// stale returns expired entries and entries that matched no group so far.
+func (m *baselineMatcher) stale() []StaleBaselineEntry {
+	var stale []StaleBaselineEntry
+	for i, e := range m.b.Entries {
+		switch {
+		case m.expired[i]:
+			stale = append(stale, StaleBaselineEntry{Entry: e, Status: "expired"})
+		case !m.matched[i]:
+			stale = append(stale, StaleBaselineEntry{Entry: e, Status: "unmatched"})
+		}
+	}
+	return stale
+}
+
+func (e *BaselineEntry) isExpired(now time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+	if e.expires.IsZero() {
+		t, err := time.Parse(baselineDateLayout, e.Expires)
+		if err != nil {
+			return false
+		}
+		e.expires = t
+	}
+	// The expiry date itself is still valid.
+	return now.After(e.expires.AddDate(0, 0, 1))
+}
+
+func (e *BaselineEntry) matches(g CloneGroup) bool {
+	if e.GroupID != "" && e.GroupID == groupIDOf(g) {
+		return true
+	}
+	if e.pattern != nil {
+		text := g.Archetype
+		if text == "" && len(g.Fragments) > 0 {
+			text = g.Fragments[0].Content
+		}
+		return e.pattern.MatchString(NormalizeArchetype(text))
+	}
+	return false
+}
+
+// IsSuppressed reports whether a group was accepted by a baseline entry.
+func IsSuppressed(g CloneGroup) bool {
+	v, _ := g.Metadata[SuppressedMetadataKey].(bool)
+	return v
+}
@@ -8,11 +8,15 @@ func RegisterBuiltInPlugins(reg *PluginRegistry) error {
 	if err := reg.RegisterTextTokenizer(&SpaceTokenizer{}); err != nil {
 		return err
 	}
-	if err := reg.RegisterSimilarityCalculator(&JaccardSimilarityCalculator{}); err != nil {
-		return err
+	for _, calc := range []SimilarityCalculator{&JaccardSimilarityCalculator{}, &DiceSimilarityCalculator{}, &LevenshteinSimilarityCalculator{}, &LCSSimilarityCalculator{}, &CosineSimilarityCalculator{}} {
+		if err := reg.RegisterSimilarityCalculator(calc); err != nil {
+			return err
+		}
 	}
-	if err := reg.RegisterFilter(&StrictFilter{}); err != nil {
-		return err
+	for _, filter := range []Filter{&StrictFilter{}, &LengthFilter{}, &PowerFilter{}, &ExcludeFilter{}, &ContainmentFilter{}} {
+		if err := reg.RegisterFilter(filter); err != nil {
+			return err
+		}
 	}
 	return nil
 }
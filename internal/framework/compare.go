@@ -0,0 +1,269 @@
+package framework
+
+import (
+	"sort"
+	"strings"
+)
+
+// Change kinds reported by CompareResults.
+const (
+	GroupNew       = "new"
+	GroupRemoved   = "removed"
+	GroupGrown     = "grown"
+	GroupShrunk    = "shrunk"
+	GroupUnchanged = "unchanged"
+)
+
+// Metadata keys set on groups of a comparison rendered via AsAnalysisResult.
+const (
+	DiffStatusMetadataKey = "diff_status"
+	OldPowerMetadataKey   = "old_power"
+	NewPowerMetadataKey   = "new_power"
+)
+
+// GroupChange describes how one clone group differs between two results.
+type GroupChange struct {
+	ID       string     // Stable group ID, see GroupID
+	Kind     string     // One of GroupNew, GroupRemoved, GroupGrown, GroupShrunk, GroupUnchanged
+	OldPower int        // Power in the old result (0 for new groups)
+	NewPower int        // Power in the new result (0 for removed groups)
+	Group    CloneGroup // Group from the new result, or from the old one when removed
+}
+
+// ResultComparison is the outcome of CompareResults.
+type ResultComparison struct {
+	New       []GroupChange
+	Removed   []GroupChange
+	Grown     []GroupChange
+	Shrunk    []GroupChange
+	Unchanged []GroupChange
+
+	OldSource string
+	NewSource string
+
+	// TotalTokens of the new result, for the statistics of AsAnalysisResult
+	TotalTokens int
+}
+
+// CompareResults matches clone groups of two analysis results by their stable
+// group ID and classifies them as new, removed, grown, shrunk or unchanged.
+// Groups sharing an ID (the same archetype) are paired by the overlap of
+// their fragment texts, see matchColliding. Either result may be nil, which
+// is treated as an empty result.
+func CompareResults(old, new *AnalysisResult) *ResultComparison {
+	cmp := &ResultComparison{
+		OldSource: sourceOf(old),
+		NewSource: sourceOf(new),
+	}
+	if new != nil {
+		cmp.TotalTokens = new.Statistics.TotalTokens
+	}
+
+	oldIDs, oldGroups := indexGroups(old)
+	newIDs, newGroups := indexGroups(new)
+
+	matched := map[string]map[int]bool{} // Old groups paired, by ID
+	for _, id := range newIDs {
+		news, olds := newGroups[id], oldGroups[id]
+		pairs := matchColliding(olds, news)
+		matched[id] = map[int]bool{}
+		for ni, ng := range news {
+			change := GroupChange{ID: ng.id, NewPower: groupPower(ng.CloneGroup), Group: ng.CloneGroup}
+			oi, existed := pairs[ni]
+			if existed {
+				matched[id][oi] = true
+				change.OldPower = groupPower(olds[oi].CloneGroup)
+			}
+			switch {
+			case !existed:
+				change.Kind = GroupNew
+				cmp.New = append(cmp.New, change)
+			case change.NewPower > change.OldPower:
+				change.Kind = GroupGrown
+				cmp.Grown = append(cmp.Grown, change)
+			case change.NewPower < change.OldPower:
+				change.Kind = GroupShrunk
+				cmp.Shrunk = append(cmp.Shrunk, change)
+			default:
+				change.Kind = GroupUnchanged
+				cmp.Unchanged = append(cmp.Unchanged, change)
+			}
+		}
+	}
+
+	for _, id := range oldIDs {
+		for oi, og := range oldGroups[id] {
+			if matched[id][oi] {
+				continue
+			}
+			cmp.Removed = append(cmp.Removed, GroupChange{
+				ID:       og.id,
+				Kind:     GroupRemoved,
+				OldPower: groupPower(og.CloneGroup),
+				Group:    og.CloneGroup,
+			})
+		}
+	}
+
+	return cmp
+}
+
+// HasChanges reports whether any group was added, removed, grown or shrunk.
+func (c *ResultComparison) HasChanges() bool {
+	return len(c.New)+len(c.Removed)+len(c.Grown)+len(c.Shrunk) > 0
+}
+
+// Changes returns all changed groups (unchanged ones excluded) in the order
+// new, grown, shrunk, removed.
+func (c *ResultComparison) Changes() []GroupChange {
+	var all []GroupChange
+	all = append(all, c.New...)
+	all = append(all, c.Grown...)
+	all = append(all, c.Shrunk...)
+	all = append(all, c.Removed...)
+	return all
+}
+
+// AsAnalysisResult converts the comparison into an AnalysisResult so that it
+// can be rendered by any registered report generator. Every changed group
+// carries "diff_status", "old_power" and "new_power" metadata.
+func (c *ResultComparison) AsAnalysisResult() *AnalysisResult {
+	changes := c.Changes()
+	groups := make([]CloneGroup, 0, len(changes))
+	for _, ch := range changes {
+		g := ch.Group
+		meta := make(map[string]interface{}, len(g.Metadata)+3)
+		for k, v := range g.Metadata {
+			meta[k] = v
+		}
+		meta[GroupIDMetadataKey] = ch.ID
+		meta[DiffStatusMetadataKey] = ch.Kind
+		meta[OldPowerMetadataKey] = ch.OldPower
+		meta[NewPowerMetadataKey] = ch.NewPower
+		g.Metadata = meta
+		groups = append(groups, g)
+	}
+
+	// Statistics as computed for an analysis, with whitespace tokens since no
+	// tokenizer is configured here. Positions of removed groups refer to the
+	// old document, so they do not count towards DuplicatedTokens.
+	all, current := statsAccumulatorWith(strings.Fields), statsAccumulatorWith(strings.Fields)
+	for _, g := range groups {
+		all.add(g)
+		if g.Metadata[DiffStatusMetadataKey] != GroupRemoved {
+			current.add(g)
+		}
+	}
+	stats := all.statistics()
+	stats.DuplicatedTokens = current.statistics().DuplicatedTokens
+	stats.TotalTokens = c.TotalTokens
+
+	return &AnalysisResult{
+		Groups:     groups,
+		Statistics: stats,
+		Metadata: map[string]interface{}{
+			"source_file": c.OldSource + " -> " + c.NewSource,
+			"old_source":  c.OldSource,
+			"new_source":  c.NewSource,
+			"comparison": map[string]int{
+				GroupNew:       len(c.New),
+				GroupRemoved:   len(c.Removed),
+				GroupGrown:     len(c.Grown),
+				GroupShrunk:    len(c.Shrunk),
+				GroupUnchanged: len(c.Unchanged),
+			},
+		},
+	}
+}
+
+// identifiedGroup is a group with its ID, made unique as in the analysis.
+type identifiedGroup struct {
+	CloneGroup
+	id string
+}
+
+// indexGroups returns the group IDs without their "-N" suffixes in
+// first-seen order and the groups sharing each ID in result order.
+func indexGroups(r *AnalysisResult) ([]string, map[string][]identifiedGroup) {
+	byID := map[string][]identifiedGroup{}
+	var order []string
+	if r == nil {
+		return order, byID
+	}
+	ids := groupIDs{}
+	for _, g := range r.Groups {
+		id := ids.unique(groupIDOf(g))
+		base := baseGroupID(id)
+		if _, seen := byID[base]; !seen {
+			order = append(order, base)
+		}
+		byID[base] = append(byID[base], identifiedGroup{g, id})
+	}
+	return order, byID
+}
+
+// matchColliding pairs new groups with old groups of the same ID and returns
+// the old index for every paired new index. With one group on each side they
+// are paired; otherwise pairs are chosen by descending overlap of their
+// normalised fragment texts, ties in result order, so the outcome does not
+// depend on anything but the two results.
+func matchColliding(olds, news []identifiedGroup) map[int]int {
+	pairs := map[int]int{}
+	if len(olds) == 0 || len(news) == 0 {
+		return pairs
+	}
+	if len(olds) == 1 && len(news) == 1 {
+		pairs[0] = 0
+		return pairs
+	}
+
+	type candidate struct{ oi, ni, shared int }
+	var candidates []candidate
+	for ni, ng := range news {
+		texts := fragmentTexts(ng.CloneGroup)
+		for oi, og := range olds {
+			shared := 0
+			for text := range fragmentTexts(og.CloneGroup) {
+				if texts[text] {
+					shared++
+				}
+			}
+			candidates = append(candidates, candidate{oi, ni, shared})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].shared > candidates[j].shared })
+
+	usedOld := map[int]bool{}
+	for _, c := range candidates {
+		if _, done := pairs[c.ni]; done || usedOld[c.oi] {
+			continue
+		}
+		pairs[c.ni] = c.oi
+		usedOld[c.oi] = true
+	}
+	return pairs
+}
+
+// fragmentTexts returns the distinct normalised fragment texts of g.
+func fragmentTexts(g CloneGroup) map[string]bool {
+	texts := make(map[string]bool, len(g.Fragments))
+	for _, f := range g.Fragments {
+		texts[NormalizeArchetype(f.Content)] = true
+	}
+	return texts
+}
+
+func groupPower(g CloneGroup) int {
+	if g.Power > 0 {
+		return g.Power
+	}
+	return len(g.Fragments)
+}
+
+func sourceOf(r *AnalysisResult) string {
+	if r == nil || r.Metadata == nil {
+		return ""
+	}
+	s, _ := r.Metadata["source_file"].(string)
+	return s
+}
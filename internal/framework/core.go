@@ -1,11 +1,14 @@
 package framework
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -13,6 +16,7 @@ import (
 type Framework struct {
 	registry *PluginRegistry
 	config   *Config
+	baseline *Baseline
 }
 
 // Config holds framework-wide configuration
@@ -22,6 +26,8 @@ type Config struct {
 	DefaultTokenizer      string
 	DefaultReportFormat   string
 	ResultsDirectory      string
+	BaselineFile          string       // Optional JSON baseline of accepted clone groups
+	Filters               []FilterStep // Registered filters applied in order after every finder run
 	EnableLogging         bool
 	CustomSettings        map[string]interface{}
 }
@@ -46,68 +52,177 @@ func (f *Framework) GetRegistry() *PluginRegistry {
 	return f.registry
 }
 
+// SetBaseline sets the baseline of accepted clone groups applied after every
+// finder run. It takes precedence over Config.BaselineFile; nil disables it.
+func (f *Framework) SetBaseline(b *Baseline) {
+	f.baseline = b
+}
+
 // AnalyzeDocument performs complete analysis of a document
 func (f *Framework) AnalyzeDocument(filePath string, finderName string, finderConfig CloneFinderConfig) (*AnalysisResult, error) {
-    // Read and parse document (existing behavior)
-    content, err := f.readDocument(filePath)
-    if err != nil {
-        return nil, fmt.Errorf("failed to read document: %v", err)
-    }
-
-    // Heuristic mode: enforce .reformatted as the analysis source
-    if finderName == "heuristic" {
-        normalized := normalizeReformattedContent(content)
-
-        reformattedPath := filePath + ".reformatted"
-        if err := os.WriteFile(reformattedPath, []byte(normalized), 0o644); err != nil {
-            return nil, fmt.Errorf("write reformatted file: %w", err)
-        }
-
-        b, err := os.ReadFile(reformattedPath)
-        if err != nil {
-            return nil, fmt.Errorf("read reformatted file: %w", err)
-        }
-        content = string(b)
-
-        if finderConfig.CustomParams == nil {
-            finderConfig.CustomParams = map[string]interface{}{}
-        }
-        finderConfig.CustomParams["reformatted_file"] = reformattedPath
-        finderConfig.CustomParams["source_file"] = filePath
-    }
-
-    finder, err := f.registry.GetCloneFinder(finderName)
-    if err != nil {
-        return nil, fmt.Errorf("failed to get clone finder: %v", err)
-    }
-
-    groups, err := finder.FindClones(content, finderConfig)
-    if err != nil {
-        return nil, fmt.Errorf("failed to find clones: %v", err)
-    }
-
-    annotateFragmentsWithLineNumbers(content, groups)
-    totalTokens := countFieldsTokens(content)
-
-    stats := f.calculateStatistics(groups)
-
-    result := &AnalysisResult{
-        Groups:     groups,
-        Statistics: stats,
-        Config:     finderConfig,
-        Metadata: map[string]interface{}{
-            "source_file": filePath,
-            "finder":      finderName,
-            "total_tokens": totalTokens,
-        },
-    }
-
-    // (optional) expose reformatted path on result metadata too
-    if finderName == "heuristic" {
-        result.Metadata["reformatted_file"] = filePath + ".reformatted"
-    }
-
-    return result, nil
+	content, finder, err := f.prepareAnalysis(filePath, finderName, &finderConfig)
+	if err != nil {
+		return nil, err
+	}
+	return f.analyzeContent(content, filePath, finder, finderConfig)
+}
+
+// AnalyzeReader analyses a document read from r without touching the file
+// system. ext selects the registered parser (e.g. ".xml"; the dot is
+// optional); formats without a parser are converted in memory when the
+// converter supports it (see StreamConverter) and otherwise read as plain
+// text, as is an empty ext. The result's "source_file" metadata is empty.
+func (f *Framework) AnalyzeReader(r io.Reader, ext string, finderName string, finderConfig CloneFinderConfig) (*AnalysisResult, error) {
+	finder, err := f.resolveFinder(finderName, &finderConfig)
+	if err != nil {
+		return nil, err
+	}
+	content, err := f.ExtractText(r, ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %v", err)
+	}
+	if finderName == "heuristic" {
+		content = normalizeReformattedContent(content)
+	}
+	fitSimilarity(content, &finderConfig)
+	return f.analyzeContent(content, "", finder, finderConfig)
+}
+
+// ExtractText returns the text finders analyse for a document read from r, in
+// the format given by ext as in AnalyzeReader. Token positions of clone
+// fragments refer to strings.Fields of this text.
+func (f *Framework) ExtractText(r io.Reader, ext string) (string, error) {
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return f.parseDocument(r, ext)
+}
+
+// AnalyzeText analyses text held in memory; see AnalyzeReader.
+func (f *Framework) AnalyzeText(text string, ext string, finderName string, finderConfig CloneFinderConfig) (*AnalysisResult, error) {
+	return f.AnalyzeReader(strings.NewReader(text), ext, finderName, finderConfig)
+}
+
+// SelectGroups returns a copy of result with only the groups keep accepts.
+// Statistics are recomputed; the analysed text's TotalTokens is kept.
+func (f *Framework) SelectGroups(result *AnalysisResult, keep func(CloneGroup) bool) *AnalysisResult {
+	groups := make([]CloneGroup, 0, len(result.Groups))
+	for _, g := range result.Groups {
+		if keep(g) {
+			groups = append(groups, g)
+		}
+	}
+	stats := f.calculateStatistics(groups)
+	stats.TotalTokens = result.Statistics.TotalTokens
+
+	metadata := make(map[string]interface{}, len(result.Metadata))
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+	return &AnalysisResult{
+		Groups:     groups,
+		Statistics: stats,
+		Config:     result.Config,
+		Metadata:   metadata,
+	}
+}
+
+// analyzeContent runs the finder on the parsed text followed by the
+// post-finder stage: filters, line numbers, stable group IDs, baseline
+// suppression and statistics.
+func (f *Framework) analyzeContent(content, filePath string, finder CloneFinder, finderConfig CloneFinderConfig) (*AnalysisResult, error) {
+	groups, err := finder.FindClones(content, finderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find clones: %v", err)
+	}
+	if groups, err = f.applyFilters(groups); err != nil {
+		return nil, err
+	}
+
+	annotateFragmentsWithLineNumbers(content, groups)
+	totalTokens := countFieldsTokens(content)
+
+	// Post-finder stage: stable group IDs and suppression of accepted groups.
+	assignGroupIDs(groups)
+	baseline, err := f.activeBaseline()
+	if err != nil {
+		return nil, err
+	}
+	stale := baseline.Apply(groups, time.Now())
+
+	stats := f.calculateStatistics(groups)
+	stats.TotalTokens = totalTokens
+
+	result := &AnalysisResult{
+		Groups:     groups,
+		Statistics: stats,
+		Config:     finderConfig,
+		Metadata:   resultMetadata(filePath, finder.Name(), totalTokens),
+	}
+
+	if baseline != nil {
+		result.Metadata["stale_baseline_entries"] = stale
+	}
+
+	return result, nil
+}
+
+// prepareAnalysis reads the document and resolves the finder and similarity
+// calculator. For the heuristic finder the analysed text is the normalised
+// ".reformatted" file, whose path is passed to the finder in CustomParams.
+func (f *Framework) prepareAnalysis(filePath string, finderName string, finderConfig *CloneFinderConfig) (string, CloneFinder, error) {
+	// Parameters are validated before the framework adds its own below
+	finder, err := f.resolveFinder(finderName, finderConfig)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Read and parse document (existing behavior)
+	content, err := f.readDocument(filePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read document: %v", err)
+	}
+
+	// Heuristic mode: enforce .reformatted as the analysis source
+	if finderName == "heuristic" {
+		normalized := normalizeReformattedContent(content)
+
+		reformattedPath := filePath + ".reformatted"
+		if err := os.WriteFile(reformattedPath, []byte(normalized), 0o644); err != nil {
+			return "", nil, fmt.Errorf("write reformatted file: %w", err)
+		}
+
+		b, err := os.ReadFile(reformattedPath)
+		if err != nil {
+			return "", nil, fmt.Errorf("read reformatted file: %w", err)
+		}
+		content = string(b)
+
+		// Copied so the caller's map is left as it was
+		params := make(map[string]interface{}, len(finderConfig.CustomParams)+2)
+		for k, v := range finderConfig.CustomParams {
+			params[k] = v
+		}
+		finderConfig.CustomParams = params
+		finderConfig.CustomParams["reformatted_file"] = reformattedPath
+		finderConfig.CustomParams["source_file"] = filePath
+	}
+	fitSimilarity(content, finderConfig)
+	return content, finder, nil
+}
+
+// resultMetadata returns the metadata every analysis result starts with.
+func resultMetadata(filePath, finderName string, totalTokens int) map[string]interface{} {
+	md := map[string]interface{}{
+		"source_file":  filePath,
+		"finder":       finderName,
+		"total_tokens": totalTokens,
+	}
+	// (optional) expose reformatted path on result metadata too
+	if finderName == "heuristic" && filePath != "" {
+		md["reformatted_file"] = filePath + ".reformatted"
+	}
+	return md
 }
 
 func countFieldsTokens(s string) int {
@@ -129,11 +244,22 @@ func annotateFragmentsWithLineNumbers(text string, groups []CloneGroup) {
 		return
 	}
 
-	// tokenLines[i] = 1-based line number where token i starts
-	tokenLines := make([]int, maxEnd)
+	tokenLines := tokenLineIndex(text, maxEnd)
+	for gi := range groups {
+		annotateGroupLines(tokenLines, &groups[gi])
+	}
+}
+
+// tokenLineIndex returns the 1-based line number where each of the first
+// maxTokens whitespace-separated tokens of text starts; maxTokens < 0 indexes
+// every token.
+func tokenLineIndex(text string, maxTokens int) []int {
+	var tokenLines []int
+	if maxTokens >= 0 {
+		tokenLines = make([]int, 0, maxTokens)
+	}
 	line := 1
 	inToken := false
-	tokenIdx := 0
 
 	for _, r := range text {
 		if r == '\n' {
@@ -146,97 +272,118 @@ func annotateFragmentsWithLineNumbers(text string, groups []CloneGroup) {
 		}
 
 		if !inToken {
-			if tokenIdx >= len(tokenLines) {
+			if maxTokens >= 0 && len(tokenLines) >= maxTokens {
 				break
 			}
-			tokenLines[tokenIdx] = line
-			tokenIdx++
+			tokenLines = append(tokenLines, line)
 			inToken = true
 		}
 	}
+	return tokenLines
+}
 
-	for gi := range groups {
-		for fi := range groups[gi].Fragments {
-			fr := &groups[gi].Fragments[fi]
-			if fr.Metadata == nil {
-				fr.Metadata = map[string]interface{}{}
-			}
-			if fr.StartPos >= 0 && fr.StartPos < len(tokenLines) && tokenLines[fr.StartPos] > 0 {
-				fr.Metadata["source_line_start"] = tokenLines[fr.StartPos]
-			}
-			endTok := fr.EndPos - 1
-			if endTok < 0 {
-				endTok = 0
-			}
-			if endTok >= len(tokenLines) {
-				endTok = len(tokenLines) - 1
-			}
-			if len(tokenLines) > 0 && endTok >= 0 && endTok < len(tokenLines) && tokenLines[endTok] > 0 {
-				fr.Metadata["source_line_end"] = tokenLines[endTok]
-			}
+// annotateGroupLines sets SourceLineStartMetadataKey/SourceLineEndMetadataKey fragment
+// metadata from a token line index.
+func annotateGroupLines(tokenLines []int, g *CloneGroup) {
+	for fi := range g.Fragments {
+		fr := &g.Fragments[fi]
+		if fr.Metadata == nil {
+			fr.Metadata = map[string]interface{}{}
+		}
+		if fr.StartPos >= 0 && fr.StartPos < len(tokenLines) && tokenLines[fr.StartPos] > 0 {
+			fr.Metadata[SourceLineStartMetadataKey] = tokenLines[fr.StartPos]
+		}
+		endTok := fr.EndPos - 1
+		if endTok < 0 {
+			endTok = 0
+		}
+		if endTok >= len(tokenLines) {
+			endTok = len(tokenLines) - 1
+		}
+		if len(tokenLines) > 0 && endTok >= 0 && endTok < len(tokenLines) && tokenLines[endTok] > 0 {
+			fr.Metadata[SourceLineEndMetadataKey] = tokenLines[endTok]
 		}
 	}
 }
 
+// activeBaseline returns the baseline set with SetBaseline or loaded from
+// Config.BaselineFile, or nil when suppression is not configured.
+func (f *Framework) activeBaseline() (*Baseline, error) {
+	if f.baseline != nil {
+		return f.baseline, nil
+	}
+	if f.config.BaselineFile == "" {
+		return nil, nil
+	}
+	b, err := LoadBaseline(f.config.BaselineFile)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
 // AnalyzeDocumentWithConfig is an alias for AnalyzeDocument with explicit config
 func (f *Framework) AnalyzeDocumentWithConfig(filePath string, finderName string, finderConfig CloneFinderConfig) (*AnalysisResult, error) {
-    return f.AnalyzeDocument(filePath, finderName, finderConfig)
+	return f.AnalyzeDocument(filePath, finderName, finderConfig)
 }
 
 // GenerateReport generates a report from analysis results
 func (f *Framework) GenerateReport(result *AnalysisResult, format string, outputPath string) error {
+	return f.GenerateReportWithParams(result, format, outputPath, nil)
+}
+
+// GenerateReportWithParams generates a report passing format-specific
+// parameters to the generator as ReportConfig.CustomParams (e.g. "template_dir"
+// for HTML). A "title" parameter overrides the default report title.
+func (f *Framework) GenerateReportWithParams(result *AnalysisResult, format string, outputPath string, params map[string]interface{}) error {
 	generator, err := f.registry.GetReportGenerator(format)
 	if err != nil {
 		return fmt.Errorf("failed to get report generator: %v", err)
 	}
 
+	reportConfig := newReportConfig(result, outputPath, params)
+	return generator.Generate(result.Groups, reportConfig, outputPath)
+}
+
+// newReportConfig builds the ReportConfig handed to report generators.
+func newReportConfig(result *AnalysisResult, outputPath string, params map[string]interface{}) ReportConfig {
 	// Make a shallow copy of metadata so report generators can enrich settings
 	// without mutating the analysis result.
 	settings := map[string]interface{}{}
 	for k, v := range result.Metadata {
 		settings[k] = v
 	}
-	// Expose statistics for generators (e.g. JSON payload "stats").
-	settings["stats"] = result.Statistics
+	// Expose statistics and finder configuration for generators (e.g. the
+	// JSON result document).
+	settings[StatsSettingsKey] = result.Statistics
+	settings[ConfigSettingsKey] = result.Config
 
+	sourceFile, _ := result.Metadata["source_file"].(string)
 	reportConfig := ReportConfig{
-		Title:      "Clone Analysis Report",
-		SourceFile: result.Metadata["source_file"].(string),
-		Settings:   settings,
-		OutputDir:  filepath.Dir(outputPath),
+		Title:        "Clone Analysis Report",
+		SourceFile:   sourceFile,
+		Settings:     settings,
+		OutputDir:    filepath.Dir(outputPath),
+		CustomParams: params,
 	}
-
-	return generator.Generate(result.Groups, reportConfig, outputPath)
+	if title, ok := params["title"].(string); ok && title != "" {
+		reportConfig.Title = title
+	}
+	return reportConfig
 }
 
 // readDocument reads and parses a document using appropriate parser/converter
 func (f *Framework) readDocument(filePath string) (string, error) {
 	ext := filepath.Ext(filePath)
 
-	// Try to get parser for this format
-	parser, err := f.registry.GetDocumentParser(ext)
-	if err == nil {
-		// Parser found, use it
+	// A registered parser reads the file directly
+	if _, err := f.registry.GetDocumentParser(ext); err == nil {
 		file, err := os.Open(filePath)
 		if err != nil {
 			return "", err
 		}
 		defer file.Close()
-
-		segments, err := parser.Parse(file)
-		if err != nil {
-			return "", err
-		}
-
-		// Join segments
-		content := ""
-		for i, seg := range segments {
-			if i > 0 {
-				content += "\n"
-			}
-			content += seg
-		}
-		return content, nil
+		return f.parseDocument(file, ext)
 	}
 
 	// No parser found, check if conversion is needed
@@ -250,75 +397,168 @@ func (f *Framework) readDocument(filePath string) (string, error) {
 		defer os.Remove(tempPath)
 
 		// Try parsing the converted file
-		parser, err := f.registry.GetDocumentParser(".xml")
-		if err == nil {
+		if _, err := f.registry.GetDocumentParser(".xml"); err == nil {
 			file, err := os.Open(tempPath)
 			if err != nil {
 				return "", err
 			}
 			defer file.Close()
+			return f.parseDocument(file, ".xml")
+		}
+	}
+
+	// Fallback: read as plain text
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// parseDocument extracts the text of a document in the format given by its
+// extension from r. Without a parser for ext, the document is converted to
+// DocBook in memory if the "pandoc" converter is a StreamConverter that needs
+// to convert ext, and read as plain text otherwise.
+func (f *Framework) parseDocument(r io.Reader, ext string) (string, error) {
+	if parser, err := f.registry.GetDocumentParser(ext); err == nil {
+		segments, err := parser.Parse(r)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(segments, "\n"), nil
+	}
 
-			segments, err := parser.Parse(file)
+	converter, err := f.registry.GetDocumentConverter("pandoc")
+	if sc, ok := converter.(StreamConverter); err == nil && ok && ext != "" && converter.IsConversionNeeded("document"+ext) {
+		if parser, err := f.registry.GetDocumentParser(".xml"); err == nil {
+			converted, err := sc.ConvertReader(r, ext, ".xml")
 			if err != nil {
-				return "", err
+				return "", fmt.Errorf("conversion failed: %v", err)
 			}
-
-			content := ""
-			for i, seg := range segments {
-				if i > 0 {
-					content += "\n"
-				}
-				content += seg
+			segments, err := parser.Parse(bytes.NewReader(converted))
+			if err != nil {
+				return "", err
 			}
-			return content, nil
+			return strings.Join(segments, "\n"), nil
 		}
 	}
 
-	// Fallback: read as plain text
-	content, err := os.ReadFile(filePath)
+	content, err := io.ReadAll(r)
 	if err != nil {
 		return "", err
 	}
-
 	return string(content), nil
 }
 
 // calculateStatistics computes statistics from clone groups
 func (f *Framework) calculateStatistics(groups []CloneGroup) AnalysisStatistics {
-	stats := AnalysisStatistics{
-		TotalGroups:    len(groups),
-		TotalFragments: 0,
-		MinTokens:      -1,
+	acc := f.newStatsAccumulator()
+	for _, group := range groups {
+		acc.add(group)
 	}
+	return acc.statistics()
+}
 
-	totalTokens := 0
-	tokenCount := 0
+// statsAccumulator computes AnalysisStatistics one group at a time. Covered
+// token ranges are merged as they accumulate, so memory grows with the number
+// of disjoint duplicated regions rather than with the number of fragments.
+type statsAccumulator struct {
+	tokenize    func(string) []string
+	stats       AnalysisStatistics
+	totalTokens int
+	tokenCount  int
+	covered     []tokenRange
+	merged      int // Length of covered after the last merge
+}
 
-	for _, group := range groups {
-		stats.TotalFragments += len(group.Fragments)
+func (f *Framework) newStatsAccumulator() *statsAccumulator {
+	return statsAccumulatorWith(f.tokenize)
+}
 
-		for _, frag := range group.Fragments {
-			// Simple token count (split by spaces)
-			tokens := len(f.tokenize(frag.Content))
-			totalTokens += tokens
-			tokenCount++
+// statsAccumulatorWith returns an accumulator counting fragment tokens with
+// tokenize.
+func statsAccumulatorWith(tokenize func(string) []string) *statsAccumulator {
+	return &statsAccumulator{tokenize: tokenize, stats: AnalysisStatistics{MinTokens: -1}}
+}
 
-			if stats.MinTokens == -1 || tokens < stats.MinTokens {
-				stats.MinTokens = tokens
-			}
-			if tokens > stats.MaxTokens {
-				stats.MaxTokens = tokens
-			}
+func (a *statsAccumulator) add(group CloneGroup) {
+	stats := &a.stats
+	stats.TotalGroups++
+	stats.TotalFragments += len(group.Fragments)
+	if IsSuppressed(group) {
+		stats.SuppressedGroups++
+	} else {
+		if group.Power > stats.MaxGroupPower {
+			stats.MaxGroupPower = group.Power
+		}
+		for _, frag := range group.Fragments {
+			a.covered = append(a.covered, tokenRange{frag.StartPos, frag.EndPos})
+		}
+		if len(a.covered) > 2*a.merged+1024 {
+			a.covered = mergeTokenRanges(a.covered)
+			a.merged = len(a.covered)
 		}
 	}
 
-	if tokenCount > 0 {
-		stats.AvgTokens = float64(totalTokens) / float64(tokenCount)
+	for _, frag := range group.Fragments {
+		// Simple token count (split by spaces)
+		tokens := len(a.tokenize(frag.Content))
+		a.totalTokens += tokens
+		a.tokenCount++
+
+		if stats.MinTokens == -1 || tokens < stats.MinTokens {
+			stats.MinTokens = tokens
+		}
+		if tokens > stats.MaxTokens {
+			stats.MaxTokens = tokens
+		}
 	}
+}
 
+func (a *statsAccumulator) statistics() AnalysisStatistics {
+	stats := a.stats
+	if a.tokenCount > 0 {
+		stats.AvgTokens = float64(a.totalTokens) / float64(a.tokenCount)
+	}
+	stats.DuplicatedTokens = coveredTokens(a.covered)
 	return stats
 }
 
+// tokenRange is a half-open [start, end) range of token positions.
+type tokenRange struct {
+	start, end int
+}
+
+// coveredTokens returns the number of token positions covered by at least one range.
+func coveredTokens(ranges []tokenRange) int {
+	total := 0
+	for _, r := range mergeTokenRanges(ranges) {
+		total += r.end - r.start
+	}
+	return total
+}
+
+// mergeTokenRanges sorts ranges in place and returns them merged into
+// disjoint, non-empty ranges.
+func mergeTokenRanges(ranges []tokenRange) []tokenRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	out := ranges[:0]
+	for _, r := range ranges {
+		if r.end <= r.start {
+			continue
+		}
+		if n := len(out); n > 0 && r.start <= out[n-1].end {
+			if r.end > out[n-1].end {
+				out[n-1].end = r.end
+			}
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
 // tokenize splits text into tokens (simple implementation)
 func (f *Framework) tokenize(text string) []string {
 	// Use default tokenizer if available
@@ -356,15 +596,15 @@ func ReadDocument(f *Framework, filePath string) (io.Reader, error) {
 }
 
 func normalizeReformattedContent(content string) string {
-    // Normalize line endings
-    content = strings.ReplaceAll(content, "\r\n", "\n")
-    content = strings.ReplaceAll(content, "\r", "\n")
+	// Normalize line endings
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
 
-    // Unify tabs
-    content = strings.ReplaceAll(content, "\t", "    ")
+	// Unify tabs
+	content = strings.ReplaceAll(content, "\t", "    ")
 
-    // Make sure string is valid UTF-8 (does not “detect encoding”, but cleans invalid bytes)
-    content = strings.ToValidUTF8(content, "")
+	// Make sure string is valid UTF-8 (does not “detect encoding”, but cleans invalid bytes)
+	content = strings.ToValidUTF8(content, "")
 
-    return content
-}
\ No newline at end of file
+	return content
+}
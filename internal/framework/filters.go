@@ -0,0 +1,224 @@
+package framework
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ParameterizedFilter is implemented by filters that declare the
+// FilterConfig.CustomParams they accept; they are validated like finder
+// parameters before the filter runs
+type ParameterizedFilter interface {
+	Filter
+
+	// Params returns the accepted parameters
+	Params() []ParamSpec
+}
+
+// FilterConfigValidator is implemented by filters that check their
+// configuration beyond the declared parameter types, e.g. pattern syntax
+type FilterConfigValidator interface {
+	// ValidateConfig reports a configuration the filter cannot run with
+	ValidateConfig(config FilterConfig) error
+}
+
+// ValidateFilter checks config against the declaration of filter.
+func ValidateFilter(filter Filter, config FilterConfig) error {
+	if p, ok := filter.(ParameterizedFilter); ok {
+		if err := ValidateParams(p.Params(), config.CustomParams); err != nil {
+			return err
+		}
+	}
+	if v, ok := filter.(FilterConfigValidator); ok {
+		return v.ValidateConfig(config)
+	}
+	return nil
+}
+
+// applyFilters runs the filters of Config.Filters in order, each on the
+// output of the previous one.
+func (f *Framework) applyFilters(groups []CloneGroup) ([]CloneGroup, error) {
+	for i, step := range f.config.Filters {
+		filter, err := f.registry.GetFilter(step.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get filter: %v", err)
+		}
+		if err := ValidateFilter(filter, step.Config); err != nil {
+			return nil, fmt.Errorf("filter %d (%s): %v", i+1, step.Name, err)
+		}
+		groups = filter.Filter(groups, step.Config)
+	}
+	return groups, nil
+}
+
+// LengthFilter keeps groups whose longest fragment has between "min_tokens"
+// and "max_tokens" tokens.
+type LengthFilter struct{}
+
+func (l *LengthFilter) Name() string {
+	return "length"
+}
+
+func (l *LengthFilter) Params() []ParamSpec {
+	return []ParamSpec{
+		{Name: "min_tokens", Type: ParamInt, Default: 0, Min: Bound(0), Description: "minimum length in tokens (0 = no minimum)"},
+		{Name: "max_tokens", Type: ParamInt, Default: 0, Min: Bound(0), Description: "maximum length in tokens (0 = no maximum)"},
+	}
+}
+
+func (l *LengthFilter) Filter(groups []CloneGroup, config FilterConfig) []CloneGroup {
+	minTokens := intParam(config.CustomParams, "min_tokens")
+	maxTokens := intParam(config.CustomParams, "max_tokens")
+	return keepGroups(groups, func(g CloneGroup) bool {
+		n := 0
+		for _, frag := range g.Fragments {
+			n = max(n, len(strings.Fields(frag.Content)))
+		}
+		return n >= minTokens && (maxTokens == 0 || n <= maxTokens)
+	})
+}
+
+// PowerFilter keeps groups with between "min_power" and "max_power"
+// fragments.
+type PowerFilter struct{}
+
+func (p *PowerFilter) Name() string {
+	return "power"
+}
+
+func (p *PowerFilter) Params() []ParamSpec {
+	return []ParamSpec{
+		{Name: "min_power", Type: ParamInt, Default: 0, Min: Bound(0), Description: "minimum number of fragments (0 = no minimum)"},
+		{Name: "max_power", Type: ParamInt, Default: 0, Min: Bound(0), Description: "maximum number of fragments (0 = no maximum)"},
+	}
+}
+
+func (p *PowerFilter) Filter(groups []CloneGroup, config FilterConfig) []CloneGroup {
+	minPower := intParam(config.CustomParams, "min_power")
+	maxPower := intParam(config.CustomParams, "max_power")
+	return keepGroups(groups, func(g CloneGroup) bool {
+		n := len(g.Fragments)
+		return n >= minPower && (maxPower == 0 || n <= maxPower)
+	})
+}
+
+// ExcludeFilter drops groups whose archetype or any fragment matches the
+// regular expression "pattern", e.g. boilerplate such as legal notices.
+type ExcludeFilter struct{}
+
+func (e *ExcludeFilter) Name() string {
+	return "exclude"
+}
+
+func (e *ExcludeFilter) Params() []ParamSpec {
+	return []ParamSpec{
+		{Name: "pattern", Type: ParamString, Default: "", Description: "regular expression (RE2 syntax) of excluded text; required"},
+	}
+}
+
+func (e *ExcludeFilter) ValidateConfig(config FilterConfig) error {
+	pattern, _ := config.CustomParams["pattern"].(string)
+	if pattern == "" {
+		return fmt.Errorf("missing parameter \"pattern\"")
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("parameter \"pattern\": %v", err)
+	}
+	return nil
+}
+
+func (e *ExcludeFilter) Filter(groups []CloneGroup, config FilterConfig) []CloneGroup {
+	pattern, _ := config.CustomParams["pattern"].(string)
+	re, err := regexp.Compile(pattern)
+	if pattern == "" || err != nil {
+		return groups
+	}
+	return keepGroups(groups, func(g CloneGroup) bool {
+		if g.Archetype != "" && re.MatchString(g.Archetype) {
+			return false
+		}
+		for _, frag := range g.Fragments {
+			if re.MatchString(frag.Content) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// ContainmentFilter drops groups contained in another group: every fragment
+// lies within a fragment of the other group, by token positions and text.
+// Of two equal groups the first is kept. Finders reporting both a long clone
+// and its sub-clones are reduced to the long one.
+type ContainmentFilter struct{}
+
+func (c *ContainmentFilter) Name() string {
+	return "containment"
+}
+
+func (c *ContainmentFilter) Params() []ParamSpec {
+	return []ParamSpec{}
+}
+
+func (c *ContainmentFilter) Filter(groups []CloneGroup, config FilterConfig) []CloneGroup {
+	pruned := make([]bool, len(groups))
+	for i := range groups {
+		for j := range groups {
+			if i == j || pruned[j] || !groupContains(groups[j], groups[i]) {
+				continue
+			}
+			// Equal groups contain each other; keep the first.
+			if j > i && groupContains(groups[i], groups[j]) {
+				continue
+			}
+			pruned[i] = true
+			break
+		}
+	}
+	filtered := make([]CloneGroup, 0, len(groups))
+	for i, g := range groups {
+		if !pruned[i] {
+			filtered = append(filtered, g)
+		}
+	}
+	return filtered
+}
+
+// groupContains reports whether every fragment of inner lies within a
+// fragment of outer.
+func groupContains(outer, inner CloneGroup) bool {
+	if len(inner.Fragments) == 0 {
+		return false
+	}
+	for _, in := range inner.Fragments {
+		contained := false
+		for _, out := range outer.Fragments {
+			if out.StartPos <= in.StartPos && in.EndPos <= out.EndPos && strings.Contains(out.Content, in.Content) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
+
+func keepGroups(groups []CloneGroup, keep func(CloneGroup) bool) []CloneGroup {
+	filtered := make([]CloneGroup, 0, len(groups))
+	for _, g := range groups {
+		if keep(g) {
+			filtered = append(filtered, g)
+		}
+	}
+	return filtered
+}
+
+// intParam reads an int parameter validated by ValidateParams; absent
+// parameters are 0.
+func intParam(params map[string]interface{}, key string) int {
+	n, _ := toFloat(params[key])
+	return int(n)
+}
@@ -0,0 +1,80 @@
+package framework
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GroupIDMetadataKey is the CloneGroup metadata key holding the stable group identifier.
+const GroupIDMetadataKey = "group_id"
+
+// GroupID returns a stable identifier for a clone group.
+// The identifier is derived from the normalised archetype (lower-cased,
+// whitespace-collapsed) only, so the same duplicated text gets the same ID
+// across runs, even when token positions shift after unrelated edits or when
+// one occurrence of a fuzzy group is edited. Groups sharing an archetype are
+// told apart by assignGroupIDs and CompareResults.
+func GroupID(g CloneGroup) string {
+	text := g.Archetype
+	if strings.TrimSpace(text) == "" && len(g.Fragments) > 0 {
+		text = g.Fragments[0].Content
+	}
+	sum := sha256.Sum256([]byte(NormalizeArchetype(text)))
+	return "g" + hex.EncodeToString(sum[:6])
+}
+
+// NormalizeArchetype lower-cases text and collapses all whitespace runs to a
+// single space. It is the canonical form used for group identity and pattern
+// matching.
+func NormalizeArchetype(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// assignGroupIDs stores the stable group ID in each group's metadata.
+// IDs already set by a finder are kept unless they repeat.
+func assignGroupIDs(groups []CloneGroup) {
+	ids := groupIDs{}
+	for i := range groups {
+		ids.assign(&groups[i])
+	}
+}
+
+// groupIDs keeps the IDs of one result unique: a repeated ID, i.e. two groups
+// with the same archetype, gets a "-2", "-3", ... suffix in group order, so
+// the groups are not merged when results are compared.
+type groupIDs map[string]int
+
+func (ids groupIDs) unique(id string) string {
+	ids[id]++
+	if n := ids[id]; n > 1 {
+		return fmt.Sprintf("%s-%d", id, n)
+	}
+	return id
+}
+
+func (ids groupIDs) assign(g *CloneGroup) {
+	id := ids.unique(groupIDOf(*g))
+	if g.Metadata == nil {
+		g.Metadata = map[string]interface{}{}
+	}
+	g.Metadata[GroupIDMetadataKey] = id
+}
+
+// groupIDOf returns the group ID stored in metadata, computing it if absent.
+func groupIDOf(g CloneGroup) string {
+	if id, ok := g.Metadata[GroupIDMetadataKey].(string); ok && id != "" {
+		return id
+	}
+	return GroupID(g)
+}
+
+// baseGroupID strips the "-N" suffix added by groupIDs.
+func baseGroupID(id string) string {
+	if i := strings.LastIndexByte(id, '-'); i > 0 && i < len(id)-1 &&
+		strings.Trim(id[i+1:], "0123456789") == "" {
+		return id[:i]
+	}
+	return id
+}
@@ -17,6 +17,16 @@ type CloneFinder interface {
 	Description() string
 }
 
+// StreamingCloneFinder is implemented by clone finders that can hand groups
+// over as they are found instead of returning them all at once
+type StreamingCloneFinder interface {
+	CloneFinder
+
+	// FindClonesStream calls emit for every group found. An error returned by
+	// emit stops the search and is returned unchanged
+	FindClonesStream(text string, config CloneFinderConfig, emit func(CloneGroup) error) error
+}
+
 // SimilarityCalculator defines the interface for similarity calculation algorithms
 type SimilarityCalculator interface {
 	// CalculateSimilarity computes similarity score between two text fragments
@@ -58,6 +68,17 @@ type DocumentConverter interface {
 	Name() string
 }
 
+// StreamConverter is implemented by document converters that can convert a
+// document held in memory, without reading or writing files
+type StreamConverter interface {
+	DocumentConverter
+
+	// ConvertReader converts the document read from input, whose format is
+	// given by the file extension inputFormat, and returns the converted
+	// document in outputFormat
+	ConvertReader(input io.Reader, inputFormat string, outputFormat string) ([]byte, error)
+}
+
 // ReportGenerator defines the interface for generating analysis reports
 type ReportGenerator interface {
 	// Generate creates a report from clone groups
@@ -70,6 +91,26 @@ type ReportGenerator interface {
 	Name() string
 }
 
+// StreamingReportGenerator is implemented by report generators that can write
+// groups one at a time, so the generator never has to hold the whole result
+type StreamingReportGenerator interface {
+	ReportGenerator
+
+	// OpenStream starts a report; config.Settings holds the result metadata
+	// known before the first group (source file, finder, configuration)
+	OpenStream(config ReportConfig, outputPath string) (GroupStreamWriter, error)
+}
+
+// GroupStreamWriter receives the groups of a streamed report
+type GroupStreamWriter interface {
+	// WriteGroup appends one clone group to the report
+	WriteGroup(group CloneGroup) error
+
+	// Close completes the report with the final statistics and metadata and
+	// releases the output
+	Close(summary StreamSummary) error
+}
+
 // TextTokenizer defines the interface for tokenizing text
 type TextTokenizer interface {
 	// Tokenize splits text into tokens
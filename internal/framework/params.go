@@ -0,0 +1,188 @@
+package framework
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ParamType is the type of a finder parameter value
+type ParamType string
+
+// Finder parameter types
+const (
+	ParamBool   ParamType = "bool"
+	ParamInt    ParamType = "int"
+	ParamFloat  ParamType = "float"
+	ParamString ParamType = "string"
+)
+
+// ParamSpec declares one CloneFinderConfig.CustomParams entry of a finder
+type ParamSpec struct {
+	Name        string      `json:"name"`
+	Type        ParamType   `json:"type"`
+	Default     interface{} `json:"default"`
+	Min         *float64    `json:"min,omitempty"` // Inclusive bounds of int and float values; nil = unbounded
+	Max         *float64    `json:"max,omitempty"`
+	Description string      `json:"description"`
+}
+
+// Bound returns a pointer to v for ParamSpec.Min and Max
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// ParameterizedCloneFinder is implemented by clone finders that declare the
+// CustomParams they accept. The framework validates CustomParams against the
+// declaration before the finder runs; finders without one get them unchecked
+type ParameterizedCloneFinder interface {
+	CloneFinder
+
+	// Params returns the accepted parameters
+	Params() []ParamSpec
+}
+
+// ValidateParams checks params against specs: every key must be declared and
+// every value must have the declared type and lie within the declared range.
+// All problems are reported in one error. Integral float64 values (as decoded
+// from JSON) are accepted for int parameters.
+func ValidateParams(specs []ParamSpec, params map[string]interface{}) error {
+	byName := make(map[string]ParamSpec, len(specs))
+	for _, s := range specs {
+		byName[s.Name] = s
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		spec, ok := byName[key]
+		if !ok {
+			msg := fmt.Sprintf("unknown parameter %q", key)
+			if s := closestParam(specs, key); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			problems = append(problems, msg)
+			continue
+		}
+		if err := spec.check(params[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("parameter %q: %v", key, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (s ParamSpec) check(value interface{}) error {
+	var number float64
+	switch s.Type {
+	case ParamBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("want bool, got %s", describeValue(value))
+		}
+		return nil
+	case ParamString:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("want string, got %s", describeValue(value))
+		}
+		return nil
+	case ParamInt:
+		n, ok := toFloat(value)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("want int, got %s", describeValue(value))
+		}
+		number = n
+	case ParamFloat:
+		n, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("want float, got %s", describeValue(value))
+		}
+		number = n
+	default:
+		return fmt.Errorf("unsupported parameter type %q", s.Type)
+	}
+	if s.Min != nil && number < *s.Min {
+		return fmt.Errorf("%v is below the minimum %v", number, *s.Min)
+	}
+	if s.Max != nil && number > *s.Max {
+		return fmt.Errorf("%v is above the maximum %v", number, *s.Max)
+	}
+	return nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func describeValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("string %q", s)
+	}
+	return fmt.Sprintf("%T %v", value, value)
+}
+
+// closestParam returns the declared name within two edits of key, if any.
+func closestParam(specs []ParamSpec, key string) string {
+	best, bestDist := "", 3
+	for _, s := range specs {
+		if d := editDistance(strings.ToLower(key), s.Name); d < bestDist {
+			best, bestDist = s.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b in runes.
+func editDistance(a, b string) int {
+	return levenshtein([]rune(a), []rune(b))
+}
+
+// FinderParams returns the parameter declaration of the named finder; ok is
+// false when the finder does not declare its parameters.
+func (f *Framework) FinderParams(finderName string) (specs []ParamSpec, ok bool, err error) {
+	finder, err := f.registry.GetCloneFinder(finderName)
+	if err != nil {
+		return nil, false, err
+	}
+	if p, ok := finder.(ParameterizedCloneFinder); ok {
+		return p.Params(), true, nil
+	}
+	return nil, false, nil
+}
+
+// resolveFinder returns the named finder after validating config.CustomParams
+// against its declaration, and resolves config.Similarity.
+func (f *Framework) resolveFinder(finderName string, config *CloneFinderConfig) (CloneFinder, error) {
+	finder, err := f.registry.GetCloneFinder(finderName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clone finder: %v", err)
+	}
+	if p, ok := finder.(ParameterizedCloneFinder); ok {
+		if err := ValidateParams(p.Params(), config.CustomParams); err != nil {
+			return nil, fmt.Errorf("finder %s: %v", finderName, err)
+		}
+	}
+	if err := f.resolveSimilarity(config); err != nil {
+		return nil, err
+	}
+	return finder, nil
+}
@@ -0,0 +1,137 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Policy rule identifiers used in PolicyCheck.Rule.
+const (
+	RuleMaxGroups               = "max_groups"
+	RuleMaxDuplicatedTokenRatio = "max_duplicated_token_ratio"
+	RuleMaxGroupPower           = "max_group_power"
+	RuleNoNewGroups             = "no_new_groups"
+)
+
+// Policy defines duplication thresholds for quality gates. A nil threshold
+// disables its rule; zero is a real limit, so MaxGroups of 0 allows no
+// duplication at all. Groups accepted by a baseline are not counted.
+type Policy struct {
+	MaxGroups               *int     `json:"max_groups,omitempty"`                 // Maximum number of clone groups
+	MaxDuplicatedTokenRatio *float64 `json:"max_duplicated_token_ratio,omitempty"` // Maximum share of duplicated tokens (0.0-1.0)
+	MaxGroupPower           *int     `json:"max_group_power,omitempty"`            // Maximum fragments in a single group
+	NoNewGroups             bool     `json:"no_new_groups,omitempty"`              // Fail on groups absent from the previous result
+}
+
+// PolicyCheck is the outcome of one policy rule.
+type PolicyCheck struct {
+	Rule    string `json:"rule"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// PolicyVerdict is the outcome of evaluating a policy against a result.
+type PolicyVerdict struct {
+	Passed bool          `json:"passed"`
+	Checks []PolicyCheck `json:"checks"`
+}
+
+// Violations returns the failed checks.
+func (v PolicyVerdict) Violations() []PolicyCheck {
+	var failed []PolicyCheck
+	for _, c := range v.Checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// LoadPolicy reads a JSON policy file. Unknown keys are rejected so that a
+// misspelt rule does not silently disable a gate.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	var p Policy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Validate checks that all thresholds are in range.
+func (p *Policy) Validate() error {
+	if p.MaxGroups != nil && *p.MaxGroups < 0 {
+		return fmt.Errorf("max_groups must not be negative")
+	}
+	if r := p.MaxDuplicatedTokenRatio; r != nil && (*r < 0 || *r > 1) {
+		return fmt.Errorf("max_duplicated_token_ratio must be within 0.0-1.0")
+	}
+	if p.MaxGroupPower != nil && *p.MaxGroupPower < 0 {
+		return fmt.Errorf("max_group_power must not be negative")
+	}
+	return nil
+}
+
+// Evaluate checks the result against every enabled rule. previous is the
+// result the no_new_groups rule compares against; it may be nil, in which
+// case every group counts as new.
+func (p *Policy) Evaluate(result *AnalysisResult, previous *AnalysisResult) PolicyVerdict {
+	verdict := PolicyVerdict{Passed: true}
+	add := func(c PolicyCheck) {
+		verdict.Checks = append(verdict.Checks, c)
+		if !c.Passed {
+			verdict.Passed = false
+		}
+	}
+
+	stats := result.Statistics
+	active := stats.TotalGroups - stats.SuppressedGroups
+
+	if limit := p.MaxGroups; limit != nil {
+		add(PolicyCheck{
+			Rule:    RuleMaxGroups,
+			Passed:  active <= *limit,
+			Message: fmt.Sprintf("%d clone groups (limit %d)", active, *limit),
+		})
+	}
+	if limit := p.MaxDuplicatedTokenRatio; limit != nil {
+		ratio := stats.DuplicatedTokenRatio()
+		add(PolicyCheck{
+			Rule:    RuleMaxDuplicatedTokenRatio,
+			Passed:  ratio <= *limit,
+			Message: fmt.Sprintf("%.1f%% of tokens duplicated (limit %.1f%%)", ratio*100, *limit*100),
+		})
+	}
+	if limit := p.MaxGroupPower; limit != nil {
+		add(PolicyCheck{
+			Rule:    RuleMaxGroupPower,
+			Passed:  stats.MaxGroupPower <= *limit,
+			Message: fmt.Sprintf("largest group has %d fragments (limit %d)", stats.MaxGroupPower, *limit),
+		})
+	}
+	if p.NoNewGroups {
+		var introduced []string
+		for _, ch := range CompareResults(previous, result).New {
+			if !IsSuppressed(ch.Group) {
+				introduced = append(introduced, ch.ID)
+			}
+		}
+		msg := "no new clone groups"
+		if len(introduced) > 0 {
+			msg = fmt.Sprintf("%d new clone groups: %v", len(introduced), introduced)
+		}
+		add(PolicyCheck{Rule: RuleNoNewGroups, Passed: len(introduced) == 0, Message: msg})
+	}
+
+	return verdict
+}
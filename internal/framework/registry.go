@@ -98,6 +98,19 @@ func (r *PluginRegistry) GetSimilarityCalculator(name string) (SimilarityCalcula
 	return calc, nil
 }
 
+// ListSimilarityCalculators returns the names of all registered similarity
+// calculators.
+func (r *PluginRegistry) ListSimilarityCalculators() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.similarityCalcs))
+	for name := range r.similarityCalcs {
+		names = append(names, name)
+	}
+	return names
+}
+
 // RegisterDocumentParser registers a document parser
 func (r *PluginRegistry) RegisterDocumentParser(parser DocumentParser) error {
 	r.mu.Lock()
@@ -183,6 +196,18 @@ func (r *PluginRegistry) GetReportGenerator(format string) (ReportGenerator, err
 	return nil, fmt.Errorf("no report generator found for format '%s'", format)
 }
 
+// ListReportFormats returns the formats of all registered report generators
+func (r *PluginRegistry) ListReportFormats() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	formats := make([]string, 0, len(r.reportGenerators))
+	for _, generator := range r.reportGenerators {
+		formats = append(formats, generator.Format())
+	}
+	return formats
+}
+
 // RegisterTextTokenizer registers a text tokenizer
 func (r *PluginRegistry) RegisterTextTokenizer(tokenizer TextTokenizer) error {
 	r.mu.Lock()
@@ -237,6 +262,18 @@ func (r *PluginRegistry) GetFilter(name string) (Filter, error) {
 	return filter, nil
 }
 
+// ListFilters returns all registered filters
+func (r *PluginRegistry) ListFilters() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.filters))
+	for name := range r.filters {
+		names = append(names, name)
+	}
+	return names
+}
+
 // RegisterPlugin registers a generic plugin
 func (r *PluginRegistry) RegisterPlugin(plugin Plugin, config map[string]interface{}) error {
 	r.mu.Lock()
@@ -0,0 +1,144 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// ResultSchemaVersion is the version of the JSON result schema written by the
+// "json" report format and read by LoadResult. It changes only when existing
+// fields change meaning or are removed; new optional fields keep the version.
+const ResultSchemaVersion = 1
+
+// Settings keys under which GenerateReport exposes the analysis result to
+// report generators in addition to the result metadata.
+const (
+	StatsSettingsKey  = "stats"  // AnalysisStatistics
+	ConfigSettingsKey = "config" // CloneFinderConfig
+)
+
+// ResultDocument is the versioned JSON representation of an AnalysisResult.
+type ResultDocument struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Title         string                 `json:"title,omitempty"`
+	SourceFile    string                 `json:"source_file,omitempty"`
+	Finder        string                 `json:"finder,omitempty"`
+	TotalTokens   int                    `json:"total_tokens,omitempty"`
+	Config        CloneFinderConfig      `json:"config"`
+	Stats         AnalysisStatistics     `json:"stats"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"` // Result metadata except the fields above
+	Groups        []CloneGroup           `json:"groups"`
+	Heatmap       []int                  `json:"heatmap,omitempty"` // Token coverage bins; informational, ignored by LoadResult
+}
+
+// NewResultDocument builds the JSON document of result.
+func NewResultDocument(result *AnalysisResult) ResultDocument {
+	doc := ResultDocument{
+		SchemaVersion: ResultSchemaVersion,
+		Config:        result.Config,
+		Stats:         result.Statistics,
+		Groups:        result.Groups,
+		Metadata:      map[string]interface{}{},
+	}
+	if doc.Groups == nil {
+		doc.Groups = []CloneGroup{}
+	}
+	for k, v := range result.Metadata {
+		switch k {
+		case "source_file":
+			doc.SourceFile, _ = v.(string)
+		case "finder":
+			doc.Finder, _ = v.(string)
+		case "total_tokens":
+			doc.TotalTokens = intValue(v)
+		case StatsSettingsKey, ConfigSettingsKey:
+			// Already present as typed fields.
+		default:
+			doc.Metadata[k] = v
+		}
+	}
+	if len(doc.Metadata) == 0 {
+		doc.Metadata = nil
+	}
+	return doc
+}
+
+// Result converts the document back into an AnalysisResult. JSON numbers in
+// metadata that hold whole values are restored as int.
+func (d ResultDocument) Result() *AnalysisResult {
+	result := &AnalysisResult{
+		Groups:     d.Groups,
+		Statistics: d.Stats,
+		Config:     d.Config,
+		Metadata:   map[string]interface{}{},
+	}
+	for k, v := range d.Metadata {
+		result.Metadata[k] = v
+	}
+	normalizeJSONNumbers(result.Metadata)
+	for i := range result.Groups {
+		normalizeJSONNumbers(result.Groups[i].Metadata)
+		for j := range result.Groups[i].Fragments {
+			normalizeJSONNumbers(result.Groups[i].Fragments[j].Metadata)
+		}
+	}
+	if stale, ok := result.Metadata["stale_baseline_entries"]; ok {
+		var entries []StaleBaselineEntry
+		if data, err := json.Marshal(stale); err == nil && json.Unmarshal(data, &entries) == nil {
+			result.Metadata["stale_baseline_entries"] = entries
+		}
+	}
+
+	result.Metadata["source_file"] = d.SourceFile
+	if d.Finder != "" {
+		result.Metadata["finder"] = d.Finder
+	}
+	if d.TotalTokens > 0 {
+		result.Metadata["total_tokens"] = d.TotalTokens
+	}
+	return result
+}
+
+// LoadResult reads an analysis result written by the "json" report format.
+func LoadResult(path string) (*AnalysisResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read result: %w", err)
+	}
+	var doc ResultDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse result %s: %w", path, err)
+	}
+	switch {
+	case doc.SchemaVersion == 0:
+		return nil, fmt.Errorf("result %s: missing schema_version, not a docline JSON result", path)
+	case doc.SchemaVersion > ResultSchemaVersion:
+		return nil, fmt.Errorf("result %s: unsupported schema_version %d (newest supported is %d)", path, doc.SchemaVersion, ResultSchemaVersion)
+	}
+	return doc.Result(), nil
+}
+
+// normalizeJSONNumbers turns whole float64 values of m into int, undoing the
+// float64 decoding of JSON numbers for the common integer metadata.
+func normalizeJSONNumbers(m map[string]interface{}) {
+	for k, v := range m {
+		if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			m[k] = int(f)
+		}
+	}
+}
+
+// intValue converts numeric metadata values to int.
+func intValue(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
@@ -0,0 +1,262 @@
+package framework
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultSimilarityCalculator is used when neither the run nor
+// Config.DefaultSimilarityCalc names a calculator.
+const DefaultSimilarityCalculator = "jaccard"
+
+// CorpusSimilarityCalculator is implemented by similarity calculators that
+// weigh tokens by statistics of the whole analysed text, e.g. TF-IDF
+type CorpusSimilarityCalculator interface {
+	SimilarityCalculator
+
+	// ForCorpus returns a calculator fitted to text, leaving the receiver
+	// unchanged so registered calculators can be shared between runs
+	ForCorpus(text string) SimilarityCalculator
+}
+
+// SimilarityOrDefault returns calc, or the Jaccard calculator when calc is
+// nil, for finders called without a resolved configuration.
+func SimilarityOrDefault(calc SimilarityCalculator) SimilarityCalculator {
+	if calc == nil {
+		return &JaccardSimilarityCalculator{}
+	}
+	return calc
+}
+
+// resolveSimilarity sets config.Similarity to the calculator named by
+// config.SimilarityCalc, Config.DefaultSimilarityCalc or
+// DefaultSimilarityCalculator, the first one set, and records its name in
+// config.SimilarityCalc. A calculator already set in config.Similarity is kept.
+func (f *Framework) resolveSimilarity(config *CloneFinderConfig) error {
+	if config.Similarity != nil {
+		config.SimilarityCalc = config.Similarity.Name()
+		return nil
+	}
+	name := config.SimilarityCalc
+	if name == "" {
+		name = f.config.DefaultSimilarityCalc
+	}
+	if name == "" {
+		name = DefaultSimilarityCalculator
+	}
+	calc, err := f.registry.GetSimilarityCalculator(name)
+	if err != nil {
+		return fmt.Errorf("failed to get similarity calculator: %v", err)
+	}
+	config.SimilarityCalc = name
+	config.Similarity = calc
+	return nil
+}
+
+// fitSimilarity fits a corpus-aware calculator to the analysed text.
+func fitSimilarity(content string, config *CloneFinderConfig) {
+	if c, ok := config.Similarity.(CorpusSimilarityCalculator); ok {
+		config.Similarity = c.ForCorpus(content)
+	}
+}
+
+// JaccardSimilarityCalculator scores two texts by the Jaccard index of their
+// token sets: shared tokens divided by all distinct tokens.
+type JaccardSimilarityCalculator struct{}
+
+func (j *JaccardSimilarityCalculator) Name() string {
+	return "jaccard"
+}
+
+func (j *JaccardSimilarityCalculator) CalculateSimilarity(text1, text2 string) float64 {
+	set1, set2 := tokenSet(text1), tokenSet(text2)
+	shared := sharedTokens(set1, set2)
+	union := len(set1) + len(set2) - shared
+	if union == 0 {
+		return 0.0
+	}
+	return float64(shared) / float64(union)
+}
+
+// DiceSimilarityCalculator scores two texts by the Sørensen-Dice coefficient
+// of their token sets, which weighs shared tokens more than Jaccard.
+type DiceSimilarityCalculator struct{}
+
+func (d *DiceSimilarityCalculator) Name() string {
+	return "dice"
+}
+
+func (d *DiceSimilarityCalculator) CalculateSimilarity(text1, text2 string) float64 {
+	set1, set2 := tokenSet(text1), tokenSet(text2)
+	if len(set1)+len(set2) == 0 {
+		return 0.0
+	}
+	return 2 * float64(sharedTokens(set1, set2)) / float64(len(set1)+len(set2))
+}
+
+// LevenshteinSimilarityCalculator scores two texts by their token edit
+// distance normalised by the longer text: 1 - distance/max(len1, len2).
+// Unlike the set measures it takes word order into account.
+type LevenshteinSimilarityCalculator struct{}
+
+func (l *LevenshteinSimilarityCalculator) Name() string {
+	return "levenshtein"
+}
+
+func (l *LevenshteinSimilarityCalculator) CalculateSimilarity(text1, text2 string) float64 {
+	tokens1, tokens2 := strings.Fields(text1), strings.Fields(text2)
+	longest := max(len(tokens1), len(tokens2))
+	if longest == 0 {
+		return 0.0
+	}
+	return 1 - float64(levenshtein(tokens1, tokens2))/float64(longest)
+}
+
+// LCSSimilarityCalculator scores two texts by the length of their longest
+// common token subsequence relative to both lengths: 2*lcs/(len1+len2).
+type LCSSimilarityCalculator struct{}
+
+func (l *LCSSimilarityCalculator) Name() string {
+	return "lcs"
+}
+
+func (l *LCSSimilarityCalculator) CalculateSimilarity(text1, text2 string) float64 {
+	tokens1, tokens2 := strings.Fields(text1), strings.Fields(text2)
+	if len(tokens1)+len(tokens2) == 0 {
+		return 0.0
+	}
+	prev := make([]int, len(tokens2)+1)
+	cur := make([]int, len(tokens2)+1)
+	for i := 1; i <= len(tokens1); i++ {
+		for j := 1; j <= len(tokens2); j++ {
+			if tokens1[i-1] == tokens2[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return 2 * float64(prev[len(tokens2)]) / float64(len(tokens1)+len(tokens2))
+}
+
+// CosineSimilarityCalculator scores two texts by the cosine of their TF-IDF
+// vectors, so tokens frequent across the document count less than rare ones.
+// Document frequencies come from the sentences of the analysed text (see
+// ForCorpus); unfitted, the two compared texts are the corpus. Tokens are
+// compared case-insensitively, so a corpus fitted before a finder lowercases
+// its input still applies.
+type CosineSimilarityCalculator struct {
+	docFreq map[string]int
+	docs    int
+}
+
+func (c *CosineSimilarityCalculator) Name() string {
+	return "cosine"
+}
+
+// ForCorpus returns a calculator whose document frequencies are counted over
+// the sentences and lines of text.
+func (c *CosineSimilarityCalculator) ForCorpus(text string) SimilarityCalculator {
+	fitted := &CosineSimilarityCalculator{docFreq: map[string]int{}}
+	for _, doc := range strings.FieldsFunc(text, isSentenceBreak) {
+		tokens := tokenSet(strings.ToLower(doc))
+		if len(tokens) == 0 {
+			continue
+		}
+		fitted.docs++
+		for t := range tokens {
+			fitted.docFreq[t]++
+		}
+	}
+	return fitted
+}
+
+func (c *CosineSimilarityCalculator) CalculateSimilarity(text1, text2 string) float64 {
+	tf1 := termFrequencies(text1)
+	tf2 := termFrequencies(text2)
+	if len(tf1) == 0 || len(tf2) == 0 {
+		return 0.0
+	}
+
+	docFreq, docs := c.docFreq, c.docs
+	if docs == 0 {
+		docFreq, docs = map[string]int{}, 2
+		for t := range tf1 {
+			docFreq[t]++
+		}
+		for t := range tf2 {
+			docFreq[t]++
+		}
+	}
+	// Smoothed IDF: never zero, so tokens in every document still count
+	idf := func(t string) float64 {
+		return math.Log(float64(1+docs)/float64(1+docFreq[t])) + 1
+	}
+
+	dot, norm1, norm2 := 0.0, 0.0, 0.0
+	for t, n := range tf1 {
+		w := float64(n) * idf(t)
+		norm1 += w * w
+		if m, ok := tf2[t]; ok {
+			dot += w * float64(m) * idf(t)
+		}
+	}
+	for t, n := range tf2 {
+		w := float64(n) * idf(t)
+		norm2 += w * w
+	}
+	return min(dot/math.Sqrt(norm1*norm2), 1.0)
+}
+
+func isSentenceBreak(r rune) bool {
+	return r == '\n' || r == '.' || r == '!' || r == '?'
+}
+
+func termFrequencies(text string) map[string]int {
+	tf := map[string]int{}
+	for _, t := range strings.Fields(strings.ToLower(text)) {
+		tf[t]++
+	}
+	return tf
+}
+
+func tokenSet(text string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, t := range strings.Fields(text) {
+		set[t] = struct{}{}
+	}
+	return set
+}
+
+func sharedTokens(set1, set2 map[string]struct{}) int {
+	n := 0
+	for t := range set1 {
+		if _, ok := set2[t]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// levenshtein returns the edit distance between two sequences.
+func levenshtein[T comparable](a, b []T) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
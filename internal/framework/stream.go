@@ -0,0 +1,112 @@
+package framework
+
+import (
+	"fmt"
+	"time"
+)
+
+// AnalyzeDocumentStream analyses a document and writes the report while groups
+// are handed over by the finder. A StreamingReportGenerator receives every
+// group as soon as it is emitted and the groups are not collected for the
+// result; a regular generator receives all groups at the end. A regular
+// finder's groups are handed over one by one after it returns. Of the
+// built-in finders only terminology streams: it emits each term as soon as
+// its last occurrence has been read and keeps only the occurrences of
+// unfinished terms. The other built-in finders compute all their groups
+// before the first one is handed over.
+//
+// Every group goes through the same post-finder stage as in AnalyzeDocument
+// (filters, line numbers, stable IDs, baseline suppression). The returned result holds
+// statistics and metadata only; Groups is nil.
+func (f *Framework) AnalyzeDocumentStream(filePath string, finderName string, finderConfig CloneFinderConfig, format string, outputPath string, params map[string]interface{}) (*AnalysisResult, error) {
+	generator, err := f.registry.GetReportGenerator(format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report generator: %v", err)
+	}
+
+	content, finder, err := f.prepareAnalysis(filePath, finderName, &finderConfig)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := f.activeBaseline()
+	if err != nil {
+		return nil, err
+	}
+	var matcher *baselineMatcher
+	if baseline != nil {
+		matcher = baseline.matcher(time.Now())
+	}
+
+	tokenLines := tokenLineIndex(content, -1)
+	result := &AnalysisResult{
+		Config:   finderConfig,
+		Metadata: resultMetadata(filePath, finderName, len(tokenLines)),
+	}
+
+	var writer GroupStreamWriter
+	if sg, ok := generator.(StreamingReportGenerator); ok {
+		writer, err = sg.OpenStream(newReportConfig(result, outputPath, params), outputPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	acc := f.newStatsAccumulator()
+	ids := groupIDs{}
+	var buffered []CloneGroup
+	emit := func(g CloneGroup) error {
+		annotateGroupLines(tokenLines, &g)
+		ids.assign(&g)
+		if matcher != nil {
+			matcher.apply(&g)
+		}
+		acc.add(g)
+		if writer != nil {
+			return writer.WriteGroup(g)
+		}
+		buffered = append(buffered, g)
+		return nil
+	}
+
+	// Filters see the complete group set, so they disable finder streaming.
+	if sf, ok := finder.(StreamingCloneFinder); ok && len(f.config.Filters) == 0 {
+		err = sf.FindClonesStream(content, finderConfig, emit)
+	} else {
+		var groups []CloneGroup
+		groups, err = finder.FindClones(content, finderConfig)
+		if err == nil {
+			groups, err = f.applyFilters(groups)
+		}
+		for i := 0; err == nil && i < len(groups); i++ {
+			err = emit(groups[i])
+		}
+	}
+	if err != nil {
+		if writer != nil {
+			if cerr := writer.Close(StreamSummary{Metadata: result.Metadata}); cerr != nil {
+				return nil, fmt.Errorf("failed to find clones: %v (closing report: %v)", err, cerr)
+			}
+		}
+		return nil, fmt.Errorf("failed to find clones: %v", err)
+	}
+
+	result.Statistics = acc.statistics()
+	result.Statistics.TotalTokens = len(tokenLines)
+	if matcher != nil {
+		result.Metadata["stale_baseline_entries"] = matcher.stale()
+	}
+
+	if writer != nil {
+		if err := writer.Close(StreamSummary{Statistics: result.Statistics, Metadata: result.Metadata}); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result.Groups = buffered
+	if err := generator.Generate(buffered, newReportConfig(result, outputPath, params), outputPath); err != nil {
+		return nil, err
+	}
+	result.Groups = nil
+	return result, nil
+}
@@ -2,27 +2,43 @@ package framework
 
 // CloneGroup represents a group of similar text fragments
 type CloneGroup struct {
-	Fragments []TextFragment
-	Power     int                    // Number of fragments in the group
-	Archetype string                 // Representative text for the group
-	Metadata  map[string]interface{} // Additional metadata
+	Fragments []TextFragment         `json:"fragments"`
+	Power     int                    `json:"power"`              // Number of fragments in the group
+	Archetype string                 `json:"archetype"`          // Representative text for the group
+	Metadata  map[string]interface{} `json:"metadata,omitempty"` // Additional metadata
 }
 
 // TextFragment represents a single text fragment with position information
 type TextFragment struct {
-	Content  string                 // The actual text content
-	StartPos int                    // Starting position (token index)
-	EndPos   int                    // Ending position (token index)
-	Metadata map[string]interface{} // Additional metadata
+	Content  string                 `json:"content"`            // The actual text content
+	StartPos int                    `json:"start_pos"`          // Starting position (token index)
+	EndPos   int                    `json:"end_pos"`            // Ending position (token index)
+	Metadata map[string]interface{} `json:"metadata,omitempty"` // Additional metadata, e.g. SourceLineStartMetadataKey
 }
 
+// Metadata keys set on text fragments.
+const (
+	// SourceLineStartMetadataKey and SourceLineEndMetadataKey hold the 1-based
+	// lines of the analysed text where the fragment starts and ends
+	SourceLineStartMetadataKey = "source_line_start"
+	SourceLineEndMetadataKey   = "source_line_end"
+	// SourceFileMetadataKey names the file of a fragment when it differs from
+	// the source file of the result
+	SourceFileMetadataKey = "source_file"
+)
+
 // CloneFinderConfig holds configuration for clone finders
 type CloneFinderConfig struct {
-	MinCloneLength      int                    // Minimum clone length in tokens
-	MaxCloneLength      int                    // Maximum clone length in tokens (0 = unlimited)
-	MinGroupPower       int                    // Minimum number of fragments in a group
-	SimilarityThreshold float64                // Minimum similarity score (0.0-1.0)
-	CustomParams        map[string]interface{} // Algorithm-specific parameters
+	MinCloneLength      int                    `json:"min_clone_length"`          // Minimum clone length in tokens
+	MaxCloneLength      int                    `json:"max_clone_length"`          // Maximum clone length in tokens (0 = unlimited)
+	MinGroupPower       int                    `json:"min_group_power"`           // Minimum number of fragments in a group
+	SimilarityThreshold float64                `json:"similarity_threshold"`      // Minimum similarity score (0.0-1.0, 0 = finder default)
+	SimilarityCalc      string                 `json:"similarity_calc,omitempty"` // Registered similarity calculator (empty = Config.DefaultSimilarityCalc)
+	CustomParams        map[string]interface{} `json:"custom_params,omitempty"`   // Algorithm-specific parameters
+
+	// Similarity is the calculator resolved from SimilarityCalc, set by the
+	// framework before the finder runs
+	Similarity SimilarityCalculator `json:"-"`
 }
 
 // ReportConfig holds configuration for report generation
@@ -42,6 +58,12 @@ type FilterConfig struct {
 	CustomParams       map[string]interface{} // Filter-specific parameters
 }
 
+// FilterStep names a registered filter and its configuration.
+type FilterStep struct {
+	Name   string
+	Config FilterConfig
+}
+
 // AnalysisResult represents the complete result of an analysis
 type AnalysisResult struct {
 	Groups     []CloneGroup           // Found clone groups
@@ -50,11 +72,30 @@ type AnalysisResult struct {
 	Config     CloneFinderConfig      // Configuration used
 }
 
+// StreamSummary completes a streamed report once all groups are written
+type StreamSummary struct {
+	Statistics AnalysisStatistics     // Final analysis statistics
+	Metadata   map[string]interface{} // Result metadata, including entries known only at the end
+}
+
 // AnalysisStatistics holds statistical information about the analysis
 type AnalysisStatistics struct {
-	TotalGroups    int     // Total number of clone groups
-	TotalFragments int     // Total number of fragments
-	AvgTokens      float64 // Average tokens per fragment
-	MaxTokens      int     // Maximum tokens in a fragment
-	MinTokens      int     // Minimum tokens in a fragment
+	TotalGroups      int     `json:"total_groups"`      // Total number of clone groups
+	TotalFragments   int     `json:"total_fragments"`   // Total number of fragments
+	AvgTokens        float64 `json:"avg_tokens"`        // Average tokens per fragment
+	MaxTokens        int     `json:"max_tokens"`        // Maximum tokens in a fragment
+	MinTokens        int     `json:"min_tokens"`        // Minimum tokens in a fragment
+	SuppressedGroups int     `json:"suppressed_groups"` // Groups accepted by a baseline (still counted in TotalGroups)
+	MaxGroupPower    int     `json:"max_group_power"`   // Largest power among groups not accepted by a baseline
+	TotalTokens      int     `json:"total_tokens"`      // Tokens in the analysed text
+	DuplicatedTokens int     `json:"duplicated_tokens"` // Tokens covered by fragments of groups not accepted by a baseline
+}
+
+// DuplicatedTokenRatio returns DuplicatedTokens / TotalTokens, or 0 when the
+// total is unknown.
+func (s AnalysisStatistics) DuplicatedTokenRatio() float64 {
+	if s.TotalTokens <= 0 {
+		return 0
+	}
+	return float64(s.DuplicatedTokens) / float64(s.TotalTokens)
 }
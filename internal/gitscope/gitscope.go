@@ -0,0 +1,308 @@
+// Package gitscope restricts analyses to the lines changed in a git revision
+// range, using the local git binary. A clone group is kept when at least one
+// of its fragments overlaps a changed hunk.
+package gitscope
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/locate"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+// Metadata keys set by Analyze.
+const (
+	RangeMetadataKey   = "git_range"     // Result: the revision range
+	ChangedMetadataKey = "changed_lines" // Fragment: true when it overlaps a changed hunk
+)
+
+// LineRange is a 1-based, inclusive range of lines.
+type LineRange struct {
+	Start, End int
+}
+
+// Overlaps reports whether the lines start..end overlap any of ranges.
+func Overlaps(ranges []LineRange, start, end int) bool {
+	for _, r := range ranges {
+		if start <= r.End && end >= r.Start {
+			return true
+		}
+	}
+	return false
+}
+
+// Repo is a git working tree and a revision range in it.
+type Repo struct {
+	Root      string // Top-level directory of the working tree
+	Range     string // Revision range as given, e.g. "main...HEAD" or "HEAD~3"
+	endCommit string // Revision at the end of Range; empty for the working tree
+	staged    bool   // Compare HEAD with the index (see OpenStaged)
+}
+
+// Open resolves the working tree containing dir. revRange is passed to
+// "git diff": "A..B" and "A...B" compare with revision B (HEAD when omitted),
+// a single revision compares with the working tree.
+func Open(dir, revRange string) (*Repo, error) {
+	if strings.TrimSpace(revRange) == "" {
+		return nil, fmt.Errorf("empty git revision range")
+	}
+	if strings.HasPrefix(revRange, "-") {
+		return nil, fmt.Errorf("invalid git revision range %q", revRange)
+	}
+	root, err := toplevel(dir)
+	if err != nil {
+		return nil, err
+	}
+	repo := &Repo{Root: root, Range: revRange}
+	if i := strings.Index(revRange, ".."); i >= 0 {
+		repo.endCommit = strings.TrimLeft(revRange[i+2:], ".")
+		if repo.endCommit == "" {
+			repo.endCommit = "HEAD"
+		}
+	}
+	return repo, nil
+}
+
+// OpenStaged resolves the working tree containing dir for the changes staged
+// for the next commit: ChangedLines compares HEAD with the index and ReadFile
+// returns the staged content.
+func OpenStaged(dir string) (*Repo, error) {
+	root, err := toplevel(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{Root: root, Range: "--cached", staged: true}, nil
+}
+
+// HookPath returns the path of the named hook (e.g. "pre-commit") of the
+// repository containing dir, honouring core.hooksPath.
+func HookPath(dir, name string) (string, error) {
+	out, err := git(dir, "rev-parse", "--git-path", "hooks/"+name)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+func toplevel(dir string) (string, error) {
+	out, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ChangedLines returns the lines added or modified in the range, keyed by
+// path relative to Root. Pure deletions count as a change of the lines
+// around them; deleted files are left out.
+func (r *Repo) ChangedLines() (map[string][]LineRange, error) {
+	out, err := git(r.Root, "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff",
+		"--no-prefix", "--unified=0", r.Range, "--")
+	if err != nil {
+		return nil, err
+	}
+	return parseDiff(out)
+}
+
+// ReadFile returns the content of path (relative to Root) at the end of the
+// range.
+func (r *Repo) ReadFile(path string) ([]byte, error) {
+	if r.staged {
+		return git(r.Root, "show", ":"+path)
+	}
+	if r.endCommit == "" {
+		return os.ReadFile(filepath.Join(r.Root, filepath.FromSlash(path)))
+	}
+	return git(r.Root, "show", r.endCommit+":"+path)
+}
+
+// ReadRevision returns the content of path (relative to Root) at rev.
+func (r *Repo) ReadRevision(rev, path string) ([]byte, error) {
+	return git(r.Root, "show", rev+":"+path)
+}
+
+// BinaryFiles returns the files changed in the range that git diffs as
+// binary, such as .doc, .docx or .odt documents. Their changes have no line
+// hunks, so ChangedLines leaves them out.
+func (r *Repo) BinaryFiles() ([]string, error) {
+	out, err := git(r.Root, "diff", "--numstat", "-z", "--no-renames", "--diff-filter=d", r.Range, "--")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, record := range strings.Split(string(out), "\x00") {
+		// Binary files are counted as "-\t-\t<path>"
+		if path, ok := strings.CutPrefix(record, "-\t-\t"); ok && path != "" {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// parseDiff reads the file headers and hunk headers of a "--no-prefix
+// --unified=0" diff. File headers are only read between a "diff --git" line
+// and the first hunk of the file, so an added line starting with "++" is not
+// taken for one.
+func parseDiff(diff []byte) (map[string][]LineRange, error) {
+	changed := map[string][]LineRange{}
+	var file string
+	inHeader := false
+	sc := bufio.NewScanner(bytes.NewReader(diff))
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file, inHeader = "", true
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			file = strings.TrimSuffix(line[4:], "\t")
+			if file == "/dev/null" {
+				file = ""
+			} else if strings.HasPrefix(file, `"`) {
+				if unquoted, err := strconv.Unquote(file); err == nil {
+					file = unquoted
+				}
+			}
+		case strings.HasPrefix(line, "@@ "):
+			inHeader = false
+			if file == "" {
+				continue
+			}
+			// @@ -a[,b] +c[,d] @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			start, count, err := parseHunkRange(fields[2][1:])
+			if err != nil {
+				return nil, fmt.Errorf("malformed hunk header %q: %v", line, err)
+			}
+			r := LineRange{Start: start, End: start + count - 1}
+			if count == 0 {
+				// Deletion after line start: mark the lines on both sides.
+				r = LineRange{Start: start, End: start + 1}
+				if r.Start < 1 {
+					r.Start = 1
+				}
+			}
+			changed[file] = append(changed[file], r)
+		}
+	}
+	return changed, sc.Err()
+}
+
+func parseHunkRange(s string) (start, count int, err error) {
+	count = 1
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+// ExtractText returns the text docline analyses for source, the content of
+// the file name. Markup documents are parsed with the registered parser;
+// formats that cannot be converted (e.g. without pandoc) fall back to the
+// source as plain text.
+func ExtractText(d *docline.Docline, name string, source []byte) (string, error) {
+	text, err := d.ExtractText(bytes.NewReader(source), filepath.Ext(name))
+	if err != nil {
+		if locate.IsMarkup(name) {
+			return "", err
+		}
+		return string(source), nil
+	}
+	return text, nil
+}
+
+// Analyze analyses source, the content of the file name, and keeps the groups
+// with a fragment on one of the changed lines. Fragment line metadata is set
+// to lines of source, and fragments on changed lines are marked with
+// ChangedMetadataKey. Markup documents are parsed with the registered parser;
+// formats that cannot be converted (e.g. without pandoc) are analysed as
+// plain text.
+func Analyze(d *docline.Docline, name string, source []byte, changed []LineRange, finder string, cfg docline.CloneFinderConfig) (*docline.AnalysisResult, error) {
+	text := string(source)
+	analysed, err := ExtractText(d, name, source)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := d.AnalyzeReaderWithConfig(strings.NewReader(analysed), "", finder, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := locate.TokenSpans(text, analysed, locate.IsMarkup(name))
+	lines := locate.NewLineIndex(text)
+	for _, g := range result.Groups {
+		for _, f := range g.Fragments {
+			sp, ok := locate.FragmentSpan(spans, f.StartPos, f.EndPos)
+			if !ok || f.Metadata == nil {
+				continue
+			}
+			start, end := lines.Line(sp.Start)+1, lines.Line(sp.End)+1
+			f.Metadata[docline.SourceLineStartMetadataKey] = start
+			f.Metadata[docline.SourceLineEndMetadataKey] = end
+			if Overlaps(changed, start, end) {
+				f.Metadata[ChangedMetadataKey] = true
+			}
+		}
+	}
+
+	scoped := d.SelectGroups(result, func(g docline.CloneGroup) bool {
+		for _, f := range g.Fragments {
+			if touched, _ := f.Metadata[ChangedMetadataKey].(bool); touched {
+				return true
+			}
+		}
+		return false
+	})
+	scoped.Metadata["source_file"] = name
+	return scoped, nil
+}
+
+// git runs git in dir and returns its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("git %s: %s", subcommand(args), msg)
+	}
+	return out, nil
+}
+
+// subcommand returns the git command in args, skipping "-c name=value".
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-c" {
+			i++
+			continue
+		}
+		return args[i]
+	}
+	return ""
+}
@@ -0,0 +1,218 @@
+// Package locate maps clone fragments back to positions in the source
+// document. Fragment token positions, and the framework's "source_line_start"
+// and "source_line_end" metadata derived from them, refer to the text the
+// parser produced: the parsers return extracted text without source offsets,
+// drop elements they do not extract (program listings, inline markup) and
+// expand entities, so those lines do not match the source file. The tokens
+// are aligned with the source words instead.
+//
+// The alignment is a heuristic. Words are compared by their letters and
+// digits only, and every analysed token is placed at the occurrence that
+// starts the longest run of matching words, so a word repeated in text the
+// parser dropped is not taken for the analysed one. It can still go wrong
+// where dropped text repeats the analysed text word for word, or where a
+// parser emits text out of document order; tokens that cannot be placed get
+// an empty span.
+package locate
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// alignLookahead bounds how many source words are searched for the next
+// analysed token. Text the parser left out (metadata, program listings)
+// between two analysed tokens is skipped as long as it is shorter.
+const alignLookahead = 2000
+
+// alignRun bounds how many following words are compared to rank the
+// occurrences of a token. A lone matching word beyond the next source word
+// is not trusted, since common words occur everywhere.
+const alignRun = 16
+
+// markupExtensions are parsed with a registered parser and contain "<...>"
+// markup that is skipped when tokens are aligned.
+var markupExtensions = map[string]bool{
+	".xml": true, ".dbk": true, ".docbook": true, ".drl": true, ".html": true, ".htm": true,
+}
+
+// IsMarkup reports whether documents with the file extension of path contain
+// "<...>" markup.
+func IsMarkup(path string) bool {
+	return markupExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// Span is a byte range [Start, End) in the source document.
+type Span struct {
+	Start, End int
+}
+
+// sourceWord is a word of the source document with its normalised form.
+type sourceWord struct {
+	norm string
+	span Span
+}
+
+// TokenSpans maps every token of the analysed text (strings.Fields of
+// analysed) to its position in source, or to an empty span when it cannot be
+// found. Tokens are aligned in document order, see the package comment; with
+// markup set, "<...>" in source is skipped.
+func TokenSpans(source, analysed string, markup bool) []Span {
+	words := sourceWords(source, markup)
+	positions := map[string][]int{}
+	for j, w := range words {
+		positions[w.norm] = append(positions[w.norm], j)
+	}
+
+	fields := strings.Fields(analysed)
+	spans := make([]Span, len(fields))
+	var tokens []string // Normalised tokens with letters or digits
+	var index []int     // Index in fields of every entry of tokens
+	for i, f := range fields {
+		if norm := normalizeWord(f); norm != "" {
+			tokens = append(tokens, norm)
+			index = append(index, i)
+		}
+	}
+
+	next := 0
+	for k, norm := range tokens {
+		candidates := positions[norm]
+		first := sort.SearchInts(candidates, next)
+		best, bestRun := -1, 0
+		for _, j := range candidates[first:] {
+			if j >= next+alignLookahead {
+				break
+			}
+			run := matchRun(words[j:], tokens[k:])
+			if run > bestRun {
+				best, bestRun = j, run
+			}
+			if run == alignRun {
+				break
+			}
+		}
+		if best < 0 || (best > next && bestRun < 2 && k+1 < len(tokens)) {
+			continue
+		}
+		spans[index[k]] = words[best].span
+		next = best + 1
+	}
+	return spans
+}
+
+// matchRun returns how many leading words match tokens, at most alignRun.
+func matchRun(words []sourceWord, tokens []string) int {
+	n := 0
+	for n < alignRun && n < len(words) && n < len(tokens) && words[n].norm == tokens[n] {
+		n++
+	}
+	return n
+}
+
+// FragmentSpan returns the source range of the tokens [start, end), or false
+// when none of them was found.
+func FragmentSpan(spans []Span, start, end int) (Span, bool) {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(spans) {
+		end = len(spans)
+	}
+	var out Span
+	found := false
+	for i := start; i < end; i++ {
+		if spans[i] == (Span{}) {
+			continue
+		}
+		if !found {
+			out.Start = spans[i].Start
+			found = true
+		}
+		out.End = spans[i].End
+	}
+	return out, found
+}
+
+// sourceWords splits source into whitespace-separated words, treating
+// "<...>" markup as a separator when markup is set. Words without letters or
+// digits are dropped.
+func sourceWords(source string, markup bool) []sourceWord {
+	var words []sourceWord
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if norm := normalizeWord(source[start:end]); norm != "" {
+			words = append(words, sourceWord{norm: norm, span: Span{start, end}})
+		}
+		start = -1
+	}
+	for i := 0; i < len(source); {
+		r, size := utf8.DecodeRuneInString(source[i:])
+		switch {
+		case markup && r == '<':
+			flush(i)
+			if end := strings.IndexByte(source[i:], '>'); end >= 0 {
+				i += end + 1
+				continue
+			}
+		case unicode.IsSpace(r):
+			flush(i)
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+		i += size
+	}
+	flush(len(source))
+	return words
+}
+
+func normalizeWord(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// LineIndex converts byte offsets of a document into line positions.
+type LineIndex struct {
+	text  string
+	start []int // Byte offset of every line start
+}
+
+// NewLineIndex indexes the lines of text.
+func NewLineIndex(text string) *LineIndex {
+	idx := &LineIndex{text: text, start: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			idx.start = append(idx.start, i+1)
+		}
+	}
+	return idx
+}
+
+// Line returns the zero-based line of a byte offset.
+func (idx *LineIndex) Line(offset int) int {
+	return sort.Search(len(idx.start), func(i int) bool { return idx.start[i] > offset }) - 1
+}
+
+// Position returns the zero-based line and UTF-16 column of a byte offset,
+// as used by the Language Server Protocol.
+func (idx *LineIndex) Position(offset int) (line, character int) {
+	line = idx.Line(offset)
+	for _, r := range idx.text[idx.start[line]:offset] {
+		character += utf16.RuneLen(r)
+	}
+	return line, character
+}
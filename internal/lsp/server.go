@@ -0,0 +1,331 @@
+// Package lsp implements "docline lsp", a Language Server Protocol server
+// over stdio. It analyses a document when it is opened or saved, publishes a
+// diagnostic on every clone fragment and answers textDocument/references with
+// the other fragments of the same clone group.
+//
+// DocBook, DRL and HTML files are parsed with the registered parsers and
+// fragments are mapped back to source positions; other files are analysed as
+// plain text.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/locate"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+// Settings are read from the client's initializationOptions.
+type Settings struct {
+	Finder         string                 `json:"finder"`           // Clone finder (default "automatic")
+	MinCloneLength int                    `json:"min_clone_length"` // 0 = finder default
+	MinGroupPower  int                    `json:"min_group_power"`  // 0 = finder default
+	Params         map[string]interface{} `json:"params"`           // Finder-specific parameters
+	Severity       int                    `json:"severity"`         // LSP DiagnosticSeverity (default 3, information)
+}
+
+// Server is a docline language server.
+type Server struct {
+	d        *docline.Docline
+	conn     *conn
+	settings Settings
+	docs     map[string]*document
+	shutdown bool
+}
+
+// document is an open text document and its last analysis.
+type document struct {
+	text   string
+	lines  *locate.LineIndex
+	groups []locatedGroup
+}
+
+// locatedGroup is a clone group whose fragments were found in the document.
+type locatedGroup struct {
+	id     string
+	power  int
+	ranges []lspRange
+}
+
+// New creates a server analysing documents with d.
+func New(d *docline.Docline) *Server {
+	return &Server{
+		d:        d,
+		settings: Settings{Finder: "automatic", Severity: 3},
+		docs:     map[string]*document{},
+	}
+}
+
+// Serve processes messages from r and writes responses and notifications
+// to w until the client sends "exit" or r ends. It returns nil after an
+// orderly shutdown.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) && s.shutdown {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != nil {
+			s.conn.write(&message{ID: json.RawMessage("null"), Error: msg.Error})
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit before shutdown")
+			}
+			return nil
+		}
+
+		result, rpcErr := s.handle(msg)
+		if !msg.isRequest() {
+			continue
+		}
+		resp := &message{ID: msg.ID, Result: result, Error: rpcErr}
+		if rpcErr == nil && result == nil {
+			resp.Result = json.RawMessage("null")
+		}
+		if err := s.conn.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) (interface{}, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		var p initializeParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		if o := p.InitializationOptions; o != nil {
+			if o.Finder != "" {
+				s.settings.Finder = o.Finder
+			}
+			if o.Severity >= 1 && o.Severity <= 4 {
+				s.settings.Severity = o.Severity
+			}
+			s.settings.MinCloneLength = o.MinCloneLength
+			s.settings.MinGroupPower = o.MinGroupPower
+			s.settings.Params = o.Params
+		}
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    1, // Full
+					"save":      map[string]bool{"includeText": true},
+				},
+				"referencesProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "docline"},
+		}, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if json.Unmarshal(msg.Params, &p) == nil {
+			s.analyze(p.TextDocument.URI, p.TextDocument.Text)
+		}
+
+	case "textDocument/didChange":
+		// Analysis runs on save; keep the text for a save without text.
+		var p didChangeParams
+		if json.Unmarshal(msg.Params, &p) == nil && len(p.ContentChanges) > 0 {
+			if doc, ok := s.docs[p.TextDocument.URI]; ok {
+				doc.text = p.ContentChanges[len(p.ContentChanges)-1].Text
+			}
+		}
+
+	case "textDocument/didSave":
+		var p didSaveParams
+		if json.Unmarshal(msg.Params, &p) == nil {
+			text := ""
+			if p.Text != nil {
+				text = *p.Text
+			} else if doc, ok := s.docs[p.TextDocument.URI]; ok {
+				text = doc.text
+			}
+			s.analyze(p.TextDocument.URI, text)
+		}
+
+	case "textDocument/didClose":
+		var p didCloseParams
+		if json.Unmarshal(msg.Params, &p) == nil {
+			delete(s.docs, p.TextDocument.URI)
+			s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []diagnostic{}})
+		}
+
+	case "textDocument/references":
+		var p referenceParams
+		if err := json.Unmarshal(msg.Params, &p); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.references(p), nil
+
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		// Nothing to do.
+
+	default:
+		if msg.isRequest() {
+			if msg.Method == "" {
+				return nil, &responseError{Code: codeInvalidRequest, Message: "missing method"}
+			}
+			return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+// analyze runs the finder on text and publishes the diagnostics of uri.
+func (s *Server) analyze(uri, text string) {
+	doc := &document{text: text, lines: locate.NewLineIndex(text)}
+	s.docs[uri] = doc
+
+	ext := strings.ToLower(path.Ext(uriPath(uri)))
+	markup := locate.IsMarkup(ext)
+	analysed := text
+	if markup {
+		extracted, err := s.d.ExtractText(strings.NewReader(text), ext)
+		if err != nil {
+			s.log(2, fmt.Sprintf("%s: %v; analysing as plain text", uri, err))
+			markup = false
+		} else {
+			analysed = extracted
+		}
+	}
+
+	result, err := s.d.AnalyzeReaderWithConfig(strings.NewReader(analysed), "", s.settings.Finder, docline.CloneFinderConfig{
+		MinCloneLength: s.settings.MinCloneLength,
+		MinGroupPower:  s.settings.MinGroupPower,
+		CustomParams:   s.settings.Params,
+	})
+	if err != nil {
+		s.log(1, fmt.Sprintf("%s: %v", uri, err))
+		return
+	}
+
+	spans := locate.TokenSpans(text, analysed, markup)
+	for _, g := range result.Groups {
+		if suppressed, _ := g.Metadata[docline.SuppressedMetadataKey].(bool); suppressed {
+			continue
+		}
+		lg := locatedGroup{power: g.Power}
+		lg.id, _ = g.Metadata[docline.GroupIDMetadataKey].(string)
+		for _, f := range g.Fragments {
+			if sp, ok := locate.FragmentSpan(spans, f.StartPos, f.EndPos); ok {
+				lg.ranges = append(lg.ranges, sourceRange(doc.lines, sp))
+			}
+		}
+		if len(lg.ranges) > 1 {
+			sort.Slice(lg.ranges, func(i, j int) bool { return before(lg.ranges[i].Start, lg.ranges[j].Start) })
+			doc.groups = append(doc.groups, lg)
+		}
+	}
+
+	// Finders return groups in no particular order; keep diagnostics and
+	// references stable between saves.
+	sort.SliceStable(doc.groups, func(i, j int) bool {
+		return before(doc.groups[i].ranges[0].Start, doc.groups[j].ranges[0].Start)
+	})
+	s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: s.diagnostics(uri, doc)})
+}
+
+func (s *Server) diagnostics(uri string, doc *document) []diagnostic {
+	diags := []diagnostic{}
+	for _, g := range doc.groups {
+		power := g.power
+		if power < len(g.ranges) {
+			power = len(g.ranges)
+		}
+		for i, r := range g.ranges {
+			var others []string
+			var related []diagnosticRelatedInformation
+			for j, o := range g.ranges {
+				if j == i {
+					continue
+				}
+				others = append(others, fmt.Sprintf("line %d", o.Start.Line+1))
+				related = append(related, diagnosticRelatedInformation{
+					Location: location{URI: uri, Range: o},
+					Message:  "other occurrence",
+				})
+			}
+			diags = append(diags, diagnostic{
+				Range:              r,
+				Severity:           s.settings.Severity,
+				Code:               g.id,
+				Source:             "docline",
+				Message:            fmt.Sprintf("Duplicated %d times, see %s", power, strings.Join(others, ", ")),
+				RelatedInformation: related,
+			})
+		}
+	}
+	return diags
+}
+
+// references returns the fragments of the clone group at the position.
+func (s *Server) references(p referenceParams) []location {
+	locations := []location{}
+	doc, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return locations
+	}
+	for _, g := range doc.groups {
+		hit := -1
+		for i, r := range g.ranges {
+			if !before(p.Position, r.Start) && before(p.Position, r.End) {
+				hit = i
+				break
+			}
+		}
+		if hit < 0 {
+			continue
+		}
+		for i, r := range g.ranges {
+			if i != hit || p.Context.IncludeDeclaration {
+				locations = append(locations, location{URI: p.TextDocument.URI, Range: r})
+			}
+		}
+		return locations
+	}
+	return locations
+}
+
+func (s *Server) log(level int, text string) {
+	s.conn.notify("window/logMessage", logMessageParams{Type: level, Message: text})
+}
+
+func sourceRange(lines *locate.LineIndex, sp locate.Span) lspRange {
+	var r lspRange
+	r.Start.Line, r.Start.Character = lines.Position(sp.Start)
+	r.End.Line, r.End.Character = lines.Position(sp.End)
+	return r
+}
+
+func before(a, b position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
+
+// uriPath returns the path of a file URI, or the URI itself when it does not
+// parse.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	return u.Path
+}
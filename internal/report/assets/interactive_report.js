@@ -0,0 +1,97 @@
+(function () {
+  "use strict";
+
+  var table = document.getElementById("groups");
+  var tbody = table.tBodies[0];
+  var minPower = document.getElementById("min-power");
+  var minLength = document.getElementById("min-length");
+  var search = document.getElementById("search");
+  var binInfo = document.getElementById("bin-info");
+  var selectedBin = null;
+
+  function groupRows() {
+    return Array.prototype.slice.call(tbody.querySelectorAll("tr.group"));
+  }
+
+  function detailsRow(row) {
+    return document.getElementById("fragments-" + row.dataset.index);
+  }
+
+  function applyFilters() {
+    var p = parseInt(minPower.value, 10) || 0;
+    var l = parseInt(minLength.value, 10) || 0;
+    var q = search.value.trim().toLowerCase();
+    var shown = 0;
+    groupRows().forEach(function (row) {
+      var bins = row.dataset.bins ? row.dataset.bins.split(" ") : [];
+      var visible =
+        parseInt(row.dataset.power, 10) >= p &&
+        parseInt(row.dataset.length, 10) >= l &&
+        (q === "" || row.dataset.text.indexOf(q) !== -1) &&
+        (selectedBin === null || bins.indexOf(selectedBin) !== -1);
+      row.classList.toggle("hidden", !visible);
+      row.classList.toggle("highlight", visible && selectedBin !== null);
+      if (!visible) {
+        detailsRow(row).classList.add("hidden");
+      }
+      if (visible) {
+        shown++;
+      }
+    });
+    document.getElementById("shown-count").textContent = shown;
+  }
+
+  function sortBy(th) {
+    var key = th.dataset.sort;
+    var desc = !th.classList.contains("desc");
+    table.querySelectorAll("th.sortable").forEach(function (h) {
+      h.classList.remove("asc", "desc");
+    });
+    th.classList.add(desc ? "desc" : "asc");
+    var rows = groupRows();
+    rows.sort(function (a, b) {
+      var d = parseInt(a.dataset[key], 10) - parseInt(b.dataset[key], 10);
+      return desc ? -d : d;
+    });
+    rows.forEach(function (row) {
+      tbody.appendChild(row);
+      tbody.appendChild(detailsRow(row));
+    });
+  }
+
+  table.querySelectorAll("th.sortable").forEach(function (th) {
+    th.addEventListener("click", function () {
+      sortBy(th);
+    });
+  });
+
+  groupRows().forEach(function (row) {
+    row.addEventListener("click", function () {
+      detailsRow(row).classList.toggle("hidden");
+    });
+  });
+
+  document.querySelectorAll(".heat").forEach(function (bin) {
+    bin.addEventListener("click", function () {
+      document.querySelectorAll(".heat.selected").forEach(function (b) {
+        b.classList.remove("selected");
+      });
+      if (selectedBin === bin.dataset.bin) {
+        selectedBin = null;
+        binInfo.textContent = "";
+      } else {
+        selectedBin = bin.dataset.bin;
+        bin.classList.add("selected");
+        binInfo.textContent = "Showing groups in tokens " + bin.dataset.start + "-" + bin.dataset.end + " (click again to clear)";
+      }
+      applyFilters();
+      table.scrollIntoView({ behavior: "smooth" });
+    });
+  });
+
+  [minPower, minLength, search].forEach(function (input) {
+    input.addEventListener("input", applyFilters);
+  });
+
+  applyFilters();
+})();
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -63,6 +65,33 @@ func (c *DocumentConverter) ConvertToDocBook(inputPath string) (string, error) {
 	return outputPath, nil
 }
 
+// ConvertReaderToDocBook converts a document read from r, whose format is
+// given by the file extension ext, to DocBook using pandoc's standard input
+// and output; no files are written
+func (c *DocumentConverter) ConvertReaderToDocBook(r io.Reader, ext string) ([]byte, error) {
+	ext = strings.ToLower(ext)
+	if !c.SupportedInputFormats[ext] {
+		return nil, fmt.Errorf("unsupported input format: %s", ext)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("pandoc",
+		"-f", getPandocFormat(ext),
+		"-t", "docbook")
+	cmd.Stdin = r
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("pandoc conversion failed: %v: %s", err, msg)
+		}
+		return nil, fmt.Errorf("pandoc conversion failed: %v", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
 // getPandocFormat returns the pandoc format identifier for a file extension
 func getPandocFormat(ext string) string {
 	switch ext {
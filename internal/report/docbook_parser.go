@@ -41,25 +41,15 @@ func NewDocBookParser() *DocBookParser {
 
 // ParseDocBook parses a DocBook XML file and returns extracted text segments
 func (p *DocBookParser) ParseDocBook(reader io.Reader) ([]string, error) {
-	fmt.Printf("Starting DocBook parsing...\n")
-
 	// read file content
 	content, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read content: %v", err)
 	}
-	fmt.Printf("Read %d bytes from file\n", len(content))
-
-	// check if the file starts with XML declaration
-	contentStr := string(content)
-	if !strings.HasPrefix(strings.TrimSpace(contentStr), "<?xml") {
-		fmt.Printf("Warning: File does not start with XML declaration\n")
-		fmt.Printf("First 100 characters: %s\n", contentStr[:min(len(contentStr), 100)])
-	}
 
 	// parse XML directly, without preprocessing
 	var doc DocBookElement
-	decoder := xml.NewDecoder(strings.NewReader(contentStr))
+	decoder := xml.NewDecoder(strings.NewReader(string(content)))
 	decoder.Strict = false // allow more flexible parsing
 
 	// set handler for HTML entities
@@ -75,16 +65,11 @@ func (p *DocBookParser) ParseDocBook(reader io.Reader) ([]string, error) {
 	}
 
 	if err := decoder.Decode(&doc); err != nil {
-		fmt.Printf("Error decoding XML: %v\n", err)
-		fmt.Printf("Last 100 characters of content: %s\n",
-			contentStr[max(0, len(contentStr)-100):])
 		return nil, fmt.Errorf("failed to decode XML: %v", err)
 	}
-	fmt.Printf("Successfully decoded XML document, root element: %s\n", doc.XMLName.Local)
 
 	var segments []string
 	p.extractText(&doc, &segments)
-	fmt.Printf("Extracted %d text segments from DocBook\n", len(segments))
 	return segments, nil
 }
 
@@ -8,7 +8,11 @@ import (
 )
 
 // DocBookParserAdapter adapts DocBookParser to the framework.DocumentParser interface.
-type DocBookParserAdapter struct{}
+type DocBookParserAdapter struct {
+	// TextElements replaces the elements text is extracted from; nil keeps
+	// the DocBookParser defaults.
+	TextElements []string
+}
 
 func (d *DocBookParserAdapter) Name() string {
 	return "docbook"
@@ -20,6 +24,12 @@ func (d *DocBookParserAdapter) SupportedFormats() []string {
 
 func (d *DocBookParserAdapter) Parse(reader io.Reader) ([]string, error) {
 	parser := NewDocBookParser()
+	if d.TextElements != nil {
+		parser.TextElements = make(map[string]bool, len(d.TextElements))
+		for _, elem := range d.TextElements {
+			parser.TextElements[elem] = true
+		}
+	}
 	return parser.ParseDocBook(reader)
 }
 
@@ -50,6 +60,18 @@ func (p *PandocConverterAdapter) Convert(inputPath string, outputFormat string)
 	return p.converter.ConvertToDocBook(inputPath)
 }
 
+// ConvertReader implements framework.StreamConverter by piping the document
+// through pandoc.
+func (p *PandocConverterAdapter) ConvertReader(input io.Reader, inputFormat string, outputFormat string) ([]byte, error) {
+	if !p.isSupportedOutput(outputFormat) {
+		return nil, fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+	if p.converter == nil {
+		p.converter = NewDocumentConverter()
+	}
+	return p.converter.ConvertReaderToDocBook(input, inputFormat)
+}
+
 func (p *PandocConverterAdapter) IsConversionNeeded(filePath string) bool {
 	if p.converter == nil {
 		p.converter = NewDocumentConverter()
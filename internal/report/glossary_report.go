@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// GlossaryReportGenerator implements framework.ReportGenerator for a Markdown
+// list of glossary candidates: one row per term with its frequency, spelling
+// variants and locations, inconsistent terms first. It is meant for the
+// "terminology" finder but works with any groups, taking variants from
+// fragment texts.
+type GlossaryReportGenerator struct {
+	// MaxLocations limits the locations listed per term; 0 means 5.
+	MaxLocations int
+}
+
+func (g *GlossaryReportGenerator) Name() string {
+	return "glossary-report"
+}
+
+func (g *GlossaryReportGenerator) Format() string {
+	return "glossary"
+}
+
+// glossaryTerm is a term prepared for the glossary table.
+type glossaryTerm struct {
+	preferred    string
+	frequency    int
+	variants     []string // Markdown "login (5)" sorted by frequency
+	locations    []string // Markdown code spans
+	inconsistent bool
+}
+
+func (g *GlossaryReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	maxLocations := g.MaxLocations
+	if maxLocations <= 0 {
+		maxLocations = 5
+	}
+
+	terms := make([]glossaryTerm, 0, len(groups))
+	inconsistent := 0
+	for _, grp := range groups {
+		t := glossaryTermOf(grp, cfg.SourceFile, maxLocations)
+		if t.inconsistent {
+			inconsistent++
+		}
+		terms = append(terms, t)
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		if terms[i].inconsistent != terms[j].inconsistent {
+			return terms[i].inconsistent
+		}
+		return terms[i].frequency > terms[j].frequency
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# Glossary candidates\n\n")
+	if cfg.SourceFile != "" {
+		sb.WriteString("**Source:** " + markdownCode(cfg.SourceFile) + "\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("%d terms, %d with inconsistent spelling.\n\n", len(terms), inconsistent))
+	if len(terms) == 0 {
+		return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
+	}
+
+	sb.WriteString("| Term | Frequency | Variants | Locations | Consistency |\n|---|---:|---|---|---|\n")
+	for _, t := range terms {
+		status := "consistent"
+		if t.inconsistent {
+			status = "**inconsistent**"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s |\n",
+			markdownEscape(t.preferred),
+			t.frequency,
+			strings.Join(t.variants, ", "),
+			strings.Join(t.locations, ", "),
+			status,
+		))
+	}
+
+	return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
+}
+
+// glossaryTermOf summarises a group as a glossary term. Variants come from
+// fragment "term_variant" metadata, falling back to the fragment text.
+func glossaryTermOf(grp framework.CloneGroup, sourceFile string, maxLocations int) glossaryTerm {
+	counts := map[string]int{}
+	t := glossaryTerm{preferred: grp.Archetype, frequency: len(grp.Fragments)}
+	for i, f := range grp.Fragments {
+		variant, _ := f.Metadata["term_variant"].(string)
+		if variant == "" {
+			variant = strings.Join(strings.Fields(f.Content), " ")
+		}
+		counts[variant]++
+		if i < maxLocations {
+			t.locations = append(t.locations, markdownCode(fragmentLocation(f, sourceFile)))
+		}
+	}
+	if extra := len(grp.Fragments) - maxLocations; extra > 0 {
+		t.locations = append(t.locations, fmt.Sprintf("+%d more", extra))
+	}
+
+	names := make([]string, 0, len(counts))
+	for v := range counts {
+		names = append(names, v)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for _, v := range names {
+		t.variants = append(t.variants, fmt.Sprintf("%s (%d)", markdownEscape(v), counts[v]))
+	}
+	if t.preferred == "" && len(names) > 0 {
+		t.preferred = names[0]
+	}
+
+	if v, ok := grp.Metadata["inconsistent_term"].(bool); ok {
+		t.inconsistent = v
+	} else {
+		t.inconsistent = len(counts) > 1
+	}
+	return t
+}
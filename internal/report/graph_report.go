@@ -0,0 +1,355 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// ReportConfig.CustomParams keys understood by the graph report generators.
+const (
+	// GraphCollapseParam set to "file" (or true) merges all windows of a file
+	// into one node.
+	GraphCollapseParam = "collapse"
+	// GraphSectionLinesParam is the size in lines of the windows of analysed
+	// text that become nodes; default 50.
+	GraphSectionLinesParam = "section_lines"
+)
+
+// cloneGraph is an undirected graph of line windows (or files) linked by
+// shared clone tokens. The built-in parsers keep no section structure, so a
+// node is a fixed window of lines of the analysed text rather than a section
+// of the document; a fragment carrying "section" metadata, e.g. from a custom
+// parser, is placed in a node of that name instead.
+type cloneGraph struct {
+	Nodes []graphNode
+	Edges []graphEdge
+}
+
+type graphNode struct {
+	ID        string
+	Label     string
+	File      string
+	Section   string // "L<start>-<end>" window or section name; empty for file nodes
+	Fragments int    // Clone fragments located in the node
+	Tokens    int    // Clone tokens located in the node
+}
+
+type graphEdge struct {
+	From, To string
+	Weight   int // Clone tokens shared between the two nodes
+	Groups   int // Clone groups linking the two nodes
+}
+
+// graphNodeKey identifies a node before IDs are assigned.
+type graphNodeKey struct {
+	file    string
+	section string
+	order   int // Sort key: first line of the window or section
+}
+
+// buildCloneGraph places every fragment in a node (its file, or a line window
+// of it) and links nodes sharing a group. For each group, the weight added to an
+// edge is the smaller of the clone tokens the group has in either node.
+func buildCloneGraph(groups []framework.CloneGroup, cfg framework.ReportConfig) cloneGraph {
+	collapse := false
+	switch v := cfg.CustomParams[GraphCollapseParam].(type) {
+	case string:
+		collapse = v == "file" || v == "true"
+	case bool:
+		collapse = v
+	}
+	sectionLines := 50
+	if n, ok := intFromMetadata(cfg.CustomParams, GraphSectionLinesParam); ok && n > 0 {
+		sectionLines = n
+	}
+
+	nodes := map[graphNodeKey]*graphNode{}
+	type pair struct{ a, b graphNodeKey }
+	edges := map[pair]*graphEdge{}
+
+	for _, g := range groups {
+		perNode := map[graphNodeKey]int{}
+		for _, f := range g.Fragments {
+			key := graphNodeKeyOf(f, cfg.SourceFile, collapse, sectionLines)
+			n, ok := nodes[key]
+			if !ok {
+				n = &graphNode{File: key.file, Section: key.section}
+				nodes[key] = n
+			}
+			tokens := f.EndPos - f.StartPos
+			if tokens < 0 {
+				tokens = 0
+			}
+			n.Fragments++
+			n.Tokens += tokens
+			perNode[key] += tokens
+		}
+
+		keys := sortedGraphKeys(perNode)
+		for i := 0; i < len(keys); i++ {
+			for j := i + 1; j < len(keys); j++ {
+				p := pair{keys[i], keys[j]}
+				e, ok := edges[p]
+				if !ok {
+					e = &graphEdge{}
+					edges[p] = e
+				}
+				w := perNode[keys[i]]
+				if perNode[keys[j]] < w {
+					w = perNode[keys[j]]
+				}
+				e.Weight += w
+				e.Groups++
+			}
+		}
+	}
+
+	keys := make([]graphNodeKey, 0, len(nodes))
+	for k := range nodes {
+		keys = append(keys, k)
+	}
+	sortGraphKeys(keys)
+	index := make(map[graphNodeKey]int, len(keys))
+	var graph cloneGraph
+	for i, k := range keys {
+		n := nodes[k]
+		n.ID = fmt.Sprintf("n%d", i)
+		n.Label = n.File
+		if n.Section != "" {
+			n.Label += " " + n.Section
+		}
+		index[k] = i
+		graph.Nodes = append(graph.Nodes, *n)
+	}
+
+	pairs := make([]pair, 0, len(edges))
+	for p := range edges {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if index[pairs[i].a] != index[pairs[j].a] {
+			return index[pairs[i].a] < index[pairs[j].a]
+		}
+		return index[pairs[i].b] < index[pairs[j].b]
+	})
+	for _, p := range pairs {
+		e := edges[p]
+		e.From, e.To = graph.Nodes[index[p.a]].ID, graph.Nodes[index[p.b]].ID
+		graph.Edges = append(graph.Edges, *e)
+	}
+	return graph
+}
+
+// graphNodeKeyOf returns the node of a fragment: its "section" metadata, the
+// window of sectionLines lines holding its first line, or the whole file when
+// collapsing or when no line is known.
+func graphNodeKeyOf(f framework.TextFragment, sourceFile string, collapse bool, sectionLines int) graphNodeKey {
+	key := graphNodeKey{file: fragmentFile(f, sourceFile)}
+	if collapse {
+		return key
+	}
+	line, hasLine := intFromMetadata(f.Metadata, framework.SourceLineStartMetadataKey)
+	if s, ok := f.Metadata["section"].(string); ok && s != "" {
+		key.section = s
+		if hasLine {
+			key.order = line
+		}
+		return key
+	}
+	if hasLine && line > 0 {
+		start := ((line-1)/sectionLines)*sectionLines + 1
+		key.section = fmt.Sprintf("L%d-%d", start, start+sectionLines-1)
+		key.order = start
+	}
+	return key
+}
+
+// sortedGraphKeys returns the keys of m in node order.
+func sortedGraphKeys(m map[graphNodeKey]int) []graphNodeKey {
+	keys := make([]graphNodeKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortGraphKeys(keys)
+	return keys
+}
+
+// sortGraphKeys orders node keys by file, first line and name.
+func sortGraphKeys(keys []graphNodeKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].file != keys[j].file {
+			return keys[i].file < keys[j].file
+		}
+		if keys[i].order != keys[j].order {
+			return keys[i].order < keys[j].order
+		}
+		return keys[i].section < keys[j].section
+	})
+}
+
+// DOTReportGenerator implements framework.ReportGenerator for a Graphviz DOT
+// graph of line windows (or files) linked by shared clone tokens. Node and
+// edge counts go into the standard tooltip attribute.
+type DOTReportGenerator struct{}
+
+func (d *DOTReportGenerator) Name() string {
+	return "dot-report"
+}
+
+func (d *DOTReportGenerator) Format() string {
+	return "dot"
+}
+
+func (d *DOTReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	graph := buildCloneGraph(groups, cfg)
+	maxWeight := 1
+	for _, e := range graph.Edges {
+		if e.Weight > maxWeight {
+			maxWeight = e.Weight
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("graph docline {\n")
+	sb.WriteString("  label=" + dotQuote(cfg.Title) + ";\n")
+	sb.WriteString("  node [shape=box, style=rounded];\n")
+	for _, n := range graph.Nodes {
+		tooltip := fmt.Sprintf("%s\nfragments: %d\nclone tokens: %d", n.Label, n.Fragments, n.Tokens)
+		sb.WriteString(fmt.Sprintf("  %s [label=%s, tooltip=%s];\n",
+			n.ID, dotQuote(n.Label), dotQuote(tooltip)))
+	}
+	for _, e := range graph.Edges {
+		width := 1 + 4*float64(e.Weight)/float64(maxWeight)
+		tooltip := fmt.Sprintf("shared clone tokens: %d\ngroups: %d", e.Weight, e.Groups)
+		sb.WriteString(fmt.Sprintf("  %s -- %s [weight=%d, label=\"%d\", tooltip=%s, penwidth=%.2f];\n",
+			e.From, e.To, e.Weight, e.Weight, dotQuote(tooltip), width))
+	}
+	sb.WriteString("}\n")
+
+	return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
+}
+
+// dotQuote renders s as a DOT double-quoted string.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	return `"` + r.Replace(s) + `"`
+}
+
+// GraphMLReportGenerator implements framework.ReportGenerator for a GraphML
+// graph of line windows (or files) linked by shared clone tokens, for tools such
+// as Gephi, yEd or Cytoscape.
+type GraphMLReportGenerator struct{}
+
+func (g *GraphMLReportGenerator) Name() string {
+	return "graphml-report"
+}
+
+func (g *GraphMLReportGenerator) Format() string {
+	return "graphml"
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (g *GraphMLReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	graph := buildCloneGraph(groups, cfg)
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "file", For: "node", AttrName: "file", AttrType: "string"},
+			{ID: "section", For: "node", AttrName: "section", AttrType: "string"},
+			{ID: "fragments", For: "node", AttrName: "fragments", AttrType: "int"},
+			{ID: "tokens", For: "node", AttrName: "tokens", AttrType: "int"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+			{ID: "groups", For: "edge", AttrName: "groups", AttrType: "int"},
+		},
+		Graph: graphMLGraph{ID: "docline", EdgeDefault: "undirected"},
+	}
+	for _, n := range graph.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: n.Label},
+				{Key: "file", Value: n.File},
+				{Key: "section", Value: n.Section},
+				{Key: "fragments", Value: fmt.Sprint(n.Fragments)},
+				{Key: "tokens", Value: fmt.Sprint(n.Tokens)},
+			},
+		})
+	}
+	for _, e := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.From,
+			Target: e.To,
+			Data: []graphMLData{
+				{Key: "weight", Value: fmt.Sprint(e.Weight)},
+				{Key: "groups", Value: fmt.Sprint(e.Groups)},
+			},
+		})
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("create graphml file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(xml.Header); err != nil {
+		return fmt.Errorf("write graphml: %w", err)
+	}
+	enc := xml.NewEncoder(file)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encode graphml: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package internal
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// HTMLTemplateDirParam is the ReportConfig.CustomParams key naming a directory
+// with custom HTML templates. Every *.tmpl file in it is parsed; rendering
+// starts at the template named by HTMLTemplateEntry.
+const HTMLTemplateDirParam = "template_dir"
+
+// HTMLTemplateEntry is the name of the entry template of an HTML report.
+const HTMLTemplateEntry = "report.html.tmpl"
+
+//go:embed assets/report.html.tmpl
+var defaultHTMLTemplate string
+
+var defaultHTMLTmpl = template.Must(template.New(HTMLTemplateEntry).Parse(defaultHTMLTemplate))
+
+// HTMLReportGenerator implements framework.ReportGenerator for HTML output.
+// The page is rendered with html/template from an embedded default template,
+// or from a custom template directory passed as
+// ReportConfig.CustomParams["template_dir"]; templates receive HTMLReportData.
+type HTMLReportGenerator struct{}
+
+func (h *HTMLReportGenerator) Name() string {
+	return "html-report"
+}
+
+func (h *HTMLReportGenerator) Format() string {
+	return "html"
+}
+
+// HTMLReportData is the data model passed to HTML report templates.
+type HTMLReportData struct {
+	Title        string                         // Report title
+	SourceFile   string                         // Analysed document
+	Settings     map[string]interface{}         // Analysis settings and result metadata
+	Params       map[string]interface{}         // ReportConfig.CustomParams (e.g. branding values)
+	Stats        framework.AnalysisStatistics   // Analysis statistics
+	TotalTokens  int                            // Tokens in the analysed text
+	Accepted     int                            // Groups accepted by a baseline
+	StaleEntries []framework.StaleBaselineEntry // Expired or unmatched baseline entries
+	Heatmap      []HTMLHeatBin                  // Token coverage histogram; empty when nothing is covered
+	Charts       []HTMLChart                    // SVG heatmaps per file, length histogram, power distribution
+	Groups       []HTMLGroup                    // Clone groups in report order
+}
+
+// HTMLHeatBin is one bin of the token coverage heatmap.
+type HTMLHeatBin struct {
+	Index int    // 0-based bin index
+	Start int    // First token covered by the bin
+	End   int    // Token after the last one covered by the bin
+	Value int    // Duplicated tokens falling into the bin
+	Alpha string // Colour intensity 0.050-1.000, formatted for CSS
+}
+
+// HTMLChart is an inline SVG duplication chart.
+type HTMLChart struct {
+	Kind  string        // "heatmap", "lengths" or "power"
+	Title string        // Chart title
+	SVG   template.HTML // <svg> element, safe to embed as is
+}
+
+// HTMLGroup is a clone group prepared for templates.
+type HTMLGroup struct {
+	Index     int            // 1-based position in the report
+	ID        string         // Stable group ID
+	Power     int            // Number of fragments
+	Archetype string         // Representative text
+	Badges    []string       // Status labels ("accepted: ...", "new (0 → 2)")
+	Fragments []HTMLFragment // Group members
+	Metadata  map[string]interface{}
+}
+
+// HTMLFragment is a clone fragment prepared for templates.
+type HTMLFragment struct {
+	Content   string // Fragment text
+	StartPos  int    // Starting token index
+	EndPos    int    // Ending token index
+	LineStart int    // 1-based first source line, 0 if unknown
+	LineEnd   int    // 1-based last source line, 0 if unknown
+	Lines     string // "L12" / "L12-14", empty if unknown
+	Location  string // "file:line" (falls back to token positions)
+	Metadata  map[string]interface{}
+}
+
+func (h *HTMLReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	tmpl := defaultHTMLTmpl
+	if dir, _ := cfg.CustomParams[HTMLTemplateDirParam].(string); dir != "" {
+		custom, err := loadHTMLTemplates(dir)
+		if err != nil {
+			return err
+		}
+		tmpl = custom
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("create html file: %w", err)
+	}
+	defer file.Close()
+
+	if err := tmpl.ExecuteTemplate(file, HTMLTemplateEntry, buildHTMLReportData(groups, cfg)); err != nil {
+		return fmt.Errorf("render html: %w", err)
+	}
+	return nil
+}
+
+// loadHTMLTemplates parses every *.tmpl file of dir.
+func loadHTMLTemplates(dir string) (*template.Template, error) {
+	tmpl, err := template.ParseGlob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("load html templates from %s: %w", dir, err)
+	}
+	if tmpl.Lookup(HTMLTemplateEntry) == nil {
+		return nil, fmt.Errorf("template directory %s has no %s", dir, HTMLTemplateEntry)
+	}
+	return tmpl, nil
+}
+
+func buildHTMLReportData(groups []framework.CloneGroup, cfg framework.ReportConfig) HTMLReportData {
+	totalTokens := totalTokensFromSettings(cfg.Settings)
+	if totalTokens <= 0 {
+		totalTokens = maxEndPos(groups)
+	}
+
+	data := HTMLReportData{
+		Title:        cfg.Title,
+		SourceFile:   cfg.SourceFile,
+		Settings:     cfg.Settings,
+		Params:       cfg.CustomParams,
+		Stats:        statsFromSettings(cfg.Settings),
+		TotalTokens:  totalTokens,
+		Accepted:     countSuppressed(groups),
+		StaleEntries: staleEntriesFromSettings(cfg.Settings),
+		Heatmap:      heatBins(buildHeatmap(groups, totalTokens, 120), totalTokens),
+		Groups:       make([]HTMLGroup, 0, len(groups)),
+	}
+	for _, c := range duplicationCharts(groups, cfg) {
+		// The SVG is built by duplicationCharts with all text escaped.
+		data.Charts = append(data.Charts, HTMLChart{Kind: c.Kind, Title: c.Title, SVG: template.HTML(c.SVG)})
+	}
+
+	for i, g := range groups {
+		id, _ := g.Metadata[framework.GroupIDMetadataKey].(string)
+		hg := HTMLGroup{
+			Index:     i + 1,
+			ID:        id,
+			Power:     g.Power,
+			Archetype: g.Archetype,
+			Badges:    groupBadges(g),
+			Metadata:  g.Metadata,
+		}
+		for _, f := range g.Fragments {
+			hf := HTMLFragment{
+				Content:  f.Content,
+				StartPos: f.StartPos,
+				EndPos:   f.EndPos,
+				Location: fragmentLocation(f, cfg.SourceFile),
+				Metadata: f.Metadata,
+			}
+			if ln1, ok := intFromMetadata(f.Metadata, framework.SourceLineStartMetadataKey); ok && ln1 > 0 {
+				hf.LineStart, hf.LineEnd = ln1, ln1
+				hf.Lines = fmt.Sprintf("L%d", ln1)
+				if ln2, ok := intFromMetadata(f.Metadata, framework.SourceLineEndMetadataKey); ok && ln2 > ln1 {
+					hf.LineEnd = ln2
+					hf.Lines = fmt.Sprintf("L%d-%d", ln1, ln2)
+				}
+			}
+			hg.Fragments = append(hg.Fragments, hf)
+		}
+		data.Groups = append(data.Groups, hg)
+	}
+	return data
+}
+
+// heatBins describes heatmap bins for HTML rendering. It returns nil when no
+// token is covered.
+func heatBins(heat []int, totalTokens int) []HTMLHeatBin {
+	maxV := 0
+	for _, v := range heat {
+		if v > maxV {
+			maxV = v
+		}
+	}
+	if maxV == 0 {
+		return nil
+	}
+
+	out := make([]HTMLHeatBin, len(heat))
+	for i, v := range heat {
+		intensity := float64(v) / float64(maxV) // 0..1
+		out[i] = HTMLHeatBin{
+			Index: i,
+			Start: (i * totalTokens) / len(heat),
+			End:   ((i + 1) * totalTokens) / len(heat),
+			Value: v,
+			// Blue-ish gradient; keep background visible even at 0.
+			Alpha: fmt.Sprintf("%.3f", 0.05+0.95*intensity),
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+//go:embed assets/interactive_report.html.tmpl
+var interactiveReportTemplate string
+
+//go:embed assets/interactive_report.css
+var interactiveReportCSS string
+
+//go:embed assets/interactive_report.js
+var interactiveReportJS string
+
+var interactiveTmpl = template.Must(template.New("interactive").Parse(interactiveReportTemplate))
+
+// InteractiveHTMLReportGenerator implements framework.ReportGenerator for a
+// self-contained interactive HTML page (CSS and JS are embedded, no network
+// access): groups can be sorted and filtered by power and length, clicking a
+// heatmap bin shows the groups it covers, and every fragment is rendered as a
+// word-level diff against its group's archetype.
+type InteractiveHTMLReportGenerator struct {
+	// Bins is the number of heatmap bins; 0 means 120.
+	Bins int
+}
+
+func (h *InteractiveHTMLReportGenerator) Name() string {
+	return "interactive-html-report"
+}
+
+func (h *InteractiveHTMLReportGenerator) Format() string {
+	return "html-interactive"
+}
+
+type interactiveReportData struct {
+	Title       string
+	SourceFile  string
+	TotalTokens int
+	Fragments   int
+	Accepted    int
+	Heatmap     []HTMLHeatBin
+	Groups      []interactiveGroup
+	CSS         template.CSS
+	JS          template.JS
+}
+
+type interactiveGroup struct {
+	Index      int
+	Power      int
+	Length     int
+	Archetype  string
+	Badges     []string
+	Bins       string // Space-separated heatmap bin indices touched by the group
+	SearchText string
+	Fragments  []interactiveFragment
+}
+
+type interactiveFragment struct {
+	Location string
+	Diff     []diffSegment
+}
+
+func (h *InteractiveHTMLReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	totalTokens := totalTokensFromSettings(cfg.Settings)
+	if totalTokens <= 0 {
+		totalTokens = maxEndPos(groups)
+	}
+	bins := h.Bins
+	if bins <= 0 {
+		bins = 120
+	}
+	heat := buildHeatmap(groups, totalTokens, bins)
+
+	data := interactiveReportData{
+		Title:       cfg.Title,
+		SourceFile:  cfg.SourceFile,
+		TotalTokens: totalTokens,
+		Fragments:   countFragments(groups),
+		Accepted:    countSuppressed(groups),
+		Heatmap:     heatBins(heat, totalTokens),
+		CSS:         template.CSS(interactiveReportCSS),
+		JS:          template.JS(interactiveReportJS),
+	}
+
+	for i, g := range groups {
+		archetype := g.Archetype
+		if archetype == "" && len(g.Fragments) > 0 {
+			archetype = g.Fragments[0].Content
+		}
+		ig := interactiveGroup{
+			Index:      i + 1,
+			Power:      g.Power,
+			Length:     len(strings.Fields(archetype)),
+			Archetype:  archetype,
+			Badges:     groupBadges(g),
+			Bins:       groupHeatBins(g, totalTokens, len(heat)),
+			SearchText: strings.ToLower(archetype),
+		}
+		for _, f := range g.Fragments {
+			ig.Fragments = append(ig.Fragments, interactiveFragment{
+				Location: fragmentLocation(f, cfg.SourceFile),
+				Diff:     wordDiff(archetype, f.Content),
+			})
+		}
+		data.Groups = append(data.Groups, ig)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("create html file: %w", err)
+	}
+	defer file.Close()
+
+	if err := interactiveTmpl.Execute(file, data); err != nil {
+		return fmt.Errorf("render interactive html: %w", err)
+	}
+	return nil
+}
+
+// groupHeatBins lists the heatmap bins overlapped by any fragment of g, using
+// the same bin layout as buildHeatmap.
+func groupHeatBins(g framework.CloneGroup, totalTokens, bins int) string {
+	if totalTokens <= 0 || bins <= 0 {
+		return ""
+	}
+	seen := map[int]bool{}
+	var out []string
+	for _, f := range g.Fragments {
+		start, end := f.StartPos, f.EndPos
+		if start < 0 {
+			start = 0
+		}
+		if end > totalTokens {
+			end = totalTokens
+		}
+		if end <= start {
+			continue
+		}
+		for bi := (start * bins) / totalTokens; bi <= ((end-1)*bins)/totalTokens && bi < bins; bi++ {
+			if !seen[bi] {
+				seen[bi] = true
+				out = append(out, strconv.Itoa(bi))
+			}
+		}
+	}
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// JUnitReportGenerator implements framework.ReportGenerator for JUnit XML so
+// CI systems show policy violations as failed tests.
+//
+// When the report settings carry a "policy_verdict" (framework.PolicyVerdict)
+// every policy rule becomes a test case. Otherwise every clone group becomes a
+// failed test case; groups accepted by a baseline are reported as skipped.
+type JUnitReportGenerator struct{}
+
+func (j *JUnitReportGenerator) Name() string {
+	return "junit-report"
+}
+
+func (j *JUnitReportGenerator) Format() string {
+	return "junit"
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func (j *JUnitReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	suite := junitTestSuite{Name: "docline: " + cfg.SourceFile}
+	if verdict, ok := policyVerdictFromSettings(cfg.Settings); ok {
+		for _, c := range verdict.Checks {
+			tc := junitTestCase{ClassName: "docline.policy", Name: c.Rule}
+			if !c.Passed {
+				tc.Failure = &junitFailure{Message: c.Message, Type: "policy", Body: c.Message}
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+	} else {
+		for i, g := range groups {
+			id, _ := g.Metadata[framework.GroupIDMetadataKey].(string)
+			if id == "" {
+				id = fmt.Sprintf("group%d", i+1)
+			}
+			tc := junitTestCase{ClassName: "docline.duplication", Name: id}
+			msg := fmt.Sprintf("duplicated %d times: %s", g.Power, g.Archetype)
+			if framework.IsSuppressed(g) {
+				tc.Skipped = &junitSkipped{Message: "accepted: " + msg}
+			} else {
+				tc.Failure = &junitFailure{Message: msg, Type: "duplication", Body: fragmentLocations(g)}
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+	}
+
+	for _, tc := range suite.TestCases {
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+	doc := junitTestSuites{
+		Name:     cfg.Title,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal junit: %w", err)
+	}
+	return os.WriteFile(outputPath, append([]byte(xml.Header), data...), 0o644)
+}
+
+// fragmentLocations lists fragment positions, one per line, using source
+// line numbers when available.
+func fragmentLocations(g framework.CloneGroup) string {
+	s := ""
+	for _, f := range g.Fragments {
+		if ln, ok := intFromMetadata(f.Metadata, framework.SourceLineStartMetadataKey); ok && ln > 0 {
+			s += fmt.Sprintf("line %d: %s\n", ln, f.Content)
+		} else {
+			s += fmt.Sprintf("tokens %d-%d: %s\n", f.StartPos, f.EndPos, f.Content)
+		}
+	}
+	return s
+}
+
+func policyVerdictFromSettings(settings map[string]interface{}) (framework.PolicyVerdict, bool) {
+	if settings == nil {
+		return framework.PolicyVerdict{}, false
+	}
+	switch v := settings["policy_verdict"].(type) {
+	case framework.PolicyVerdict:
+		return v, true
+	case *framework.PolicyVerdict:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return framework.PolicyVerdict{}, false
+}
@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// MarkdownChartsParam is the ReportConfig.CustomParams key that, when true,
+// makes the Markdown report write its SVG charts as sidecar files next to the
+// report ("<report>-<chart>.svg") and link them as images.
+const MarkdownChartsParam = "charts"
+
+// MarkdownMaxGroupsParam is the ReportConfig.CustomParams key limiting the
+// number of groups listed in the Markdown report; it overrides
+// MarkdownReportGenerator.MaxGroups.
+const MarkdownMaxGroupsParam = "max_groups"
+
+// MarkdownReportGenerator implements framework.ReportGenerator for
+// GitHub-flavoured Markdown, suitable for posting as a pull request comment:
+// summary statistics, a table of groups and collapsible fragment lists with
+// file:line locations.
+type MarkdownReportGenerator struct {
+	// MaxGroups limits the number of groups listed when the report config
+	// sets no MarkdownMaxGroupsParam; 0 means no limit.
+	MaxGroups int
+}
+
+func (m *MarkdownReportGenerator) Name() string {
+	return "markdown-report"
+}
+
+func (m *MarkdownReportGenerator) Format() string {
+	return "md"
+}
+
+func (m *MarkdownReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	stats := statsFromSettings(cfg.Settings)
+	totalTokens := totalTokensFromSettings(cfg.Settings)
+
+	var sb strings.Builder
+	sb.WriteString("# " + markdownEscape(cfg.Title) + "\n\n")
+	if cfg.SourceFile != "" {
+		sb.WriteString("**Source:** " + markdownCode(cfg.SourceFile) + "\n\n")
+	}
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString("| Metric | Value |\n|---|---:|\n")
+	sb.WriteString(fmt.Sprintf("| Clone groups | %d |\n", len(groups)))
+	if accepted := countSuppressed(groups); accepted > 0 {
+		sb.WriteString(fmt.Sprintf("| Accepted (baseline) | %d |\n", accepted))
+	}
+	sb.WriteString(fmt.Sprintf("| Fragments | %d |\n", countFragments(groups)))
+	if stats.TotalFragments > 0 {
+		sb.WriteString(fmt.Sprintf("| Tokens per fragment (min / avg / max) | %d / %.1f / %d |\n", stats.MinTokens, stats.AvgTokens, stats.MaxTokens))
+	}
+	if totalTokens > 0 && stats.DuplicatedTokens > 0 {
+		sb.WriteString(fmt.Sprintf("| Duplicated tokens | %d of %d (%.1f%%) |\n",
+			stats.DuplicatedTokens, totalTokens, 100*float64(stats.DuplicatedTokens)/float64(totalTokens)))
+	}
+	sb.WriteString("\n")
+
+	if charts, _ := cfg.CustomParams[MarkdownChartsParam].(bool); charts && len(groups) > 0 {
+		if err := writeMarkdownCharts(&sb, groups, cfg, outputPath); err != nil {
+			return err
+		}
+	}
+
+	if len(groups) == 0 {
+		sb.WriteString("No clone groups found.\n")
+		return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
+	}
+
+	maxGroups := m.MaxGroups
+	if n, ok := intFromMetadata(cfg.CustomParams, MarkdownMaxGroupsParam); ok {
+		maxGroups = n
+	}
+	listed := groups
+	if maxGroups > 0 && len(listed) > maxGroups {
+		listed = listed[:maxGroups]
+	}
+
+	sb.WriteString("## Clone groups\n\n")
+	sb.WriteString("| # | ID | Power | Tokens | Status | Archetype |\n|---:|---|---:|---:|---|---|\n")
+	for i, g := range listed {
+		id, _ := g.Metadata[framework.GroupIDMetadataKey].(string)
+		sb.WriteString(fmt.Sprintf("| %d | %s | %d | %d | %s | %s |\n",
+			i+1,
+			markdownCode(id),
+			g.Power,
+			len(strings.Fields(g.Archetype)),
+			markdownEscape(strings.Join(groupBadges(g), ", ")),
+			markdownEscape(truncateRunes(g.Archetype, 80)),
+		))
+	}
+	if len(listed) < len(groups) {
+		sb.WriteString(fmt.Sprintf("\n_%d more groups not shown._\n", len(groups)-len(listed)))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Fragments\n\n")
+	for i, g := range listed {
+		// The summary line is part of an HTML block, so it takes HTML escaping.
+		sb.WriteString(fmt.Sprintf("<details>\n<summary>Group %d: %d fragments — %s</summary>\n\n",
+			i+1, len(g.Fragments), html.EscapeString(truncateRunes(g.Archetype, 60))))
+		for _, f := range g.Fragments {
+			sb.WriteString("- " + markdownCode(fragmentLocation(f, cfg.SourceFile)) + " " + markdownEscape(f.Content) + "\n")
+		}
+		sb.WriteString("\n</details>\n\n")
+	}
+
+	return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
+}
+
+// writeMarkdownCharts writes the duplication charts next to outputPath and
+// adds a "Charts" section linking them.
+func writeMarkdownCharts(sb *strings.Builder, groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	base := strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	sb.WriteString("## Charts\n\n")
+	for _, c := range duplicationCharts(groups, cfg) {
+		name := base + "-" + c.Name + ".svg"
+		if err := os.WriteFile(filepath.Join(filepath.Dir(outputPath), name), []byte(c.SVG), 0o644); err != nil {
+			return fmt.Errorf("write chart: %w", err)
+		}
+		sb.WriteString(fmt.Sprintf("![%s](<%s>)\n\n", markdownEscape(c.Title), name))
+	}
+	return nil
+}
+
+// fragmentLocation formats "file:line" / "file:start-end", falling back to
+// token positions when no line metadata is available.
+func fragmentLocation(f framework.TextFragment, sourceFile string) string {
+	sourceFile = fragmentFile(f, sourceFile)
+	start, ok := intFromMetadata(f.Metadata, framework.SourceLineStartMetadataKey)
+	if !ok || start <= 0 {
+		return fmt.Sprintf("%s#tokens%d-%d", sourceFile, f.StartPos, f.EndPos)
+	}
+	if end, ok := intFromMetadata(f.Metadata, framework.SourceLineEndMetadataKey); ok && end > start {
+		return fmt.Sprintf("%s:%d-%d", sourceFile, start, end)
+	}
+	return fmt.Sprintf("%s:%d", sourceFile, start)
+}
+
+// markdownEscape makes arbitrary text safe for GitHub-flavoured Markdown
+// paragraphs and table cells: raw HTML is neutralised, Markdown syntax
+// characters are backslash-escaped and line breaks become spaces. '-' and
+// '.' only mean something at the start of a line (a list item, or "1." for
+// an ordered one), so they are escaped there only and "e-mail" or "1.2"
+// stay readable in the raw Markdown.
+func markdownEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	leadingDigits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	var sb strings.Builder
+	for i, r := range s {
+		switch r {
+		case '&':
+			sb.WriteString("&amp;")
+		case '<':
+			sb.WriteString("&lt;")
+		case '>':
+			sb.WriteString("&gt;")
+		case '\\', '`', '*', '_', '{', '}', '[', ']', '(', ')', '#', '+', '!', '|', '~':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case '-':
+			if i == 0 {
+				sb.WriteRune('\\')
+			}
+			sb.WriteRune(r)
+		case '.':
+			if i > 0 && i == leadingDigits {
+				sb.WriteRune('\\')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// markdownCode renders s as an inline code span, choosing a backtick fence
+// longer than any backtick run inside s. It is not meant for table cells
+// holding a '|'.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = strings.ReplaceAll(s, "\n", " ")
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+func truncateRunes(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func countFragments(groups []framework.CloneGroup) int {
+	n := 0
+	for _, g := range groups {
+		n += len(g.Fragments)
+	}
+	return n
+}
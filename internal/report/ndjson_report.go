@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// NDJSON record types.
+const (
+	ndjsonHeader  = "header"
+	ndjsonGroup   = "group"
+	ndjsonTrailer = "trailer"
+)
+
+// NDJSONReportGenerator implements framework.ReportGenerator and
+// framework.StreamingReportGenerator for newline-delimited JSON: a header
+// record, one record per group and a trailer with the final statistics.
+// Groups are encoded as they arrive and are not kept by the generator; how
+// many groups are held in memory at once depends on the finder.
+type NDJSONReportGenerator struct{}
+
+func (n *NDJSONReportGenerator) Name() string {
+	return "ndjson-report"
+}
+
+func (n *NDJSONReportGenerator) Format() string {
+	return "ndjson"
+}
+
+type ndjsonHeaderRecord struct {
+	Type          string                      `json:"type"`
+	SchemaVersion int                         `json:"schema_version"`
+	Title         string                      `json:"title,omitempty"`
+	SourceFile    string                      `json:"source_file,omitempty"`
+	Finder        string                      `json:"finder,omitempty"`
+	TotalTokens   int                         `json:"total_tokens,omitempty"`
+	Config        framework.CloneFinderConfig `json:"config"`
+}
+
+type ndjsonGroupRecord struct {
+	Type string `json:"type"`
+	framework.CloneGroup
+}
+
+type ndjsonTrailerRecord struct {
+	Type     string                       `json:"type"`
+	Groups   int                          `json:"groups"` // Group records written
+	Stats    framework.AnalysisStatistics `json:"stats"`
+	Metadata map[string]interface{}       `json:"metadata,omitempty"`
+}
+
+func (n *NDJSONReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	w, err := n.OpenStream(cfg, outputPath)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if err := w.WriteGroup(g); err != nil {
+			w.Close(framework.StreamSummary{})
+			return err
+		}
+	}
+	return w.Close(framework.StreamSummary{Statistics: statsFromSettings(cfg.Settings), Metadata: cfg.Settings})
+}
+
+func (n *NDJSONReportGenerator) OpenStream(cfg framework.ReportConfig, outputPath string) (framework.GroupStreamWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("create ndjson file: %w", err)
+	}
+
+	buf := bufio.NewWriter(file)
+	w := &ndjsonWriter{file: file, buf: buf, enc: json.NewEncoder(buf)}
+	header := ndjsonHeaderRecord{
+		Type:          ndjsonHeader,
+		SchemaVersion: framework.ResultSchemaVersion,
+		Title:         cfg.Title,
+		SourceFile:    cfg.SourceFile,
+		TotalTokens:   totalTokensFromSettings(cfg.Settings),
+		Config:        configFromSettings(cfg.Settings),
+	}
+	header.Finder, _ = cfg.Settings["finder"].(string)
+	if err := w.enc.Encode(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("write ndjson header: %w", err)
+	}
+	return w, nil
+}
+
+// ndjsonWriter is the framework.GroupStreamWriter of NDJSONReportGenerator.
+type ndjsonWriter struct {
+	file   *os.File
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	groups int
+}
+
+func (w *ndjsonWriter) WriteGroup(g framework.CloneGroup) error {
+	if err := w.enc.Encode(ndjsonGroupRecord{Type: ndjsonGroup, CloneGroup: g}); err != nil {
+		return fmt.Errorf("write ndjson group: %w", err)
+	}
+	w.groups++
+	return nil
+}
+
+func (w *ndjsonWriter) Close(summary framework.StreamSummary) error {
+	// The trailer repeats only metadata not already in the header.
+	metadata := map[string]interface{}{}
+	for k, v := range summary.Metadata {
+		switch k {
+		case "source_file", "finder", "total_tokens", framework.StatsSettingsKey, framework.ConfigSettingsKey:
+		default:
+			metadata[k] = v
+		}
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	trailer := ndjsonTrailerRecord{Type: ndjsonTrailer, Groups: w.groups, Stats: summary.Statistics, Metadata: metadata}
+	if err := w.enc.Encode(trailer); err != nil {
+		w.file.Close()
+		return fmt.Errorf("write ndjson trailer: %w", err)
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("flush ndjson: %w", err)
+	}
+	return w.file.Close()
+}
@@ -11,76 +11,8 @@ import (
 	"github.com/PavelMkr/docline-new/internal/framework"
 )
 
-// HTMLReportGenerator implements framework.ReportGenerator for HTML output.
-type HTMLReportGenerator struct{}
-
-func (h *HTMLReportGenerator) Name() string {
-	return "html-report"
-}
-
-func (h *HTMLReportGenerator) Format() string {
-	return "html"
-}
-
-func (h *HTMLReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
-		return fmt.Errorf("create output dir: %w", err)
-	}
-
-	totalTokens := totalTokensFromSettings(cfg.Settings)
-	if totalTokens <= 0 {
-		totalTokens = maxEndPos(groups)
-	}
-	heat := buildHeatmap(groups, totalTokens, 120)
-
-	var sb strings.Builder
-	sb.WriteString("<!DOCTYPE html><html><head><meta charset=\"utf-8\">")
-	sb.WriteString("<title>" + htmlEscape(cfg.Title) + "</title>")
-	sb.WriteString("<style>body{font-family:sans-serif}table{border-collapse:collapse;width:100%}th,td{border:1px solid #ccc;padding:4px;font-size:14px}code{white-space:pre-wrap}.heatmap{display:flex;gap:1px;height:14px;align-items:stretch}.heat{flex:1 1 auto}</style>")
-	sb.WriteString("</head><body>")
-	sb.WriteString("<h1>" + htmlEscape(cfg.Title) + "</h1>")
-	if cfg.SourceFile != "" {
-		sb.WriteString("<p><b>Source:</b> " + htmlEscape(cfg.SourceFile) + "</p>")
-	}
-	sb.WriteString(fmt.Sprintf("<p><b>Total groups:</b> %d</p>", len(groups)))
-	if len(heat) > 0 && totalTokens > 0 {
-		sb.WriteString("<p><b>Heatmap:</b> (token coverage, bins=" + fmt.Sprint(len(heat)) + ", total_tokens=" + fmt.Sprint(totalTokens) + ")</p>")
-		sb.WriteString(renderHeatmapHTML(heat))
-	}
-
-	sb.WriteString("<table><thead><tr><th>#</th><th>Power</th><th>Archetype</th><th>Fragments</th></tr></thead><tbody>")
-	for i, g := range groups {
-		sb.WriteString("<tr>")
-		sb.WriteString(fmt.Sprintf("<td>%d</td>", i+1))
-		sb.WriteString(fmt.Sprintf("<td>%d</td>", g.Power))
-		sb.WriteString("<td><code>" + htmlEscape(g.Archetype) + "</code></td>")
-		sb.WriteString("<td><ol>")
-		for _, f := range g.Fragments {
-			prefix := ""
-			if ln1, ok1 := intFromMetadata(f.Metadata, "source_line_start"); ok1 && ln1 > 0 {
-				ln2, ok2 := intFromMetadata(f.Metadata, "source_line_end")
-				if ok2 && ln2 > 0 && ln2 != ln1 {
-					prefix = "L" + fmt.Sprint(ln1) + "-" + fmt.Sprint(ln2) + ": "
-				} else {
-					prefix = "L" + fmt.Sprint(ln1) + ": "
-				}
-			}
-			sb.WriteString("<li><code>" + htmlEscape(prefix+f.Content) + "</code></li>")
-		}
-		sb.WriteString("</ol></td>")
-		sb.WriteString("</tr>")
-	}
-	sb.WriteString("</tbody></table></body></html>")
-
-	return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
-}
-
-func htmlEscape(s string) string {
-	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
-	return r.Replace(s)
-}
-
-// JSONReportGenerator implements framework.ReportGenerator for JSON output.
+// JSONReportGenerator implements framework.ReportGenerator for JSON output:
+// a framework.ResultDocument that framework.LoadResult reads back.
 type JSONReportGenerator struct{}
 
 func (j *JSONReportGenerator) Name() string {
@@ -100,26 +32,19 @@ func (j *JSONReportGenerator) Generate(groups []framework.CloneGroup, cfg framew
 	if totalTokens <= 0 {
 		totalTokens = maxEndPos(groups)
 	}
-	heat := buildHeatmap(groups, totalTokens, 120)
-	stats := statsFromSettings(cfg.Settings)
-
-	payload := struct {
-		Title      string                       `json:"title"`
-		SourceFile string                       `json:"source_file"`
-		Settings   map[string]interface{}       `json:"settings,omitempty"`
-		Groups     []framework.CloneGroup       `json:"groups"`
-		Stats      framework.AnalysisStatistics `json:"stats,omitempty"`
-		Heatmap    []int                        `json:"heatmap,omitempty"`
-		TotalTokens int                         `json:"total_tokens,omitempty"`
-	}{
-		Title:      cfg.Title,
-		SourceFile: cfg.SourceFile,
-		Settings:   cfg.Settings,
+	result := &framework.AnalysisResult{
 		Groups:     groups,
-		Stats:      stats,
-		Heatmap:    heat,
-		TotalTokens: totalTokens,
+		Statistics: statsFromSettings(cfg.Settings),
+		Config:     configFromSettings(cfg.Settings),
+		Metadata:   cfg.Settings,
 	}
+	payload := framework.NewResultDocument(result)
+	payload.Title = cfg.Title
+	if payload.SourceFile == "" {
+		payload.SourceFile = cfg.SourceFile
+	}
+	payload.TotalTokens = totalTokens
+	payload.Heatmap = buildHeatmap(groups, totalTokens, 120)
 
 	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -129,24 +54,27 @@ func (j *JSONReportGenerator) Generate(groups []framework.CloneGroup, cfg framew
 	return os.WriteFile(outputPath, data, 0o644)
 }
 
-// CSVReportGenerator implements framework.ReportGenerator for CSV output
-// similar in spirit to the old WriteShortTermsCSV helper.
-type CSVReportGenerator struct {
+// ShortTermsCSVReportGenerator implements framework.ReportGenerator for a
+// list of short repeated terms, similar in spirit to the old
+// WriteShortTermsCSV helper: only groups whose first fragment is at most
+// MaxTokens long are written. Use FragmentTableReportGenerator ("csv") for a
+// complete export.
+type ShortTermsCSVReportGenerator struct {
 	// MaxTokens limits fragment length to be included; 0 means no limit.
 	MaxTokens int
 	// MinOccurs specifies minimal number of fragments per group.
 	MinOccurs int
 }
 
-func (c *CSVReportGenerator) Name() string {
-	return "csv-report"
+func (c *ShortTermsCSVReportGenerator) Name() string {
+	return "short-terms-csv-report"
 }
 
-func (c *CSVReportGenerator) Format() string {
-	return "csv"
+func (c *ShortTermsCSVReportGenerator) Format() string {
+	return "short-terms-csv"
 }
 
-func (c *CSVReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+func (c *ShortTermsCSVReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
 		return fmt.Errorf("create output dir: %w", err)
 	}
@@ -225,7 +153,7 @@ func statsFromSettings(settings map[string]interface{}) framework.AnalysisStatis
 	if settings == nil {
 		return framework.AnalysisStatistics{}
 	}
-	v, ok := settings["stats"]
+	v, ok := settings[framework.StatsSettingsKey]
 	if !ok || v == nil {
 		return framework.AnalysisStatistics{}
 	}
@@ -238,11 +166,54 @@ func statsFromSettings(settings map[string]interface{}) framework.AnalysisStatis
 		}
 		return *vv
 	default:
-		// Unknown representation (e.g. after JSON roundtrip). Keep zero value.
+		// Settings built outside Framework.GenerateReport; results loaded
+		// with framework.LoadResult carry typed statistics.
 		return framework.AnalysisStatistics{}
 	}
 }
 
+func configFromSettings(settings map[string]interface{}) framework.CloneFinderConfig {
+	cfg, _ := settings[framework.ConfigSettingsKey].(framework.CloneFinderConfig)
+	return cfg
+}
+
+// groupBadges returns short status labels for a group: baseline acceptance
+// and, for rendered comparisons, the diff status.
+func groupBadges(g framework.CloneGroup) []string {
+	var badges []string
+	if framework.IsSuppressed(g) {
+		badge := "accepted"
+		if reason, _ := g.Metadata[framework.SuppressionReasonMetadataKey].(string); reason != "" {
+			badge += ": " + reason
+		}
+		badges = append(badges, badge)
+	}
+	if status, _ := g.Metadata[framework.DiffStatusMetadataKey].(string); status != "" {
+		oldPower, _ := intFromMetadata(g.Metadata, framework.OldPowerMetadataKey)
+		newPower, _ := intFromMetadata(g.Metadata, framework.NewPowerMetadataKey)
+		badges = append(badges, fmt.Sprintf("%s (%d → %d)", status, oldPower, newPower))
+	}
+	return badges
+}
+
+func staleEntriesFromSettings(settings map[string]interface{}) []framework.StaleBaselineEntry {
+	if settings == nil {
+		return nil
+	}
+	stale, _ := settings["stale_baseline_entries"].([]framework.StaleBaselineEntry)
+	return stale
+}
+
+func countSuppressed(groups []framework.CloneGroup) int {
+	n := 0
+	for _, g := range groups {
+		if framework.IsSuppressed(g) {
+			n++
+		}
+	}
+	return n
+}
+
 func maxEndPos(groups []framework.CloneGroup) int {
 	maxEnd := 0
 	for _, g := range groups {
@@ -320,29 +291,6 @@ func overlapLen(aStart, aEnd, bStart, bEnd int) int {
 	return e - s
 }
 
-func renderHeatmapHTML(heat []int) string {
-	maxV := 0
-	for _, v := range heat {
-		if v > maxV {
-			maxV = v
-		}
-	}
-	if maxV == 0 {
-		return "<div class=\"heatmap\"></div>"
-	}
-
-	var sb strings.Builder
-	sb.WriteString("<div class=\"heatmap\" aria-label=\"heatmap\">")
-	for _, v := range heat {
-		intensity := float64(v) / float64(maxV) // 0..1
-		// Blue-ish gradient; keep background visible even at 0.
-		alpha := 0.05 + 0.95*intensity
-		sb.WriteString("<div class=\"heat\" style=\"background-color: rgba(30, 136, 229, " + fmt.Sprintf("%.3f", alpha) + ")\"></div>")
-	}
-	sb.WriteString("</div>")
-	return sb.String()
-}
-
 func intFromMetadata(m map[string]interface{}, key string) (int, bool) {
 	if m == nil {
 		return 0, false
@@ -367,7 +315,7 @@ func intFromMetadata(m map[string]interface{}, key string) (int, bool) {
 	}
 }
 
-// RegisterReportGenerators registers built-in HTML/JSON/CSV report generators.
+// RegisterReportGenerators registers the built-in report generators.
 func RegisterReportGenerators(reg *framework.PluginRegistry) error {
 	if err := reg.RegisterReportGenerator(&HTMLReportGenerator{}); err != nil {
 		return fmt.Errorf("register html report generator: %w", err)
@@ -375,8 +323,41 @@ func RegisterReportGenerators(reg *framework.PluginRegistry) error {
 	if err := reg.RegisterReportGenerator(&JSONReportGenerator{}); err != nil {
 		return fmt.Errorf("register json report generator: %w", err)
 	}
-	if err := reg.RegisterReportGenerator(&CSVReportGenerator{MaxTokens: 3, MinOccurs: 2}); err != nil {
+	if err := reg.RegisterReportGenerator(&FragmentTableReportGenerator{FormatName: "csv", Delimiter: ','}); err != nil {
 		return fmt.Errorf("register csv report generator: %w", err)
 	}
+	if err := reg.RegisterReportGenerator(&FragmentTableReportGenerator{FormatName: "tsv", Delimiter: '\t'}); err != nil {
+		return fmt.Errorf("register tsv report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&ShortTermsCSVReportGenerator{MaxTokens: 3, MinOccurs: 2}); err != nil {
+		return fmt.Errorf("register short-terms csv report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&JUnitReportGenerator{}); err != nil {
+		return fmt.Errorf("register junit report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&SARIFReportGenerator{}); err != nil {
+		return fmt.Errorf("register sarif report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&MarkdownReportGenerator{}); err != nil {
+		return fmt.Errorf("register markdown report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&InteractiveHTMLReportGenerator{}); err != nil {
+		return fmt.Errorf("register interactive html report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&GlossaryReportGenerator{}); err != nil {
+		return fmt.Errorf("register glossary report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&DOTReportGenerator{}); err != nil {
+		return fmt.Errorf("register dot report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&GraphMLReportGenerator{}); err != nil {
+		return fmt.Errorf("register graphml report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&SVGReportGenerator{}); err != nil {
+		return fmt.Errorf("register svg report generator: %w", err)
+	}
+	if err := reg.RegisterReportGenerator(&NDJSONReportGenerator{}); err != nil {
+		return fmt.Errorf("register ndjson report generator: %w", err)
+	}
 	return nil
 }
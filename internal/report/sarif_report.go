@@ -0,0 +1,239 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+const (
+	sarifVersion   = "2.1.0"
+	sarifSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI   = "https://github.com/PavelMkr/docline-new"
+)
+
+// SARIFReportGenerator implements framework.ReportGenerator for SARIF 2.1.0,
+// the format understood by code-scanning UIs and many IDEs.
+//
+// Every clone fragment becomes one result whose relatedLocations point to the
+// other fragments of its group. Rule IDs are derived from the finder name
+// ("docline/<finder>") so they stay stable across runs.
+type SARIFReportGenerator struct{}
+
+func (s *SARIFReportGenerator) Name() string {
+	return "sarif-report"
+}
+
+func (s *SARIFReportGenerator) Format() string {
+	return "sarif"
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	FullDescription      sarifMessage      `json:"fullDescription"`
+	DefaultConfiguration sarifRuleDefaults `json:"defaultConfiguration"`
+}
+
+type sarifRuleDefaults struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	RelatedLocations    []sarifLocation        `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Suppressions        []sarifSuppression     `json:"suppressions,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+func (s *SARIFReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	finder, _ := cfg.Settings["finder"].(string)
+	if finder == "" {
+		finder = "unknown"
+	}
+	rule := sarifRule{
+		ID:               sarifRuleID(finder),
+		Name:             "DuplicatedText",
+		ShortDescription: sarifMessage{Text: "Duplicated documentation text"},
+		FullDescription: sarifMessage{Text: fmt.Sprintf(
+			"Text fragment repeated elsewhere in the documentation, detected by the %q clone finder.", finder)},
+		DefaultConfiguration: sarifRuleDefaults{Level: "warning"},
+	}
+
+	results := make([]sarifResult, 0)
+	for gi, g := range groups {
+		groupID, _ := g.Metadata[framework.GroupIDMetadataKey].(string)
+		if groupID == "" {
+			groupID = framework.GroupID(g)
+		}
+		for fi, f := range g.Fragments {
+			loc := sarifPhysical(f, cfg.SourceFile)
+			res := sarifResult{
+				RuleID:              rule.ID,
+				RuleIndex:           0,
+				Level:               "warning",
+				Message:             sarifMessage{Text: sarifResultMessage(g, fi)},
+				Locations:           []sarifLocation{{PhysicalLocation: loc}},
+				PartialFingerprints: map[string]string{"doclineFragment/v1": sarifFingerprint(groupID, loc, fi)},
+				Properties: map[string]interface{}{
+					"groupId":    groupID,
+					"groupIndex": gi + 1,
+					"power":      g.Power,
+					"startToken": f.StartPos,
+					"endToken":   f.EndPos,
+				},
+			}
+			for oi, other := range g.Fragments {
+				if oi == fi {
+					continue
+				}
+				id := oi
+				res.RelatedLocations = append(res.RelatedLocations, sarifLocation{
+					ID:               &id,
+					PhysicalLocation: sarifPhysical(other, cfg.SourceFile),
+					Message:          &sarifMessage{Text: "Other occurrence"},
+				})
+			}
+			if framework.IsSuppressed(g) {
+				reason, _ := g.Metadata[framework.SuppressionReasonMetadataKey].(string)
+				res.Suppressions = []sarifSuppression{{Kind: "external", Justification: reason}}
+			}
+			results = append(results, res)
+		}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "docline",
+				InformationURI: sarifToolURI,
+				Rules:          []sarifRule{rule},
+			}},
+			Results: results,
+		}},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal sarif: %w", err)
+	}
+	return os.WriteFile(outputPath, data, 0o644)
+}
+
+// sarifRuleID returns the stable rule ID for a finder.
+func sarifRuleID(finder string) string {
+	return "docline/" + finder
+}
+
+// sarifResultMessage describes fragment fi of g, linking to the other
+// occurrences with SARIF embedded links ("[text](relatedLocationId)").
+func sarifResultMessage(g framework.CloneGroup, fi int) string {
+	var links []string
+	for oi, other := range g.Fragments {
+		if oi == fi {
+			continue
+		}
+		label := fmt.Sprintf("tokens %d-%d", other.StartPos, other.EndPos)
+		if ln, ok := intFromMetadata(other.Metadata, framework.SourceLineStartMetadataKey); ok && ln > 0 {
+			label = fmt.Sprintf("line %d", ln)
+		}
+		links = append(links, fmt.Sprintf("[%s](%d)", label, oi))
+	}
+	msg := fmt.Sprintf("Text duplicated %d times", len(g.Fragments))
+	if len(links) > 0 {
+		msg += "; see " + strings.Join(links, ", ")
+	}
+	return msg + "."
+}
+
+// sarifFingerprint identifies one occurrence of a group: the group ID plus
+// the fragment's file and start line, or its ordinal in the group when the
+// source line is unknown.
+func sarifFingerprint(groupID string, loc sarifPhysicalLocation, fi int) string {
+	if loc.Region != nil {
+		return fmt.Sprintf("%s:%s:%d", groupID, loc.ArtifactLocation.URI, loc.Region.StartLine)
+	}
+	return fmt.Sprintf("%s:%s:#%d", groupID, loc.ArtifactLocation.URI, fi+1)
+}
+
+// sarifPhysical builds the physical location of a fragment. The fragment's
+// own "source_file" metadata wins over the report source file.
+func sarifPhysical(f framework.TextFragment, sourceFile string) sarifPhysicalLocation {
+	if s, ok := f.Metadata[framework.SourceFileMetadataKey].(string); ok && s != "" {
+		sourceFile = s
+	}
+	loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(sourceFile)}}
+	if start, ok := intFromMetadata(f.Metadata, framework.SourceLineStartMetadataKey); ok && start > 0 {
+		loc.Region = &sarifRegion{StartLine: start}
+		if end, ok := intFromMetadata(f.Metadata, framework.SourceLineEndMetadataKey); ok && end >= start {
+			loc.Region.EndLine = end
+		}
+	}
+	return loc
+}
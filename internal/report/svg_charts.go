@@ -0,0 +1,312 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// SVGChartParam is the ReportConfig.CustomParams key selecting the charts of
+// an SVG report: "heatmap", "lengths", "power" or "all" (default).
+const SVGChartParam = "chart"
+
+// Chart kinds, also used as SVGChart.Kind.
+const (
+	ChartHeatmap = "heatmap"
+	ChartLengths = "lengths"
+	ChartPower   = "power"
+)
+
+const (
+	svgChartWidth    = 720
+	svgHeatmapHeight = 64
+	svgBarHeight     = 200
+)
+
+// SVGChart is a standalone SVG document with one duplication chart.
+type SVGChart struct {
+	Kind  string // ChartHeatmap, ChartLengths or ChartPower
+	Name  string // File-name friendly identifier, e.g. "heatmap-guide"
+	Title string
+	SVG   string // Complete <svg> element with namespace
+}
+
+// duplicationCharts renders a token coverage heatmap per file, a histogram of
+// fragment lengths and the distribution of group power.
+func duplicationCharts(groups []framework.CloneGroup, cfg framework.ReportConfig) []SVGChart {
+	var charts []SVGChart
+
+	byFile := map[string][]framework.TextFragment{}
+	for _, g := range groups {
+		for _, f := range g.Fragments {
+			file := fragmentFile(f, cfg.SourceFile)
+			byFile[file] = append(byFile[file], f)
+		}
+	}
+	files := make([]string, 0, len(byFile))
+	for f := range byFile {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	mainFile := fragmentFile(framework.TextFragment{}, cfg.SourceFile)
+	for _, file := range files {
+		fileGroups := []framework.CloneGroup{{Fragments: byFile[file]}}
+		totalTokens := maxEndPos(fileGroups)
+		if file == mainFile {
+			if n := totalTokensFromSettings(cfg.Settings); n > totalTokens {
+				totalTokens = n
+			}
+		}
+		heat := buildHeatmap(fileGroups, totalTokens, 120)
+		if len(heat) == 0 {
+			continue
+		}
+		title := "Clone coverage: " + filepath.Base(file)
+		charts = append(charts, SVGChart{
+			Kind:  ChartHeatmap,
+			Name:  "heatmap-" + svgSlug(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))),
+			Title: title,
+			SVG:   renderHeatmapSVG(title, heat, totalTokens),
+		})
+	}
+
+	if len(groups) == 0 {
+		return charts
+	}
+	labels, values := fragmentLengthHistogram(groups)
+	charts = append(charts, SVGChart{
+		Kind:  ChartLengths,
+		Name:  ChartLengths,
+		Title: "Fragments by length (tokens)",
+		SVG:   renderBarChartSVG("Fragments by length (tokens)", labels, values),
+	})
+	labels, values = groupPowerDistribution(groups)
+	charts = append(charts, SVGChart{
+		Kind:  ChartPower,
+		Name:  ChartPower,
+		Title: "Groups by power (fragments per group)",
+		SVG:   renderBarChartSVG("Groups by power (fragments per group)", labels, values),
+	})
+	return charts
+}
+
+// fragmentFile returns the file of a fragment, defaulting to sourceFile.
+func fragmentFile(f framework.TextFragment, sourceFile string) string {
+	if s, ok := f.Metadata[framework.SourceFileMetadataKey].(string); ok && s != "" {
+		return s
+	}
+	if sourceFile == "" {
+		return "document"
+	}
+	return sourceFile
+}
+
+// fragmentLengthHistogram counts fragments in power-of-two token length
+// buckets: 1, 2-3, 4-7, ...
+func fragmentLengthHistogram(groups []framework.CloneGroup) ([]string, []int) {
+	var counts []int
+	for _, g := range groups {
+		for _, f := range g.Fragments {
+			n := f.EndPos - f.StartPos
+			if n <= 0 {
+				n = len(strings.Fields(f.Content))
+			}
+			if n <= 0 {
+				continue
+			}
+			b := 0
+			for 1<<(b+1) <= n {
+				b++
+			}
+			for len(counts) <= b {
+				counts = append(counts, 0)
+			}
+			counts[b]++
+		}
+	}
+	labels := make([]string, len(counts))
+	for b := range counts {
+		lo, hi := 1<<b, 1<<(b+1)-1
+		if lo == hi {
+			labels[b] = strconv.Itoa(lo)
+		} else {
+			labels[b] = fmt.Sprintf("%d-%d", lo, hi)
+		}
+	}
+	return labels, counts
+}
+
+// groupPowerDistribution counts groups per power from the smallest power
+// present up to 9, with a final "10+" bucket.
+func groupPowerDistribution(groups []framework.CloneGroup) ([]string, []int) {
+	lo, hi := 10, 0
+	counts := map[int]int{}
+	for _, g := range groups {
+		p := g.Power
+		if p <= 0 {
+			p = len(g.Fragments)
+		}
+		if p > 10 {
+			p = 10
+		}
+		counts[p]++
+		if p < lo {
+			lo = p
+		}
+		if p > hi {
+			hi = p
+		}
+	}
+	var labels []string
+	var values []int
+	for p := lo; p <= hi; p++ {
+		label := strconv.Itoa(p)
+		if p == 10 {
+			label = "10+"
+		}
+		labels = append(labels, label)
+		values = append(values, counts[p])
+	}
+	return labels, values
+}
+
+// renderHeatmapSVG draws heatmap bins as a strip of rectangles whose opacity
+// follows the bin value.
+func renderHeatmapSVG(title string, heat []int, totalTokens int) string {
+	maxV := 0
+	for _, v := range heat {
+		if v > maxV {
+			maxV = v
+		}
+	}
+
+	var sb strings.Builder
+	svgOpen(&sb, svgChartWidth, svgHeatmapHeight, title)
+	barW := float64(svgChartWidth-20) / float64(len(heat))
+	sb.WriteString(`<rect x="10" y="24" width="700" height="22" fill="#f5f5f5"/>` + "\n")
+	for i, v := range heat {
+		if v == 0 {
+			continue
+		}
+		start := (i * totalTokens) / len(heat)
+		end := ((i + 1) * totalTokens) / len(heat)
+		fmt.Fprintf(&sb, `<rect x="%.2f" y="24" width="%.2f" height="22" fill="#1e88e5" fill-opacity="%.3f"><title>tokens %d-%d: %d</title></rect>`+"\n",
+			10+float64(i)*barW, barW, 0.05+0.95*float64(v)/float64(maxV), start, end, v)
+	}
+	fmt.Fprintf(&sb, `<text x="10" y="60" font-size="10">0</text><text x="710" y="60" font-size="10" text-anchor="end">%d tokens</text>`+"\n", totalTokens)
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// renderBarChartSVG draws a vertical bar chart with value labels.
+func renderBarChartSVG(title string, labels []string, values []int) string {
+	maxV := 1
+	for _, v := range values {
+		if v > maxV {
+			maxV = v
+		}
+	}
+
+	const top, bottom, plotH = 30, 24, svgBarHeight - 30 - 24
+	var sb strings.Builder
+	svgOpen(&sb, svgChartWidth, svgBarHeight, title)
+	fmt.Fprintf(&sb, `<line x1="10" y1="%d" x2="710" y2="%d" stroke="#999"/>`+"\n", top+plotH, top+plotH)
+	if len(values) > 0 {
+		slot := float64(svgChartWidth-20) / float64(len(values))
+		for i, v := range values {
+			h := float64(plotH) * float64(v) / float64(maxV)
+			x := 10 + float64(i)*slot
+			fmt.Fprintf(&sb, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="#1e88e5"/>`+"\n",
+				x+slot*0.1, float64(top+plotH)-h, slot*0.8, h)
+			fmt.Fprintf(&sb, `<text x="%.2f" y="%.2f" font-size="10" text-anchor="middle">%d</text>`+"\n",
+				x+slot/2, float64(top+plotH)-h-3, v)
+			fmt.Fprintf(&sb, `<text x="%.2f" y="%d" font-size="10" text-anchor="middle">%s</text>`+"\n",
+				x+slot/2, svgBarHeight-bottom/2, html.EscapeString(labels[i]))
+		}
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// svgOpen writes the root element and the chart title.
+func svgOpen(sb *strings.Builder, width, height int, title string) {
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" role="img" aria-label="%s">`+"\n",
+		width, height, width, height, html.EscapeString(title))
+	fmt.Fprintf(sb, `<text x="10" y="16" font-size="13" font-weight="bold">%s</text>`+"\n", html.EscapeString(title))
+}
+
+// svgSlug keeps letters, digits, '-' and '_' of s for use in file names.
+func svgSlug(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// SVGReportGenerator implements framework.ReportGenerator for a standalone
+// SVG image stacking the duplication charts, e.g. for embedding in wikis.
+// ReportConfig.CustomParams["chart"] restricts it to one kind of chart.
+type SVGReportGenerator struct{}
+
+func (s *SVGReportGenerator) Name() string {
+	return "svg-report"
+}
+
+func (s *SVGReportGenerator) Format() string {
+	return "svg"
+}
+
+func (s *SVGReportGenerator) Generate(groups []framework.CloneGroup, cfg framework.ReportConfig, outputPath string) error {
+	kind, _ := cfg.CustomParams[SVGChartParam].(string)
+	switch kind {
+	case "", "all":
+		kind = ""
+	case ChartHeatmap, ChartLengths, ChartPower:
+	default:
+		return fmt.Errorf("unknown svg chart %q: want heatmap, lengths, power or all", kind)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+
+	var body strings.Builder
+	y := 0
+	for _, c := range duplicationCharts(groups, cfg) {
+		if kind != "" && c.Kind != kind {
+			continue
+		}
+		height := svgBarHeight
+		if c.Kind == ChartHeatmap {
+			height = svgHeatmapHeight
+		}
+		// Each chart is a nested <svg>; the group moves it below the previous one.
+		fmt.Fprintf(&body, "<g transform=\"translate(0 %d)\">\n%s</g>\n", y, c.SVG)
+		y += height + 10
+	}
+	if y == 0 {
+		var sb strings.Builder
+		svgOpen(&sb, svgChartWidth, 40, "No clone groups found")
+		sb.WriteString("</svg>\n")
+		return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		svgChartWidth, y, svgChartWidth, y)
+	sb.WriteString(body.String())
+	sb.WriteString("</svg>\n")
+	return os.WriteFile(outputPath, []byte(sb.String()), 0o644)
+}
@@ -0,0 +1,91 @@
+package internal
+
+import "strings"
+
+// Word diff operations.
+const (
+	diffEqual  = "eq"
+	diffDelete = "del" // Present in the archetype only
+	diffInsert = "ins" // Present in the fragment only
+)
+
+// maxWordDiffCells bounds the LCS table size; larger inputs are reported as a
+// whole-text replacement instead of being diffed word by word.
+const maxWordDiffCells = 4_000_000
+
+// diffSegment is a run of words sharing the same diff operation.
+type diffSegment struct {
+	Op   string
+	Text string
+}
+
+// wordDiff computes a word-level diff that turns base into other using the
+// longest common subsequence of their whitespace-separated tokens.
+func wordDiff(base, other string) []diffSegment {
+	a := strings.Fields(base)
+	b := strings.Fields(other)
+	if len(a)*len(b) > maxWordDiffCells {
+		return mergeDiffSegments([]diffSegment{
+			{Op: diffDelete, Text: strings.Join(a, " ")},
+			{Op: diffInsert, Text: strings.Join(b, " ")},
+		})
+	}
+
+	// lcs[i][j] = LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var segs []diffSegment
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			segs = append(segs, diffSegment{Op: diffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			segs = append(segs, diffSegment{Op: diffDelete, Text: a[i]})
+			i++
+		default:
+			segs = append(segs, diffSegment{Op: diffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		segs = append(segs, diffSegment{Op: diffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		segs = append(segs, diffSegment{Op: diffInsert, Text: b[j]})
+	}
+	return mergeDiffSegments(segs)
+}
+
+// mergeDiffSegments joins adjacent segments with the same operation and drops
+// empty ones.
+func mergeDiffSegments(segs []diffSegment) []diffSegment {
+	var out []diffSegment
+	for _, s := range segs {
+		if s.Text == "" {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Op == s.Op {
+			out[n-1].Text += " " + s.Text
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+// Job states.
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// Errors returned by jobQueue.submit.
+var (
+	errQueueFull = errors.New("job queue is full")
+	errClosed    = errors.New("server is shutting down")
+)
+
+// job is one uploaded document and its analysis.
+type job struct {
+	id       string
+	fileName string
+	ext      string
+	finder   string
+	config   docline.CloneFinderConfig
+	data     []byte // Uploaded document; released when the job starts
+
+	mu       sync.Mutex
+	status   string
+	message  string
+	created  time.Time
+	finished time.Time
+	result   *docline.AnalysisResult
+}
+
+// snapshot returns the mutable state of the job under its lock.
+func (j *job) snapshot() (status, message string, finished time.Time, result *docline.AnalysisResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status, j.message, j.finished, j.result
+}
+
+// jobQueue runs jobs on a fixed number of workers and keeps the most recent
+// finished jobs for status requests and report downloads.
+type jobQueue struct {
+	d      *docline.Docline
+	retain int
+
+	mu    sync.Mutex
+	jobs  map[string]*job
+	order []string // Job IDs, oldest first
+
+	pending chan *job
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+func newJobQueue(d *docline.Docline, workers, queueSize, retain int) *jobQueue {
+	q := &jobQueue{
+		d:       d,
+		retain:  retain,
+		jobs:    map[string]*job{},
+		pending: make(chan *job, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// submit queues j, failing with errQueueFull instead of blocking.
+func (q *jobQueue) submit(j *job) error {
+	id, err := newJobID()
+	if err != nil {
+		return err
+	}
+	j.id = id
+	j.status = StatusQueued
+	j.created = time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return errClosed
+	}
+	select {
+	case q.pending <- j:
+	default:
+		return errQueueFull
+	}
+	q.jobs[j.id] = j
+	q.order = append(q.order, j.id)
+	q.evictLocked()
+	return nil
+}
+
+func (q *jobQueue) get(id string) (*job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	return j, ok
+}
+
+func (q *jobQueue) remove(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.jobs[id]; !ok {
+		return false
+	}
+	delete(q.jobs, id)
+	for i, oid := range q.order {
+		if oid == id {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// evictLocked drops the oldest finished jobs beyond the retention limit.
+// Queued and running jobs are never dropped.
+func (q *jobQueue) evictLocked() {
+	excess := len(q.order) - q.retain
+	if q.retain <= 0 || excess <= 0 {
+		return
+	}
+	kept := q.order[:0]
+	for _, id := range q.order {
+		if excess > 0 {
+			if status, _, _, _ := q.jobs[id].snapshot(); status == StatusDone || status == StatusFailed {
+				delete(q.jobs, id)
+				excess--
+				continue
+			}
+		}
+		kept = append(kept, id)
+	}
+	q.order = kept
+}
+
+// close stops accepting jobs and waits for queued ones to finish.
+func (q *jobQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.pending)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *jobQueue) work() {
+	defer q.wg.Done()
+	for j := range q.pending {
+		q.run(j)
+	}
+}
+
+func (q *jobQueue) run(j *job) {
+	j.mu.Lock()
+	j.status = StatusRunning
+	data := j.data
+	j.data = nil
+	j.mu.Unlock()
+
+	result, err := q.d.AnalyzeReaderWithConfig(bytes.NewReader(data), j.ext, j.finder, j.config)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finished = time.Now()
+	if err != nil {
+		j.status = StatusFailed
+		j.message = err.Error()
+		return
+	}
+	if result.Metadata == nil {
+		result.Metadata = map[string]interface{}{}
+	}
+	result.Metadata["source_file"] = j.fileName
+	j.status = StatusDone
+	j.message = fmt.Sprintf("%d clone groups found", result.Statistics.TotalGroups)
+	j.result = result
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate job id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
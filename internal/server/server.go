@@ -0,0 +1,368 @@
+// Package server implements "docline serve": an HTTP API that analyses
+// uploaded documents asynchronously and renders the results in any
+// registered report format.
+//
+// Endpoints:
+//
+//	POST   /api/jobs                    upload a document (multipart field "file") and queue its analysis
+//	GET    /api/jobs/{id}               job status and statistics
+//	GET    /api/jobs/{id}/report/{fmt}  download the report in format fmt (query values are report parameters)
+//	DELETE /api/jobs/{id}               forget a job
+//	GET    /api/formats                 registered report formats
+//	GET    /api/finders                 registered clone finders and their parameters
+//	GET    /healthz                     liveness probe
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	rep "github.com/PavelMkr/docline-new/internal/report"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+// Config configures a Server. Zero values select the defaults.
+type Config struct {
+	Workers        int    // Concurrent analyses (default 2)
+	QueueSize      int    // Jobs that may wait for a worker before uploads are refused (default 16)
+	Retain         int    // Finished jobs kept for status and download (default 100)
+	MaxUploadBytes int64  // Largest accepted document (default 32 MiB)
+	DefaultFinder  string // Finder used when the request names none (default "automatic")
+	TempDir        string // Directory for rendering reports (default os.TempDir())
+}
+
+// Server serves the docline HTTP API.
+type Server struct {
+	d     *docline.Docline
+	cfg   Config
+	queue *jobQueue
+	mux   *http.ServeMux
+}
+
+// New creates a server analysing documents with d and starts its workers.
+// Call Close to stop them.
+func New(d *docline.Docline, cfg Config) *Server {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 2
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 16
+	}
+	if cfg.Retain <= 0 {
+		cfg.Retain = 100
+	}
+	if cfg.MaxUploadBytes <= 0 {
+		cfg.MaxUploadBytes = 32 << 20
+	}
+	if cfg.DefaultFinder == "" {
+		cfg.DefaultFinder = "automatic"
+	}
+
+	s := &Server{
+		d:     d,
+		cfg:   cfg,
+		queue: newJobQueue(d, cfg.Workers, cfg.QueueSize, cfg.Retain),
+		mux:   http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /api/jobs", s.handleSubmit)
+	s.mux.HandleFunc("GET /api/jobs/{id}", s.handleStatus)
+	s.mux.HandleFunc("DELETE /api/jobs/{id}", s.handleDelete)
+	s.mux.HandleFunc("GET /api/jobs/{id}/report/{format}", s.handleReport)
+	s.mux.HandleFunc("GET /api/formats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.d.ReportFormats())
+	})
+	s.mux.HandleFunc("GET /api/finders", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.d.CloneFinders())
+	})
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close refuses new uploads and waits for queued analyses to finish.
+func (s *Server) Close() {
+	s.queue.close()
+}
+
+// JobResponse is the JSON body of job responses. Status, message and
+// results_file keep the field names of the former web frontend.
+type JobResponse struct {
+	ID          string                      `json:"id,omitempty"`
+	Status      string                      `json:"status"` // queued, running, done, failed or error
+	Message     string                      `json:"message,omitempty"`
+	File        string                      `json:"file,omitempty"`
+	Finder      string                      `json:"finder,omitempty"`
+	CreatedAt   *time.Time                  `json:"created_at,omitempty"`
+	FinishedAt  *time.Time                  `json:"finished_at,omitempty"`
+	Stats       *docline.AnalysisStatistics `json:"stats,omitempty"`
+	ResultsFile string                      `json:"results_file,omitempty"` // URL of the JSON result once done
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.cfg.MaxUploadBytes)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("document exceeds %d bytes", s.cfg.MaxUploadBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, "expected multipart/form-data: "+err.Error())
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, `missing "file" field`)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "read upload: "+err.Error())
+		return
+	}
+
+	j := &job{
+		fileName: filepath.Base(header.Filename),
+		ext:      filepath.Ext(header.Filename),
+		finder:   r.FormValue("finder"),
+		data:     data,
+	}
+	if j.finder == "" {
+		j.finder = s.cfg.DefaultFinder
+	}
+	if j.config, err = finderConfigFromForm(r); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.d.ValidateFinderParams(j.finder, j.config.CustomParams); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if calc := j.config.SimilarityCalc; calc != "" && !slices.Contains(s.d.SimilarityCalculators(), calc) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown similarity calculator %q", calc))
+		return
+	}
+
+	switch err := s.queue.submit(j); {
+	case errors.Is(err, errQueueFull), errors.Is(err, errClosed):
+		w.Header().Set("Retry-After", "5")
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/jobs/"+j.id)
+	writeJSON(w, http.StatusAccepted, jobResponse(j))
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.queue.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, jobResponse(j))
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.queue.remove(r.PathValue("id")) {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.queue.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	status, message, _, result := j.snapshot()
+	switch status {
+	case StatusFailed:
+		writeError(w, http.StatusConflict, "analysis failed: "+message)
+		return
+	case StatusQueued, StatusRunning:
+		writeError(w, http.StatusConflict, "analysis not finished yet")
+		return
+	}
+
+	format := r.PathValue("format")
+	if !slices.Contains(s.d.ReportFormats(), format) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown report format %q", format))
+		return
+	}
+	params := map[string]interface{}{}
+	for key, values := range r.URL.Query() {
+		if key == rep.HTMLTemplateDirParam {
+			// Templates are read from the server's file system.
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("report parameter %q is not available over HTTP", key))
+			return
+		}
+		params[key] = parseParamValue(values[len(values)-1])
+	}
+
+	// Reports may write sidecar files (e.g. Markdown charts), so every
+	// download renders into its own directory.
+	dir, err := os.MkdirTemp(s.cfg.TempDir, "docline-report-")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	ext := docline.ReportFileExtension(format)
+	name := strings.TrimSuffix(j.fileName, j.ext)
+	if name == "" {
+		name = "report"
+	}
+	outPath := filepath.Join(dir, name+"."+ext)
+	if err := s.d.GenerateReportWithParams(result, format, outPath, params); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	f, err := os.Open(outPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", contentType(ext))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(outPath)}))
+	io.Copy(w, f)
+}
+
+func jobResponse(j *job) JobResponse {
+	status, message, finished, result := j.snapshot()
+	created := j.created
+	resp := JobResponse{
+		ID:        j.id,
+		Status:    status,
+		Message:   message,
+		File:      j.fileName,
+		Finder:    j.finder,
+		CreatedAt: &created,
+	}
+	if !finished.IsZero() {
+		resp.FinishedAt = &finished
+	}
+	if result != nil {
+		stats := result.Statistics
+		resp.Stats = &stats
+		resp.ResultsFile = "/api/jobs/" + j.id + "/report/json"
+	}
+	return resp
+}
+
+// finderConfigFromForm reads the finder settings of an upload: min_length,
+// max_length, min_power, similarity, similarity_calc and repeatable
+// param=key=value fields.
+func finderConfigFromForm(r *http.Request) (docline.CloneFinderConfig, error) {
+	var cfg docline.CloneFinderConfig
+	ints := []struct {
+		field string
+		dst   *int
+	}{
+		{"min_length", &cfg.MinCloneLength},
+		{"max_length", &cfg.MaxCloneLength},
+		{"min_power", &cfg.MinGroupPower},
+	}
+	for _, f := range ints {
+		if v := r.FormValue(f.field); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return cfg, fmt.Errorf("%s must be a non-negative integer, got %q", f.field, v)
+			}
+			*f.dst = n
+		}
+	}
+	if v := r.FormValue("similarity"); v != "" {
+		x, err := strconv.ParseFloat(v, 64)
+		if err != nil || x < 0 || x > 1 {
+			return cfg, fmt.Errorf("similarity must be within 0.0-1.0, got %q", v)
+		}
+		cfg.SimilarityThreshold = x
+	}
+	cfg.SimilarityCalc = r.FormValue("similarity_calc")
+	for _, p := range r.Form["param"] {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return cfg, fmt.Errorf("param must be key=value, got %q", p)
+		}
+		if cfg.CustomParams == nil {
+			cfg.CustomParams = map[string]interface{}{}
+		}
+		cfg.CustomParams[strings.TrimSpace(key)] = parseParamValue(strings.TrimSpace(value))
+	}
+	return cfg, nil
+}
+
+// parseParamValue converts parameter values like the CLI's -param flag:
+// bool, int or float64 when they parse as such, string otherwise.
+func parseParamValue(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// reportContentTypes covers report extensions unknown to the mime package.
+var reportContentTypes = map[string]string{
+	"md":      "text/markdown; charset=utf-8",
+	"ndjson":  "application/x-ndjson",
+	"sarif":   "application/sarif+json",
+	"dot":     "text/vnd.graphviz; charset=utf-8",
+	"graphml": "application/xml",
+	"tsv":     "text/tab-separated-values; charset=utf-8",
+}
+
+func contentType(ext string) string {
+	if ct, ok := reportContentTypes[ext]; ok {
+		return ct
+	}
+	if ct := mime.TypeByExtension("." + ext); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, JobResponse{Status: "error", Message: message})
+}
@@ -0,0 +1,184 @@
+// Package watch detects changes of files by polling their size and
+// modification time, so it works on every platform and file system
+// (including network mounts and containers without inotify).
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Options configure a Watcher. Zero values select the defaults.
+type Options struct {
+	Interval time.Duration          // Time between polls (default 1s)
+	Debounce time.Duration          // Quiet time after the last change before it is reported (default 500ms, negative: none)
+	Match    func(path string) bool // Files of watched directories to include (default: all)
+}
+
+// Change lists the files that changed since the previous report.
+type Change struct {
+	Modified []string // Created or modified files, sorted
+	Removed  []string // Deleted files, sorted
+}
+
+// Empty reports whether the change contains no files.
+func (c Change) Empty() bool {
+	return len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+// Watcher polls a set of files and directories. Directories are walked
+// recursively on every poll, so new files are picked up.
+type Watcher struct {
+	paths []string
+	opts  Options
+}
+
+// fileState is what a poll compares.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// New creates a watcher for paths.
+func New(paths []string, opts Options) *Watcher {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Debounce < 0 {
+		opts.Debounce = 0
+	} else if opts.Debounce == 0 {
+		opts.Debounce = 500 * time.Millisecond
+	}
+	return &Watcher{paths: paths, opts: opts}
+}
+
+// Run reports all current files as modified, then polls until ctx is done
+// and calls onChange once changes have been quiet for the debounce time.
+// Bursts of writes (editors saving through temporary files) are therefore
+// reported once. Run returns an error only when a watched path cannot be
+// read at start; it returns nil when ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context, onChange func(Change)) error {
+	last, err := w.scan(true)
+	if err != nil {
+		return err
+	}
+	initial := Change{Modified: sortedKeys(last)}
+	if !initial.Empty() {
+		onChange(initial)
+	}
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	pending := map[string]bool{} // Changed files; true if the file still exists
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			current, err := w.scan(false)
+			if err != nil {
+				// The file system is temporarily unreadable; try again.
+				continue
+			}
+			if changed := diff(last, current); len(changed) > 0 {
+				for path, exists := range changed {
+					pending[path] = exists
+				}
+				lastChange = now
+			}
+			last = current
+
+			if len(pending) > 0 && now.Sub(lastChange) >= w.opts.Debounce {
+				var c Change
+				for path, exists := range pending {
+					if exists {
+						c.Modified = append(c.Modified, path)
+					} else {
+						c.Removed = append(c.Removed, path)
+					}
+				}
+				sort.Strings(c.Modified)
+				sort.Strings(c.Removed)
+				pending = map[string]bool{}
+				onChange(c)
+			}
+		}
+	}
+}
+
+// scan returns the state of every watched file. Unless strict, a missing
+// watched path counts as deleted instead of failing the scan.
+func (w *Watcher) scan(strict bool) (map[string]fileState, error) {
+	files := map[string]fileState{}
+	for _, root := range w.paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			if !strict && os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !info.IsDir() {
+			// Explicitly named files are watched regardless of Match.
+			files[root] = fileState{size: info.Size(), modTime: info.ModTime()}
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// Entries may vanish while the directory is walked.
+				return nil
+			}
+			if entry.IsDir() {
+				if path != root && entry.Name()[0] == '.' {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if w.opts.Match != nil && !w.opts.Match(path) {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			files[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// diff returns the files that differ between two scans, mapped to whether
+// they exist in the newer one.
+func diff(old, current map[string]fileState) map[string]bool {
+	changed := map[string]bool{}
+	for path, state := range current {
+		if prev, ok := old[path]; !ok || prev.size != state.size || !prev.modTime.Equal(state.modTime) {
+			changed[path] = true
+		}
+	}
+	for path := range old {
+		if _, ok := current[path]; !ok {
+			changed[path] = false
+		}
+	}
+	return changed
+}
+
+func sortedKeys(m map[string]fileState) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
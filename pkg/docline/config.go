@@ -0,0 +1,470 @@
+package docline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileNames are the project configuration files FindConfig looks for,
+// in order.
+var ConfigFileNames = []string{"docline.yaml", "docline.yml", "docline.json"}
+
+// ProjectConfig is a project configuration file (docline.yaml or
+// docline.json). The top-level settings apply to every run; a profile
+// overrides them:
+//
+//	version: 1
+//	inputs: ["docs/**/*.xml"]
+//	finder: automatic
+//	min_clone_length: 20
+//	params: {strict_filter: true}
+//	reports: {formats: [html, sarif], output_dir: results}
+//	profiles:
+//	  strict: {min_clone_length: 10, policy: docline-policy.json}
+//	  exploratory: {finder: ngram, filters: []}
+//
+// Relative paths are resolved against the directory of the file.
+type ProjectConfig struct {
+	Version         int `json:"version,omitempty" yaml:"version,omitempty"` // Schema version; 0 or 1
+	ProjectSettings `yaml:",inline"`
+	DefaultProfile  string                      `json:"default_profile,omitempty" yaml:"default_profile,omitempty"` // Profile used when none is selected
+	Profiles        map[string]*ProjectSettings `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	dir string // Directory of the loaded file
+}
+
+// ProjectSettings are the settings of a configuration file or one of its
+// profiles. In a profile, unset fields keep the top-level value, params
+// are merged key by key and lists, even empty ones, replace the top-level
+// list.
+type ProjectSettings struct {
+	Inputs  []string `json:"inputs,omitempty" yaml:"inputs,omitempty"`   // Documents to analyse; globs, "**" matches any number of directories
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"` // Globs removed from Inputs
+
+	Finder              string                 `json:"finder,omitempty" yaml:"finder,omitempty"`
+	MinCloneLength      *int                   `json:"min_clone_length,omitempty" yaml:"min_clone_length,omitempty"`
+	MaxCloneLength      *int                   `json:"max_clone_length,omitempty" yaml:"max_clone_length,omitempty"`
+	MinGroupPower       *int                   `json:"min_group_power,omitempty" yaml:"min_group_power,omitempty"`
+	SimilarityThreshold *float64               `json:"similarity_threshold,omitempty" yaml:"similarity_threshold,omitempty"`
+	SimilarityCalc      string                 `json:"similarity_calc,omitempty" yaml:"similarity_calc,omitempty"` // Similarity calculator used by the finder
+	Params              map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`                   // Finder-specific parameters (CustomParams)
+
+	Parsers  ParserSettings `json:"parsers,omitempty" yaml:"parsers,omitempty"`
+	Filters  []FilterSpec   `json:"filters,omitempty" yaml:"filters,omitempty"` // Applied in order after every finder run
+	Reports  ReportSettings `json:"reports,omitempty" yaml:"reports,omitempty"`
+	Baseline string         `json:"baseline,omitempty" yaml:"baseline,omitempty"` // JSON baseline of accepted groups
+	Policy   string         `json:"policy,omitempty" yaml:"policy,omitempty"`     // JSON policy for "docline check" and the pre-commit hook
+}
+
+// ParserSettings configure the document parsers.
+type ParserSettings struct {
+	DocBook DocBookSettings `json:"docbook,omitempty" yaml:"docbook,omitempty"`
+}
+
+// DocBookSettings configure the DocBook parser.
+type DocBookSettings struct {
+	TextElements []string `json:"text_elements,omitempty" yaml:"text_elements,omitempty"` // Elements text is extracted from
+}
+
+// ReportSettings select the generated reports.
+type ReportSettings struct {
+	Formats   []string               `json:"formats,omitempty" yaml:"formats,omitempty"`       // Report formats, e.g. [html, sarif]
+	OutputDir string                 `json:"output_dir,omitempty" yaml:"output_dir,omitempty"` // Directory for reports (default "results")
+	Params    map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`         // Report parameters, e.g. title
+}
+
+// LoadConfig reads a YAML (.yaml, .yml) or JSON (.json) project
+// configuration. Unknown fields and invalid values are errors.
+func LoadConfig(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var cfg ProjectConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse config %s: %v", path, err)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("parse config %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("config %s: unsupported extension %q (want .yaml, .yml or .json)", path, ext)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %v", path, err)
+	}
+	abs, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	cfg.dir = abs
+	return &cfg, nil
+}
+
+// FindConfig returns the first of ConfigFileNames present in dir, or "" when
+// there is none.
+func FindConfig(dir string) string {
+	for _, name := range ConfigFileNames {
+		p := filepath.Join(dir, name)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// Validate checks the schema version, value ranges, glob syntax and profile
+// references.
+func (c *ProjectConfig) Validate() error {
+	if c.Version != 0 && c.Version != 1 {
+		return fmt.Errorf("unsupported version %d (want 1)", c.Version)
+	}
+	if err := c.ProjectSettings.validate(); err != nil {
+		return err
+	}
+	for name, p := range c.Profiles {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("profiles: empty profile name")
+		}
+		if p == nil {
+			continue
+		}
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("profile %q: %v", name, err)
+		}
+	}
+	if c.DefaultProfile != "" {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			return fmt.Errorf("default_profile %q is not defined in profiles", c.DefaultProfile)
+		}
+	}
+	return nil
+}
+
+func (s *ProjectSettings) validate() error {
+	for _, field := range []struct {
+		name  string
+		value *int
+	}{
+		{"min_clone_length", s.MinCloneLength},
+		{"max_clone_length", s.MaxCloneLength},
+		{"min_group_power", s.MinGroupPower},
+	} {
+		if field.value != nil && *field.value < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", field.name, *field.value)
+		}
+	}
+	if s.MinCloneLength != nil && s.MaxCloneLength != nil && *s.MaxCloneLength > 0 && *s.MaxCloneLength < *s.MinCloneLength {
+		return fmt.Errorf("max_clone_length %d is below min_clone_length %d", *s.MaxCloneLength, *s.MinCloneLength)
+	}
+	if s.SimilarityThreshold != nil && (*s.SimilarityThreshold < 0 || *s.SimilarityThreshold > 1) {
+		return fmt.Errorf("similarity_threshold must be within 0.0-1.0, got %v", *s.SimilarityThreshold)
+	}
+	for _, pattern := range append(append([]string{}, s.Inputs...), s.Exclude...) {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("inputs/exclude: empty pattern")
+		}
+		if _, err := path.Match(filepath.ToSlash(pattern), ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %v", pattern, err)
+		}
+	}
+	for i, f := range s.Filters {
+		if strings.TrimSpace(f.Name) == "" {
+			return fmt.Errorf("filters[%d]: missing name", i)
+		}
+		if f.MinArchetypeLength < 0 {
+			return fmt.Errorf("filters[%d]: min_archetype_length must not be negative", i)
+		}
+	}
+	for _, format := range s.Reports.Formats {
+		if strings.TrimSpace(format) == "" {
+			return fmt.Errorf("reports.formats: empty format")
+		}
+	}
+	for _, elem := range s.Parsers.DocBook.TextElements {
+		if strings.TrimSpace(elem) == "" {
+			return fmt.Errorf("parsers.docbook.text_elements: empty element name")
+		}
+	}
+	return nil
+}
+
+// Dir returns the directory relative paths are resolved against.
+func (c *ProjectConfig) Dir() string {
+	return c.dir
+}
+
+// Profile returns the top-level settings merged with the named profile, or
+// with DefaultProfile when name is empty. Paths of the result are absolute.
+func (c *ProjectConfig) Profile(name string) (*ProjectSettings, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	merged := c.ProjectSettings.clone()
+	if name != "" {
+		p, ok := c.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q (defined: %s)", name, strings.Join(c.profileNames(), ", "))
+		}
+		if p != nil {
+			merged.override(p)
+		}
+	}
+	merged.resolvePaths(c.dir)
+	return merged, nil
+}
+
+func (c *ProjectConfig) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *ProjectSettings) clone() *ProjectSettings {
+	out := *s
+	out.Params = mergeParams(nil, s.Params)
+	out.Reports.Params = mergeParams(nil, s.Reports.Params)
+	return &out
+}
+
+// override applies the fields set in p.
+func (s *ProjectSettings) override(p *ProjectSettings) {
+	if p.Inputs != nil {
+		s.Inputs = p.Inputs
+	}
+	if p.Exclude != nil {
+		s.Exclude = p.Exclude
+	}
+	if p.Finder != "" {
+		s.Finder = p.Finder
+	}
+	if p.MinCloneLength != nil {
+		s.MinCloneLength = p.MinCloneLength
+	}
+	if p.MaxCloneLength != nil {
+		s.MaxCloneLength = p.MaxCloneLength
+	}
+	if p.MinGroupPower != nil {
+		s.MinGroupPower = p.MinGroupPower
+	}
+	if p.SimilarityThreshold != nil {
+		s.SimilarityThreshold = p.SimilarityThreshold
+	}
+	if p.SimilarityCalc != "" {
+		s.SimilarityCalc = p.SimilarityCalc
+	}
+	s.Params = mergeParams(s.Params, p.Params)
+	if p.Parsers.DocBook.TextElements != nil {
+		s.Parsers.DocBook.TextElements = p.Parsers.DocBook.TextElements
+	}
+	if p.Filters != nil { // An empty list clears the top-level filters
+		s.Filters = p.Filters
+	}
+	if p.Reports.Formats != nil {
+		s.Reports.Formats = p.Reports.Formats
+	}
+	if p.Reports.OutputDir != "" {
+		s.Reports.OutputDir = p.Reports.OutputDir
+	}
+	s.Reports.Params = mergeParams(s.Reports.Params, p.Reports.Params)
+	if p.Baseline != "" {
+		s.Baseline = p.Baseline
+	}
+	if p.Policy != "" {
+		s.Policy = p.Policy
+	}
+}
+
+func (s *ProjectSettings) resolvePaths(dir string) {
+	abs := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	s.Baseline = abs(s.Baseline)
+	s.Policy = abs(s.Policy)
+	if s.Reports.OutputDir == "" {
+		s.Reports.OutputDir = "results"
+	}
+	s.Reports.OutputDir = abs(s.Reports.OutputDir)
+	inputs := make([]string, len(s.Inputs))
+	for i, p := range s.Inputs {
+		inputs[i] = abs(p)
+	}
+	s.Inputs = inputs
+	exclude := make([]string, len(s.Exclude))
+	for i, p := range s.Exclude {
+		exclude[i] = abs(p)
+	}
+	s.Exclude = exclude
+}
+
+func mergeParams(base, override map[string]interface{}) map[string]interface{} {
+	if base == nil && override == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range override {
+		out[k] = v
+	}
+	return out
+}
+
+// FinderConfig returns the finder configuration of the settings.
+func (s *ProjectSettings) FinderConfig() CloneFinderConfig {
+	var cfg CloneFinderConfig
+	if s.MinCloneLength != nil {
+		cfg.MinCloneLength = *s.MinCloneLength
+	}
+	if s.MaxCloneLength != nil {
+		cfg.MaxCloneLength = *s.MaxCloneLength
+	}
+	if s.MinGroupPower != nil {
+		cfg.MinGroupPower = *s.MinGroupPower
+	}
+	if s.SimilarityThreshold != nil {
+		cfg.SimilarityThreshold = *s.SimilarityThreshold
+	}
+	cfg.SimilarityCalc = s.SimilarityCalc
+	cfg.CustomParams = mergeParams(nil, s.Params)
+	return cfg
+}
+
+// Config returns the Docline configuration of the settings, for New.
+func (s *ProjectSettings) Config() *Config {
+	format := "html"
+	if len(s.Reports.Formats) > 0 {
+		format = s.Reports.Formats[0]
+	}
+	finder := s.Finder
+	if finder == "" {
+		finder = "automatic"
+	}
+	return &Config{
+		ResultsDirectory:    s.Reports.OutputDir,
+		DefaultReportFormat: format,
+		DefaultTokenizer:    "space",
+		DefaultCloneFinder:  finder,
+		BaselineFile:        s.Baseline,
+		Filters:             s.Filters,
+		DocBookTextElements: s.Parsers.DocBook.TextElements,
+	}
+}
+
+// Files expands Inputs and removes Exclude matches. Patterns are matched
+// against slash-separated paths; "**" matches any number of directories.
+// The result is sorted and free of duplicates; a pattern matching nothing
+// is an error, so typos do not go unnoticed.
+func (s *ProjectSettings) Files() ([]string, error) {
+	seen := map[string]bool{}
+	var files []string
+	for _, pattern := range s.Inputs {
+		matches, err := expandGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("input %q matches no files", pattern)
+		}
+		for _, m := range matches {
+			if !seen[m] && !matchesAny(s.Exclude, m) {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// expandGlob returns the regular files matching pattern. The walk starts at
+// the longest directory prefix without wildcards.
+func expandGlob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	root := pattern
+	for strings.ContainsAny(root, "*?[") {
+		root = path.Dir(root)
+	}
+	if root == pattern {
+		if info, err := os.Stat(filepath.FromSlash(pattern)); err != nil || info.IsDir() {
+			return nil, nil
+		}
+		return []string{filepath.FromSlash(pattern)}, nil
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == filepath.FromSlash(root) {
+				return nil // Missing root: no matches
+			}
+			return err
+		}
+		if !d.IsDir() && matchGlob(pattern, filepath.ToSlash(p)) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+func matchesAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(filepath.ToSlash(filepath.Clean(pattern)), filepath.ToSlash(file)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated name against pattern segment by
+// segment; a "**" segment matches zero or more segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
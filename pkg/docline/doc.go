@@ -0,0 +1,23 @@
+// Package docline is the public API of the Docline duplicate finder.
+//
+// Everything another module needs is declared in this package: the Docline
+// entry point, finder configurations, analysis results (AnalysisResult,
+// CloneGroup, TextFragment, AnalysisStatistics), comparisons, policies and
+// the plugin interfaces (CloneFinder, ReportGenerator, DocumentParser,
+// TextTokenizer, SimilarityCalculator). The implementation lives under
+// internal/ and is converted to and from these types at the package boundary,
+// so callers never have to name an internal type.
+//
+// # Stability
+//
+// The exported identifiers of this package follow semantic versioning: within
+// a major version, types, fields, methods and functions are not removed or
+// renamed, and their meaning does not change. New fields, methods, functions,
+// finders, report formats and metadata keys may be added in minor versions, so
+// do not rely on the exact set of keys in Metadata maps or use unkeyed struct
+// literals. Plugin interfaces are never extended; new capabilities are added
+// as separate optional interfaces.
+//
+// The JSON result format is versioned separately, see ResultSchemaVersion.
+// Packages under internal/ carry no compatibility promise.
+package docline
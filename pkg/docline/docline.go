@@ -6,6 +6,9 @@ import (
 	internalReport "github.com/PavelMkr/docline-new/internal/report"
 
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 )
 
 // Config - public configuration struct for initializing the Docline framework
@@ -14,14 +17,34 @@ type Config struct {
 	DefaultReportFormat string
 	DefaultTokenizer    string
 	DefaultCloneFinder  string
+	// DefaultSimilarityCalc names the similarity calculator finders use when
+	// CloneFinderConfig.SimilarityCalc is empty (default "jaccard"; built-in:
+	// jaccard, dice, levenshtein, lcs, cosine).
+	DefaultSimilarityCalc string
+	// BaselineFile optionally points to a JSON baseline of accepted clone
+	// groups; matching groups are reported as "accepted".
+	BaselineFile string
+	// Filters are registered filters applied in order after every finder run.
+	Filters []FilterSpec
+	// DocBookTextElements replaces the DocBook elements text is extracted
+	// from (default: para, simpara, title, section, chapter, note, warning,
+	// important, tip).
+	DocBookTextElements []string
 }
 
+// CloneFinderConfig is the finder-independent configuration of an analysis;
+// finder-specific settings go into CustomParams.
 type CloneFinderConfig struct {
-	MinCloneLength      int
-	MaxCloneLength      int
-	MinGroupPower       int
-	SimilarityThreshold float64
-	CustomParams        map[string]interface{}
+	MinCloneLength      int                    `json:"min_clone_length"`          // Minimum clone length in tokens
+	MaxCloneLength      int                    `json:"max_clone_length"`          // Maximum clone length in tokens (0 = unlimited)
+	MinGroupPower       int                    `json:"min_group_power"`           // Minimum number of fragments in a group
+	SimilarityThreshold float64                `json:"similarity_threshold"`      // Minimum similarity score (0.0-1.0, 0 = finder default)
+	SimilarityCalc      string                 `json:"similarity_calc,omitempty"` // Similarity calculator (empty = Config.DefaultSimilarityCalc)
+	CustomParams        map[string]interface{} `json:"custom_params,omitempty"`   // Algorithm-specific parameters
+
+	// Similarity is the calculator named by SimilarityCalc, resolved before
+	// the finder runs; set it to use a calculator that is not registered
+	Similarity SimilarityCalculator `json:"-"`
 }
 
 // FinderModeConfig type-safe public config for a API
@@ -42,10 +65,15 @@ type Docline struct {
 // New creates a new Docline instance
 func New(cfg *Config) *Docline {
 	internalCfg := &internalFramework.Config{
-		ResultsDirectory:    cfg.ResultsDirectory,
-		DefaultReportFormat: cfg.DefaultReportFormat,
-		DefaultTokenizer:    cfg.DefaultTokenizer,
-		DefaultCloneFinder:  cfg.DefaultCloneFinder,
+		ResultsDirectory:      cfg.ResultsDirectory,
+		DefaultReportFormat:   cfg.DefaultReportFormat,
+		DefaultTokenizer:      cfg.DefaultTokenizer,
+		DefaultCloneFinder:    cfg.DefaultCloneFinder,
+		DefaultSimilarityCalc: cfg.DefaultSimilarityCalc,
+		BaselineFile:          cfg.BaselineFile,
+	}
+	for _, f := range cfg.Filters {
+		internalCfg.Filters = append(internalCfg.Filters, f.toInternal())
 	}
 
 	fw := internalFramework.NewFramework(internalCfg)
@@ -56,12 +84,19 @@ func New(cfg *Config) *Docline {
 	internalAlgorithms.RegisterCloneFinders(reg)
 	internalReport.RegisterDocumentPlugins(reg)
 	internalReport.RegisterReportGenerators(reg)
+	if cfg.DocBookTextElements != nil {
+		if p, err := reg.GetDocumentParser(".xml"); err == nil {
+			if docbook, ok := p.(*internalReport.DocBookParserAdapter); ok {
+				docbook.TextElements = cfg.DocBookTextElements
+			}
+		}
+	}
 
 	return &Docline{fw: fw}
 }
 
 // AnalyzeDocument analyzes the specified document and returns the result
-func (d *Docline) AnalyzeDocument(filePath, finderType string, cfg FinderModeConfig) (*internalFramework.AnalysisResult, error) {
+func (d *Docline) AnalyzeDocument(filePath, finderType string, cfg FinderModeConfig) (*AnalysisResult, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("nil finder config")
 	}
@@ -73,21 +108,182 @@ func (d *Docline) AnalyzeDocument(filePath, finderType string, cfg FinderModeCon
 		return nil, fmt.Errorf("finderType %q does not match config type %q", finderType, cfg.FinderType())
 	}
 
-	return d.fw.AnalyzeDocument(filePath, finderType, cfg.toInternal(filePath))
+	return resultOf(d.fw.AnalyzeDocument(filePath, finderType, cfg.toInternal(filePath)))
+}
+
+// AnalyzeDocumentWithConfig analyzes the document with a finder-independent
+// configuration, e.g. for custom finders
+func (d *Docline) AnalyzeDocumentWithConfig(filePath, finderType string, cfg CloneFinderConfig) (*AnalysisResult, error) {
+	return resultOf(d.fw.AnalyzeDocument(filePath, finderType, cfg.toInternal()))
+}
+
+// AnalyzeText analyzes a document held in memory, e.g. received over HTTP.
+// format selects the registered parser by file extension ("xml", ".md", ...);
+// "" reads the text as is. Nothing is read from or written to disk, except
+// that formats needing conversion are piped through pandoc.
+func (d *Docline) AnalyzeText(text, format string, cfg FinderModeConfig) (*AnalysisResult, error) {
+	return d.AnalyzeReader(strings.NewReader(text), format, cfg)
 }
 
-func (d *Docline) AnalyzeDocumentWithConfig(filePath, finderType string, cfg CloneFinderConfig) (*internalFramework.AnalysisResult, error) {
-	internalCfg := internalFramework.CloneFinderConfig{
-		MinCloneLength:      cfg.MinCloneLength,
-		MaxCloneLength:      cfg.MaxCloneLength,
-		MinGroupPower:       cfg.MinGroupPower,
-		SimilarityThreshold: cfg.SimilarityThreshold,
-		CustomParams:        cfg.CustomParams,
+// AnalyzeReader analyzes a document read from r; ext is handled as the
+// format of AnalyzeText.
+func (d *Docline) AnalyzeReader(r io.Reader, ext string, cfg FinderModeConfig) (*AnalysisResult, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("nil finder config")
 	}
-	return d.fw.AnalyzeDocument(filePath, finderType, internalCfg)
+	return resultOf(d.fw.AnalyzeReader(r, ext, cfg.FinderType(), cfg.toInternal("")))
+}
+
+// ExtractText returns the text that AnalyzeReader would analyse for the
+// document read from r; fragment StartPos/EndPos are indexes into
+// strings.Fields of it. Use it to map fragments back to the source document.
+func (d *Docline) ExtractText(r io.Reader, ext string) (string, error) {
+	return d.fw.ExtractText(r, ext)
+}
+
+// SelectGroups returns a copy of result with only the groups keep accepts,
+// with recomputed statistics (e.g. to restrict a result to changed lines).
+func (d *Docline) SelectGroups(result *AnalysisResult, keep func(CloneGroup) bool) *AnalysisResult {
+	return resultFromInternal(d.fw.SelectGroups(result.toInternal(), func(g internalFramework.CloneGroup) bool {
+		return keep(groupFromInternal(g))
+	}))
+}
+
+// AnalyzeReaderWithConfig is AnalyzeReader with a finder-independent
+// configuration, e.g. for custom finders
+func (d *Docline) AnalyzeReaderWithConfig(r io.Reader, ext, finderType string, cfg CloneFinderConfig) (*AnalysisResult, error) {
+	return resultOf(d.fw.AnalyzeReader(r, ext, finderType, cfg.toInternal()))
 }
 
 // GenerateReport generates a report based on the analysis result
-func (d *Docline) GenerateReport(result *internalFramework.AnalysisResult, format, outputPath string) error {
-	return d.fw.GenerateReport(result, format, outputPath)
+func (d *Docline) GenerateReport(result *AnalysisResult, format, outputPath string) error {
+	return d.fw.GenerateReport(result.toInternal(), format, outputPath)
+}
+
+// GenerateReportWithParams generates a report passing format-specific
+// parameters to the generator, e.g. "template_dir" with custom HTML templates.
+func (d *Docline) GenerateReportWithParams(result *AnalysisResult, format, outputPath string, params map[string]interface{}) error {
+	return d.fw.GenerateReportWithParams(result.toInternal(), format, outputPath, params)
+}
+
+// AnalyzeDocumentStream analyses a document and writes the report as groups
+// are handed over by the finder; with the "ndjson" format they are written
+// one at a time. The "terminology" finder hands each term over as soon as
+// its last occurrence has been read; the other built-in finders compute all
+// groups first. The returned result has no groups.
+func (d *Docline) AnalyzeDocumentStream(filePath, finderType string, cfg CloneFinderConfig, format, outputPath string, params map[string]interface{}) (*AnalysisResult, error) {
+	return resultOf(d.fw.AnalyzeDocumentStream(filePath, finderType, cfg.toInternal(), format, outputPath, params))
+}
+
+// ReportFormats returns the registered report formats in alphabetical order.
+func (d *Docline) ReportFormats() []string {
+	formats := d.fw.GetRegistry().ListReportFormats()
+	sort.Strings(formats)
+	return formats
+}
+
+// SimilarityCalculators returns the registered similarity calculators in
+// alphabetical order.
+func (d *Docline) SimilarityCalculators() []string {
+	names := d.fw.GetRegistry().ListSimilarityCalculators()
+	sort.Strings(names)
+	return names
+}
+
+// CloneFinders describes the registered clone finders in alphabetical order.
+func (d *Docline) CloneFinders() []FinderInfo {
+	names := d.fw.GetRegistry().ListCloneFinders()
+	sort.Strings(names)
+	infos := make([]FinderInfo, 0, len(names))
+	for _, name := range names {
+		finder, err := d.fw.GetRegistry().GetCloneFinder(name)
+		if err != nil {
+			continue
+		}
+		info := FinderInfo{Name: name, Description: finder.Description()}
+		if specs, ok, _ := d.fw.FinderParams(name); ok {
+			info.Declared = true
+			info.Params = make([]ParamSpec, len(specs))
+			for i, s := range specs {
+				info.Params[i] = paramSpecFromInternal(s)
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// ValidateFinderParams checks params against the declaration of the named
+// finder, as the analysis does before running it. Finders that declare no
+// parameters accept any.
+func (d *Docline) ValidateFinderParams(finderType string, params map[string]interface{}) error {
+	specs, ok, err := d.fw.FinderParams(finderType)
+	if err != nil || !ok {
+		return err
+	}
+	if err := internalFramework.ValidateParams(specs, params); err != nil {
+		return fmt.Errorf("finder %s: %v", finderType, err)
+	}
+	return nil
+}
+
+// reportExtensions maps report formats to file extensions where they differ.
+var reportExtensions = map[string]string{
+	"glossary":         "md",
+	"html-interactive": "html",
+	"junit":            "xml",
+	"short-terms-csv":  "csv",
+}
+
+// ReportFileExtension returns the file extension, without the dot, for
+// reports in format; it is the format name unless that is not an extension
+// (e.g. "xml" for "junit").
+func ReportFileExtension(format string) string {
+	if ext, ok := reportExtensions[format]; ok {
+		return ext
+	}
+	return format
+}
+
+// LoadResult reads an analysis result saved with the "json" report format, so
+// it can be rendered in other formats or compared later.
+func LoadResult(path string) (*AnalysisResult, error) {
+	return resultOf(internalFramework.LoadResult(path))
+}
+
+// CompareResults matches clone groups of two analysis results by stable group
+// ID and reports new, removed, grown and shrunk groups. Use
+// AsAnalysisResult on the returned comparison to render it with GenerateReport.
+func CompareResults(old, new *AnalysisResult) *ResultComparison {
+	return comparisonFromInternal(internalFramework.CompareResults(old.toInternal(), new.toInternal()))
+}
+
+// LoadPolicy reads a JSON duplication policy (max groups, max duplicated-token
+// ratio, max group power, no new groups) for use with EvaluatePolicy.
+func LoadPolicy(path string) (*Policy, error) {
+	p, err := internalFramework.LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	return policyFromInternal(p), nil
+}
+
+// EvaluatePolicy checks result against policy and stores the verdict in
+// result.Metadata["policy_verdict"], where the "junit" report picks it up.
+// previous is the result new groups are counted against; it may be nil.
+func EvaluatePolicy(policy *Policy, result, previous *AnalysisResult) PolicyVerdict {
+	verdict := verdictFromInternal(policy.toInternal().Evaluate(result.toInternal(), previous.toInternal()))
+	if result.Metadata == nil {
+		result.Metadata = map[string]interface{}{}
+	}
+	result.Metadata["policy_verdict"] = verdict
+	return verdict
+}
+
+// resultOf converts the result of an internal framework call.
+func resultOf(result *internalFramework.AnalysisResult, err error) (*AnalysisResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	return resultFromInternal(result), nil
 }
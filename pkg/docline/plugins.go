@@ -0,0 +1,88 @@
+package docline
+
+import "io"
+
+// CloneFinder is a clone detection algorithm.
+type CloneFinder interface {
+	// FindClones searches for duplicate text fragments in the given text
+	FindClones(text string, config CloneFinderConfig) ([]CloneGroup, error)
+
+	// Name returns the identifier the finder is selected by
+	Name() string
+
+	// Description returns a human-readable description of the algorithm
+	Description() string
+}
+
+// ParameterizedCloneFinder is implemented by clone finders that declare the
+// CustomParams they accept. Unknown keys, wrong types and out-of-range values
+// are then rejected before the finder runs, and "docline finders" lists them.
+type ParameterizedCloneFinder interface {
+	CloneFinder
+
+	// Params returns the accepted parameters
+	Params() []ParamSpec
+}
+
+// ReportGenerator renders clone groups in one output format.
+type ReportGenerator interface {
+	// Generate writes a report of the groups to outputPath
+	Generate(groups []CloneGroup, config ReportConfig, outputPath string) error
+
+	// Format returns the format identifier the generator is selected by (e.g. "html")
+	Format() string
+
+	// Name returns the name of the report generator
+	Name() string
+}
+
+// DocumentParser extracts text from one or more document formats.
+type DocumentParser interface {
+	// Parse extracts text segments from a document
+	Parse(reader io.Reader) ([]string, error)
+
+	// SupportedFormats returns the file extensions handled, including the dot (e.g. ".adoc")
+	SupportedFormats() []string
+
+	// Name returns the name of the parser
+	Name() string
+}
+
+// TextTokenizer splits text into tokens.
+type TextTokenizer interface {
+	// Tokenize splits text into tokens
+	Tokenize(text string) []string
+
+	// Name returns the name of the tokenizer
+	Name() string
+}
+
+// SimilarityCalculator scores how similar two text fragments are.
+type SimilarityCalculator interface {
+	// CalculateSimilarity returns a value between 0.0 (completely different)
+	// and 1.0 (identical)
+	CalculateSimilarity(text1, text2 string) float64
+
+	// Name returns the name of the similarity algorithm
+	Name() string
+}
+
+// Filter post-processes the clone groups of every analysis; see
+// Config.Filters.
+type Filter interface {
+	// Filter returns the groups to keep, possibly modified
+	Filter(groups []CloneGroup, config FilterConfig) []CloneGroup
+
+	// Name returns the identifier the filter is selected by in FilterSpec
+	Name() string
+}
+
+// ParameterizedFilter is implemented by filters that declare the
+// FilterConfig.CustomParams they accept; they are validated before the
+// filter runs.
+type ParameterizedFilter interface {
+	Filter
+
+	// Params returns the accepted parameters
+	Params() []ParamSpec
+}
@@ -0,0 +1,175 @@
+package docline
+
+import (
+	"fmt"
+
+	internalFramework "github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// RegisterCloneFinder makes a custom finder available to
+// AnalyzeDocumentWithConfig under its Name(). Names must be unique; the
+// built-in finders cannot be replaced.
+func (d *Docline) RegisterCloneFinder(finder CloneFinder) error {
+	if finder == nil {
+		return fmt.Errorf("nil clone finder")
+	}
+	adapter := &cloneFinderAdapter{finder: finder}
+	if p, ok := finder.(ParameterizedCloneFinder); ok {
+		return d.fw.GetRegistry().RegisterCloneFinder(&parameterizedFinderAdapter{cloneFinderAdapter: adapter, params: p})
+	}
+	return d.fw.GetRegistry().RegisterCloneFinder(adapter)
+}
+
+// RegisterParser makes a custom document parser available for the file
+// extensions it supports. An extension already handled by another parser is
+// rejected.
+func (d *Docline) RegisterParser(parser DocumentParser) error {
+	if parser == nil {
+		return fmt.Errorf("nil document parser")
+	}
+	reg := d.fw.GetRegistry()
+	for _, ext := range parser.SupportedFormats() {
+		if existing, err := reg.GetDocumentParser(ext); err == nil {
+			return fmt.Errorf("extension '%s' is already handled by parser '%s'", ext, existing.Name())
+		}
+	}
+	return reg.RegisterDocumentParser(parser)
+}
+
+// RegisterReportGenerator makes a custom report format available to
+// GenerateReport under its Format(). A format that is already registered is
+// rejected.
+func (d *Docline) RegisterReportGenerator(generator ReportGenerator) error {
+	if generator == nil {
+		return fmt.Errorf("nil report generator")
+	}
+	reg := d.fw.GetRegistry()
+	if existing, err := reg.GetReportGenerator(generator.Format()); err == nil {
+		return fmt.Errorf("report format '%s' is already provided by '%s'", generator.Format(), existing.Name())
+	}
+	return reg.RegisterReportGenerator(&reportGeneratorAdapter{generator: generator})
+}
+
+// RegisterTokenizer registers a custom tokenizer; select it with
+// Config.DefaultTokenizer.
+func (d *Docline) RegisterTokenizer(tokenizer TextTokenizer) error {
+	if tokenizer == nil {
+		return fmt.Errorf("nil tokenizer")
+	}
+	return d.fw.GetRegistry().RegisterTextTokenizer(tokenizer)
+}
+
+// RegisterFilter makes a custom filter available to Config.Filters under its
+// Name(). Names must be unique; the built-in filters cannot be replaced.
+func (d *Docline) RegisterFilter(filter Filter) error {
+	if filter == nil {
+		return fmt.Errorf("nil filter")
+	}
+	adapter := &filterAdapter{filter: filter}
+	if p, ok := filter.(ParameterizedFilter); ok {
+		return d.fw.GetRegistry().RegisterFilter(&parameterizedFilterAdapter{filterAdapter: adapter, params: p})
+	}
+	return d.fw.GetRegistry().RegisterFilter(adapter)
+}
+
+// RegisterSimilarityCalculator makes a custom similarity calculator
+// available to Config.DefaultSimilarityCalc and
+// CloneFinderConfig.SimilarityCalc under its Name(). Names must be unique.
+func (d *Docline) RegisterSimilarityCalculator(calc SimilarityCalculator) error {
+	if calc == nil {
+		return fmt.Errorf("nil similarity calculator")
+	}
+	return d.fw.GetRegistry().RegisterSimilarityCalculator(calc)
+}
+
+// cloneFinderAdapter implements the internal framework.CloneFinder for a
+// public CloneFinder.
+type cloneFinderAdapter struct {
+	finder CloneFinder
+}
+
+func (a *cloneFinderAdapter) Name() string {
+	return a.finder.Name()
+}
+
+func (a *cloneFinderAdapter) Description() string {
+	return a.finder.Description()
+}
+
+func (a *cloneFinderAdapter) FindClones(text string, config internalFramework.CloneFinderConfig) ([]internalFramework.CloneGroup, error) {
+	groups, err := a.finder.FindClones(text, configFromInternal(config))
+	if err != nil {
+		return nil, err
+	}
+	return groupsToInternal(groups), nil
+}
+
+// parameterizedFinderAdapter additionally implements the internal
+// framework.ParameterizedCloneFinder.
+type parameterizedFinderAdapter struct {
+	*cloneFinderAdapter
+	params ParameterizedCloneFinder
+}
+
+func (a *parameterizedFinderAdapter) Params() []internalFramework.ParamSpec {
+	return paramSpecsToInternal(a.params.Params())
+}
+
+// filterAdapter implements the internal framework.Filter for a public Filter.
+type filterAdapter struct {
+	filter Filter
+}
+
+func (a *filterAdapter) Name() string {
+	return a.filter.Name()
+}
+
+func (a *filterAdapter) Filter(groups []internalFramework.CloneGroup, config internalFramework.FilterConfig) []internalFramework.CloneGroup {
+	return groupsToInternal(a.filter.Filter(groupsFromInternal(groups), FilterConfig{
+		MinArchetypeLength: config.MinArchetypeLength,
+		RemoveOverlaps:     config.RemoveOverlaps,
+		CustomParams:       config.CustomParams,
+	}))
+}
+
+// parameterizedFilterAdapter additionally implements the internal
+// framework.ParameterizedFilter.
+type parameterizedFilterAdapter struct {
+	*filterAdapter
+	params ParameterizedFilter
+}
+
+func (a *parameterizedFilterAdapter) Params() []internalFramework.ParamSpec {
+	return paramSpecsToInternal(a.params.Params())
+}
+
+// reportGeneratorAdapter implements the internal framework.ReportGenerator
+// for a public ReportGenerator.
+type reportGeneratorAdapter struct {
+	generator ReportGenerator
+}
+
+func (a *reportGeneratorAdapter) Name() string {
+	return a.generator.Name()
+}
+
+func (a *reportGeneratorAdapter) Format() string {
+	return a.generator.Format()
+}
+
+func (a *reportGeneratorAdapter) Generate(groups []internalFramework.CloneGroup, config internalFramework.ReportConfig, outputPath string) error {
+	return a.generator.Generate(groupsFromInternal(groups), ReportConfig{
+		Title:        config.Title,
+		SourceFile:   config.SourceFile,
+		Settings:     metadataFromInternal(config.Settings),
+		OutputDir:    config.OutputDir,
+		CustomParams: config.CustomParams,
+	}, outputPath)
+}
+
+// DocumentParser and TextTokenizer use only standard types, so public
+// implementations satisfy the internal interfaces directly.
+var (
+	_ internalFramework.DocumentParser = DocumentParser(nil)
+	_ internalFramework.TextTokenizer  = TextTokenizer(nil)
+)
@@ -0,0 +1,551 @@
+package docline
+
+import internalFramework "github.com/PavelMkr/docline-new/internal/framework"
+
+// ResultSchemaVersion is the version of the JSON result written by the "json"
+// report format and read by LoadResult.
+const ResultSchemaVersion = internalFramework.ResultSchemaVersion
+
+// Metadata keys set on clone groups.
+const (
+	GroupIDMetadataKey            = internalFramework.GroupIDMetadataKey            // Stable group ID
+	SuppressedMetadataKey         = internalFramework.SuppressedMetadataKey         // true when accepted by a baseline
+	SuppressionReasonMetadataKey  = internalFramework.SuppressionReasonMetadataKey  // Reason of the matching baseline entry
+	SuppressionExpiresMetadataKey = internalFramework.SuppressionExpiresMetadataKey // Expiry of the matching baseline entry
+	DiffStatusMetadataKey         = internalFramework.DiffStatusMetadataKey         // Change kind in ResultComparison.AsAnalysisResult
+	OldPowerMetadataKey           = internalFramework.OldPowerMetadataKey           // Power in the old result of a comparison
+	NewPowerMetadataKey           = internalFramework.NewPowerMetadataKey           // Power in the new result of a comparison
+)
+
+// Metadata keys set on text fragments.
+const (
+	SourceLineStartMetadataKey = internalFramework.SourceLineStartMetadataKey // 1-based first line in the analysed text
+	SourceLineEndMetadataKey   = internalFramework.SourceLineEndMetadataKey   // 1-based last line in the analysed text
+	SourceFileMetadataKey      = internalFramework.SourceFileMetadataKey      // File of the fragment when not the result's source file
+)
+
+// Change kinds reported by CompareResults.
+const (
+	GroupNew       = internalFramework.GroupNew
+	GroupRemoved   = internalFramework.GroupRemoved
+	GroupGrown     = internalFramework.GroupGrown
+	GroupShrunk    = internalFramework.GroupShrunk
+	GroupUnchanged = internalFramework.GroupUnchanged
+)
+
+// Policy rule names used in PolicyCheck.Rule.
+const (
+	RuleMaxGroups               = internalFramework.RuleMaxGroups
+	RuleMaxDuplicatedTokenRatio = internalFramework.RuleMaxDuplicatedTokenRatio
+	RuleMaxGroupPower           = internalFramework.RuleMaxGroupPower
+	RuleNoNewGroups             = internalFramework.RuleNoNewGroups
+)
+
+// TextFragment is a single occurrence of a repeated text.
+type TextFragment struct {
+	Content  string                 `json:"content"`            // The actual text content
+	StartPos int                    `json:"start_pos"`          // Starting position (token index)
+	EndPos   int                    `json:"end_pos"`            // Ending position (token index)
+	Metadata map[string]interface{} `json:"metadata,omitempty"` // e.g. SourceLineStartMetadataKey, SourceLineEndMetadataKey
+}
+
+// CloneGroup is a group of similar text fragments.
+type CloneGroup struct {
+	Fragments []TextFragment         `json:"fragments"`
+	Power     int                    `json:"power"`              // Number of fragments in the group
+	Archetype string                 `json:"archetype"`          // Representative text for the group
+	Metadata  map[string]interface{} `json:"metadata,omitempty"` // e.g. GroupIDMetadataKey, SuppressedMetadataKey
+}
+
+// AnalysisStatistics summarises an analysis result.
+type AnalysisStatistics struct {
+	TotalGroups      int     `json:"total_groups"`      // Total number of clone groups
+	TotalFragments   int     `json:"total_fragments"`   // Total number of fragments
+	AvgTokens        float64 `json:"avg_tokens"`        // Average tokens per fragment
+	MaxTokens        int     `json:"max_tokens"`        // Maximum tokens in a fragment
+	MinTokens        int     `json:"min_tokens"`        // Minimum tokens in a fragment
+	SuppressedGroups int     `json:"suppressed_groups"` // Groups accepted by a baseline (still counted in TotalGroups)
+	MaxGroupPower    int     `json:"max_group_power"`   // Largest power among groups not accepted by a baseline
+	TotalTokens      int     `json:"total_tokens"`      // Tokens in the analysed text
+	DuplicatedTokens int     `json:"duplicated_tokens"` // Tokens covered by fragments of groups not accepted by a baseline
+}
+
+// DuplicatedTokenRatio returns DuplicatedTokens / TotalTokens, or 0 when the
+// total is unknown.
+func (s AnalysisStatistics) DuplicatedTokenRatio() float64 {
+	return s.toInternal().DuplicatedTokenRatio()
+}
+
+// AnalysisResult is the complete result of an analysis.
+//
+// Metadata holds "source_file", "finder" and "total_tokens" and, when set,
+// "stale_baseline_entries" ([]StaleBaselineEntry) and "policy_verdict"
+// (PolicyVerdict).
+type AnalysisResult struct {
+	Groups     []CloneGroup
+	Statistics AnalysisStatistics
+	Metadata   map[string]interface{}
+	Config     CloneFinderConfig // Configuration used
+}
+
+// ReportConfig is passed to report generators.
+type ReportConfig struct {
+	Title        string                 // Report title
+	SourceFile   string                 // Source file path
+	Settings     map[string]interface{} // Result metadata plus "stats" (AnalysisStatistics) and "config" (CloneFinderConfig)
+	OutputDir    string                 // Output directory
+	CustomParams map[string]interface{} // Format-specific parameters
+}
+
+// BaselineEntry accepts a clone group by stable ID or archetype pattern.
+type BaselineEntry struct {
+	GroupID string `json:"group_id,omitempty"` // Stable group ID
+	Pattern string `json:"pattern,omitempty"`  // Regular expression matched against the normalised archetype
+	Reason  string `json:"reason"`             // Why the repetition is accepted
+	Expires string `json:"expires,omitempty"`  // Optional expiry date (YYYY-MM-DD)
+}
+
+// StaleBaselineEntry reports a baseline entry that should be reviewed.
+type StaleBaselineEntry struct {
+	Entry  BaselineEntry `json:"entry"`
+	Status string        `json:"status"` // "expired" or "unmatched"
+}
+
+// GroupChange describes how one clone group differs between two results.
+type GroupChange struct {
+	ID       string     // Stable group ID
+	Kind     string     // One of GroupNew, GroupRemoved, GroupGrown, GroupShrunk, GroupUnchanged
+	OldPower int        // Power in the old result (0 for new groups)
+	NewPower int        // Power in the new result (0 for removed groups)
+	Group    CloneGroup // Group from the new result, or from the old one when removed
+}
+
+// ResultComparison is the outcome of CompareResults.
+type ResultComparison struct {
+	New       []GroupChange
+	Removed   []GroupChange
+	Grown     []GroupChange
+	Shrunk    []GroupChange
+	Unchanged []GroupChange
+
+	OldSource string
+	NewSource string
+
+	// TotalTokens of the new result, for the statistics of AsAnalysisResult
+	TotalTokens int
+}
+
+// HasChanges reports whether any group was added, removed, grown or shrunk.
+func (c *ResultComparison) HasChanges() bool {
+	return len(c.New)+len(c.Removed)+len(c.Grown)+len(c.Shrunk) > 0
+}
+
+// Changes returns all changed groups (unchanged ones excluded) in the order
+// new, grown, shrunk, removed.
+func (c *ResultComparison) Changes() []GroupChange {
+	return groupChangesFromInternal(c.toInternal().Changes())
+}
+
+// AsAnalysisResult converts the comparison into an AnalysisResult so that it
+// can be rendered by any report format. Every changed group carries
+// DiffStatusMetadataKey, OldPowerMetadataKey and NewPowerMetadataKey metadata.
+func (c *ResultComparison) AsAnalysisResult() *AnalysisResult {
+	return resultFromInternal(c.toInternal().AsAnalysisResult())
+}
+
+// Policy is a duplication policy for EvaluatePolicy; a nil threshold disables
+// its rule and zero is a real limit.
+type Policy struct {
+	MaxGroups               *int     `json:"max_groups,omitempty"`                 // Maximum number of clone groups
+	MaxDuplicatedTokenRatio *float64 `json:"max_duplicated_token_ratio,omitempty"` // Maximum share of duplicated tokens (0.0-1.0)
+	MaxGroupPower           *int     `json:"max_group_power,omitempty"`            // Maximum fragments in a single group
+	NoNewGroups             bool     `json:"no_new_groups,omitempty"`              // Fail on groups absent from the previous result
+}
+
+// Validate checks that all thresholds are in range.
+func (p *Policy) Validate() error {
+	return p.toInternal().Validate()
+}
+
+// ParamType is the type of a finder parameter value.
+type ParamType string
+
+// Finder parameter types.
+const (
+	ParamBool   ParamType = "bool"
+	ParamInt    ParamType = "int"
+	ParamFloat  ParamType = "float"
+	ParamString ParamType = "string"
+)
+
+// ParamSpec declares one CloneFinderConfig.CustomParams entry of a finder;
+// see ParameterizedCloneFinder.
+type ParamSpec struct {
+	Name        string      `json:"name"`
+	Type        ParamType   `json:"type"`
+	Default     interface{} `json:"default"`
+	Min         *float64    `json:"min,omitempty"` // Inclusive bounds of int and float values; nil = unbounded
+	Max         *float64    `json:"max,omitempty"`
+	Description string      `json:"description"`
+}
+
+// Bound returns a pointer to v for ParamSpec.Min and Max.
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// FinderInfo describes a registered clone finder.
+type FinderInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Params      []ParamSpec `json:"params"`   // Declared CustomParams; nil when Declared is false
+	Declared    bool        `json:"declared"` // Whether CustomParams are validated against Params
+}
+
+// FilterConfig configures one run of a Filter.
+type FilterConfig struct {
+	MinArchetypeLength int                    // Minimum archetype length in tokens ("strict")
+	RemoveOverlaps     bool                   // Remove overlapping fragments ("strict")
+	CustomParams       map[string]interface{} // Filter-specific parameters
+}
+
+// FilterSpec selects a registered filter and its configuration. Built-in
+// filters: "strict", "length" (min_tokens, max_tokens), "power" (min_power,
+// max_power), "exclude" (pattern) and "containment".
+type FilterSpec struct {
+	Name               string                 `json:"name" yaml:"name"`
+	MinArchetypeLength int                    `json:"min_archetype_length,omitempty" yaml:"min_archetype_length,omitempty"` // Drop groups with shorter archetypes (tokens)
+	RemoveOverlaps     bool                   `json:"remove_overlaps,omitempty" yaml:"remove_overlaps,omitempty"`           // Drop fragments overlapping an earlier one
+	Params             map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`                             // Filter-specific parameters
+}
+
+func (f FilterSpec) toInternal() internalFramework.FilterStep {
+	return internalFramework.FilterStep{
+		Name: f.Name,
+		Config: internalFramework.FilterConfig{
+			MinArchetypeLength: f.MinArchetypeLength,
+			RemoveOverlaps:     f.RemoveOverlaps,
+			CustomParams:       f.Params,
+		},
+	}
+}
+
+// PolicyCheck is the outcome of one policy rule.
+type PolicyCheck struct {
+	Rule    string `json:"rule"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// PolicyVerdict is the outcome of evaluating a policy against a result.
+type PolicyVerdict struct {
+	Passed bool          `json:"passed"`
+	Checks []PolicyCheck `json:"checks"`
+}
+
+// Violations returns the failed checks.
+func (v PolicyVerdict) Violations() []PolicyCheck {
+	var failed []PolicyCheck
+	for _, c := range v.Checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// Conversions between the public types and their internal counterparts.
+// Group and fragment metadata maps are shared, not copied; result metadata is
+// copied so that internal values stored there ("stale_baseline_entries",
+// "policy_verdict", and "stats" and "config" in report settings) can be
+// converted.
+
+func (c CloneFinderConfig) toInternal() internalFramework.CloneFinderConfig {
+	return internalFramework.CloneFinderConfig{
+		MinCloneLength:      c.MinCloneLength,
+		MaxCloneLength:      c.MaxCloneLength,
+		MinGroupPower:       c.MinGroupPower,
+		SimilarityThreshold: c.SimilarityThreshold,
+		SimilarityCalc:      c.SimilarityCalc,
+		CustomParams:        c.CustomParams,
+		Similarity:          c.Similarity,
+	}
+}
+
+func configFromInternal(c internalFramework.CloneFinderConfig) CloneFinderConfig {
+	return CloneFinderConfig{
+		MinCloneLength:      c.MinCloneLength,
+		MaxCloneLength:      c.MaxCloneLength,
+		MinGroupPower:       c.MinGroupPower,
+		SimilarityThreshold: c.SimilarityThreshold,
+		SimilarityCalc:      c.SimilarityCalc,
+		CustomParams:        c.CustomParams,
+		Similarity:          c.Similarity,
+	}
+}
+
+func (s ParamSpec) toInternal() internalFramework.ParamSpec {
+	return internalFramework.ParamSpec{
+		Name:        s.Name,
+		Type:        internalFramework.ParamType(s.Type),
+		Default:     s.Default,
+		Min:         s.Min,
+		Max:         s.Max,
+		Description: s.Description,
+	}
+}
+
+func paramSpecsToInternal(specs []ParamSpec) []internalFramework.ParamSpec {
+	out := make([]internalFramework.ParamSpec, len(specs))
+	for i, s := range specs {
+		out[i] = s.toInternal()
+	}
+	return out
+}
+
+func paramSpecFromInternal(s internalFramework.ParamSpec) ParamSpec {
+	return ParamSpec{
+		Name:        s.Name,
+		Type:        ParamType(s.Type),
+		Default:     s.Default,
+		Min:         s.Min,
+		Max:         s.Max,
+		Description: s.Description,
+	}
+}
+
+func (s AnalysisStatistics) toInternal() internalFramework.AnalysisStatistics {
+	return internalFramework.AnalysisStatistics(s)
+}
+
+func statsFromInternal(s internalFramework.AnalysisStatistics) AnalysisStatistics {
+	return AnalysisStatistics(s)
+}
+
+func (f TextFragment) toInternal() internalFramework.TextFragment {
+	return internalFramework.TextFragment(f)
+}
+
+func (g CloneGroup) toInternal() internalFramework.CloneGroup {
+	out := internalFramework.CloneGroup{Power: g.Power, Archetype: g.Archetype, Metadata: g.Metadata}
+	if g.Fragments != nil {
+		out.Fragments = make([]internalFramework.TextFragment, len(g.Fragments))
+		for i, f := range g.Fragments {
+			out.Fragments[i] = f.toInternal()
+		}
+	}
+	return out
+}
+
+func groupFromInternal(g internalFramework.CloneGroup) CloneGroup {
+	out := CloneGroup{Power: g.Power, Archetype: g.Archetype, Metadata: g.Metadata}
+	if g.Fragments != nil {
+		out.Fragments = make([]TextFragment, len(g.Fragments))
+		for i, f := range g.Fragments {
+			out.Fragments[i] = TextFragment(f)
+		}
+	}
+	return out
+}
+
+func groupsToInternal(groups []CloneGroup) []internalFramework.CloneGroup {
+	if groups == nil {
+		return nil
+	}
+	out := make([]internalFramework.CloneGroup, len(groups))
+	for i, g := range groups {
+		out[i] = g.toInternal()
+	}
+	return out
+}
+
+func groupsFromInternal(groups []internalFramework.CloneGroup) []CloneGroup {
+	if groups == nil {
+		return nil
+	}
+	out := make([]CloneGroup, len(groups))
+	for i, g := range groups {
+		out[i] = groupFromInternal(g)
+	}
+	return out
+}
+
+func (r *AnalysisResult) toInternal() *internalFramework.AnalysisResult {
+	if r == nil {
+		return nil
+	}
+	return &internalFramework.AnalysisResult{
+		Groups:     groupsToInternal(r.Groups),
+		Statistics: r.Statistics.toInternal(),
+		Metadata:   metadataToInternal(r.Metadata),
+		Config:     r.Config.toInternal(),
+	}
+}
+
+func resultFromInternal(r *internalFramework.AnalysisResult) *AnalysisResult {
+	if r == nil {
+		return nil
+	}
+	return &AnalysisResult{
+		Groups:     groupsFromInternal(r.Groups),
+		Statistics: statsFromInternal(r.Statistics),
+		Metadata:   metadataFromInternal(r.Metadata),
+		Config:     configFromInternal(r.Config),
+	}
+}
+
+func metadataToInternal(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		switch vv := v.(type) {
+		case AnalysisStatistics:
+			v = vv.toInternal()
+		case CloneFinderConfig:
+			v = vv.toInternal()
+		case PolicyVerdict:
+			v = vv.toInternal()
+		case []StaleBaselineEntry:
+			entries := make([]internalFramework.StaleBaselineEntry, len(vv))
+			for i, e := range vv {
+				entries[i] = e.toInternal()
+			}
+			v = entries
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func metadataFromInternal(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		switch vv := v.(type) {
+		case internalFramework.AnalysisStatistics:
+			v = statsFromInternal(vv)
+		case internalFramework.CloneFinderConfig:
+			v = configFromInternal(vv)
+		case internalFramework.PolicyVerdict:
+			v = verdictFromInternal(vv)
+		case []internalFramework.StaleBaselineEntry:
+			entries := make([]StaleBaselineEntry, len(vv))
+			for i, e := range vv {
+				entries[i] = staleEntryFromInternal(e)
+			}
+			v = entries
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func (e StaleBaselineEntry) toInternal() internalFramework.StaleBaselineEntry {
+	return internalFramework.StaleBaselineEntry{
+		Entry: internalFramework.BaselineEntry{
+			GroupID: e.Entry.GroupID,
+			Pattern: e.Entry.Pattern,
+			Reason:  e.Entry.Reason,
+			Expires: e.Entry.Expires,
+		},
+		Status: e.Status,
+	}
+}
+
+func staleEntryFromInternal(e internalFramework.StaleBaselineEntry) StaleBaselineEntry {
+	return StaleBaselineEntry{
+		Entry: BaselineEntry{
+			GroupID: e.Entry.GroupID,
+			Pattern: e.Entry.Pattern,
+			Reason:  e.Entry.Reason,
+			Expires: e.Entry.Expires,
+		},
+		Status: e.Status,
+	}
+}
+
+func (c *ResultComparison) toInternal() *internalFramework.ResultComparison {
+	return &internalFramework.ResultComparison{
+		New:         groupChangesToInternal(c.New),
+		Removed:     groupChangesToInternal(c.Removed),
+		Grown:       groupChangesToInternal(c.Grown),
+		Shrunk:      groupChangesToInternal(c.Shrunk),
+		Unchanged:   groupChangesToInternal(c.Unchanged),
+		OldSource:   c.OldSource,
+		NewSource:   c.NewSource,
+		TotalTokens: c.TotalTokens,
+	}
+}
+
+func comparisonFromInternal(c *internalFramework.ResultComparison) *ResultComparison {
+	return &ResultComparison{
+		New:         groupChangesFromInternal(c.New),
+		Removed:     groupChangesFromInternal(c.Removed),
+		Grown:       groupChangesFromInternal(c.Grown),
+		Shrunk:      groupChangesFromInternal(c.Shrunk),
+		Unchanged:   groupChangesFromInternal(c.Unchanged),
+		OldSource:   c.OldSource,
+		NewSource:   c.NewSource,
+		TotalTokens: c.TotalTokens,
+	}
+}
+
+func groupChangesToInternal(changes []GroupChange) []internalFramework.GroupChange {
+	if changes == nil {
+		return nil
+	}
+	out := make([]internalFramework.GroupChange, len(changes))
+	for i, ch := range changes {
+		out[i] = internalFramework.GroupChange{ID: ch.ID, Kind: ch.Kind, OldPower: ch.OldPower, NewPower: ch.NewPower, Group: ch.Group.toInternal()}
+	}
+	return out
+}
+
+func groupChangesFromInternal(changes []internalFramework.GroupChange) []GroupChange {
+	if changes == nil {
+		return nil
+	}
+	out := make([]GroupChange, len(changes))
+	for i, ch := range changes {
+		out[i] = GroupChange{ID: ch.ID, Kind: ch.Kind, OldPower: ch.OldPower, NewPower: ch.NewPower, Group: groupFromInternal(ch.Group)}
+	}
+	return out
+}
+
+func (p *Policy) toInternal() *internalFramework.Policy {
+	return &internalFramework.Policy{
+		MaxGroups:               p.MaxGroups,
+		MaxDuplicatedTokenRatio: p.MaxDuplicatedTokenRatio,
+		MaxGroupPower:           p.MaxGroupPower,
+		NoNewGroups:             p.NoNewGroups,
+	}
+}
+
+func policyFromInternal(p *internalFramework.Policy) *Policy {
+	return &Policy{
+		MaxGroups:               p.MaxGroups,
+		MaxDuplicatedTokenRatio: p.MaxDuplicatedTokenRatio,
+		MaxGroupPower:           p.MaxGroupPower,
+		NoNewGroups:             p.NoNewGroups,
+	}
+}
+
+func (v PolicyVerdict) toInternal() internalFramework.PolicyVerdict {
+	out := internalFramework.PolicyVerdict{Passed: v.Passed}
+	for _, c := range v.Checks {
+		out.Checks = append(out.Checks, internalFramework.PolicyCheck(c))
+	}
+	return out
+}
+
+func verdictFromInternal(v internalFramework.PolicyVerdict) PolicyVerdict {
+	out := PolicyVerdict{Passed: v.Passed}
+	for _, c := range v.Checks {
+		out.Checks = append(out.Checks, PolicyCheck(c))
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func TestAnalyzeText_MatchesAnalyzeDocument(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := writeTermsDoc(t, tmpDir)
+	data, err := os.ReadFile(docPath)
+	if err != nil {
+		t.Fatalf("read doc: %v", err)
+	}
+	d := docline.New(&docline.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+
+	fromFile, err := d.AnalyzeDocumentWithConfig(docPath, "terminology", docline.CloneFinderConfig{})
+	if err != nil {
+		t.Fatalf("AnalyzeDocumentWithConfig: %v", err)
+	}
+	fromText, err := d.AnalyzeReaderWithConfig(strings.NewReader(string(data)), "xml", "terminology", docline.CloneFinderConfig{})
+	if err != nil {
+		t.Fatalf("AnalyzeReaderWithConfig: %v", err)
+	}
+
+	if fromText.Statistics != fromFile.Statistics {
+		t.Fatalf("statistics differ:\n text %+v\n file %+v", fromText.Statistics, fromFile.Statistics)
+	}
+	if docline.CompareResults(fromFile, fromText).HasChanges() {
+		t.Fatal("in-memory analysis found different groups")
+	}
+	if src := fromText.Metadata["source_file"]; src != "" {
+		t.Fatalf("expected empty source_file, got %v", src)
+	}
+}
+
+func TestAnalyzeText_HeuristicWritesNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	d := docline.New(&docline.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+	<d:DocumentationCore xmlns:d="https://docbook.org/ns/docbook/">
+	<d:InfElement>alpha	beta
+	alpha	beta</d:InfElement>
+	</d:DocumentationCore>`
+	result, err := d.AnalyzeText(input, ".drl", docline.HeuristicConfig{MinCloneLength: 2, ExtensionPointCheckbox: true})
+	if err != nil {
+		t.Fatalf("AnalyzeText: %v", err)
+	}
+	if _, ok := result.Metadata["reformatted_file"]; ok {
+		t.Fatalf("unexpected reformatted_file metadata: %v", result.Metadata)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no files, found %s", filepath.Join(tmpDir, entries[0].Name()))
+	}
+
+	// Plain text needs neither a parser nor a converter.
+	plain, err := d.AnalyzeReaderWithConfig(strings.NewReader("one two three. one two three."), "", "automatic", docline.CloneFinderConfig{MinCloneLength: 3, MinGroupPower: 2})
+	if err != nil {
+		t.Fatalf("AnalyzeReaderWithConfig plain: %v", err)
+	}
+	if plain.Statistics.TotalTokens != 6 {
+		t.Fatalf("expected 6 tokens, got %d", plain.Statistics.TotalTokens)
+	}
+}
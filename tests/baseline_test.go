@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func TestBaseline_SuppressesAndFlagsStaleEntries(t *testing.T) {
+	legal := framework.CloneGroup{
+		Archetype: "All rights reserved",
+		Power:     2,
+		Fragments: []framework.TextFragment{
+			{Content: "All rights reserved", StartPos: 0, EndPos: 3},
+			{Content: "All rights reserved", StartPos: 10, EndPos: 13},
+		},
+	}
+	warning := framework.CloneGroup{
+		Archetype: "Do not touch the heating element",
+		Power:     2,
+		Fragments: []framework.TextFragment{
+			{Content: "Do not touch the heating element", StartPos: 20, EndPos: 26},
+			{Content: "Do not touch the heating element", StartPos: 40, EndPos: 46},
+		},
+	}
+	other := framework.CloneGroup{Archetype: "something else entirely", Power: 2}
+
+	b := &framework.Baseline{Version: 1, Entries: []framework.BaselineEntry{
+		{GroupID: framework.GroupID(legal), Reason: "legal notice"},
+		{Pattern: `^do not touch`, Reason: "safety warning", Expires: "2100-01-01"},
+		{Pattern: `obsolete`, Reason: "no longer present"},
+		{GroupID: framework.GroupID(other), Reason: "expired", Expires: "2000-01-01"},
+	}}
+	if err := b.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	groups := []framework.CloneGroup{legal, warning, other}
+	stale := b.Apply(groups, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if !framework.IsSuppressed(groups[0]) || !framework.IsSuppressed(groups[1]) {
+		t.Fatalf("expected legal notice and warning to be suppressed")
+	}
+	if framework.IsSuppressed(groups[2]) {
+		t.Fatalf("expired entry must not suppress")
+	}
+	if got := groups[1].Metadata[framework.SuppressionReasonMetadataKey]; got != "safety warning" {
+		t.Fatalf("unexpected suppression reason: %v", got)
+	}
+
+	statuses := map[string]string{}
+	for _, s := range stale {
+		statuses[s.Entry.Reason] = s.Status
+	}
+	if statuses["no longer present"] != "unmatched" || statuses["expired"] != "expired" || len(stale) != 2 {
+		t.Fatalf("unexpected stale entries: %+v", stale)
+	}
+}
+
+func TestBaseline_LoadRejectsInvalidEntries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.json")
+	if err := os.WriteFile(path, []byte(`{"version":1,"entries":[{"reason":"missing target"}]}`), 0o644); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+	if _, err := framework.LoadBaseline(path); err == nil {
+		t.Fatal("expected error for entry without group_id or pattern")
+	}
+}
+
+func TestFramework_AnalyzeDocument_AppliesBaseline(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := filepath.Join(tmpDir, "doc.xml")
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<book>
+	<para>read the safety notice first</para>
+	<para>some text</para>
+	<para>read the safety notice first</para>
+</book>`
+	if err := os.WriteFile(docPath, []byte(doc), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+	baselinePath := filepath.Join(tmpDir, "baseline.json")
+	if err := os.WriteFile(baselinePath, []byte(`{"version":1,"entries":[{"pattern":"safety","reason":"accepted"}]}`), 0o644); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	fw := newTestFramework(t, &framework.Config{
+		ResultsDirectory: tmpDir,
+		DefaultTokenizer: "space",
+		BaselineFile:     baselinePath,
+	})
+
+	result, err := fw.AnalyzeDocument(docPath, "automatic", framework.CloneFinderConfig{
+		MinCloneLength: 5,
+		CustomParams:   map[string]interface{}{"convert_to_drl": false, "strict_filter": false},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeDocument: %v", err)
+	}
+	if len(result.Groups) == 0 {
+		t.Fatal("expected at least one clone group")
+	}
+	if result.Statistics.SuppressedGroups != len(result.Groups) {
+		t.Fatalf("expected all %d groups suppressed, got %d", len(result.Groups), result.Statistics.SuppressedGroups)
+	}
+
+	outPath := filepath.Join(tmpDir, "report.html")
+	if err := fw.GenerateReport(result, "html", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	html, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	if !strings.Contains(string(html), "accepted") {
+		t.Fatalf("expected accepted marker in HTML report")
+	}
+}
@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func cloneGroup(archetype string, power int) framework.CloneGroup {
+	g := framework.CloneGroup{Archetype: archetype, Power: power}
+	for i := 0; i < power; i++ {
+		g.Fragments = append(g.Fragments, framework.TextFragment{Content: archetype, StartPos: i * 10, EndPos: i*10 + 3})
+	}
+	return g
+}
+
+func TestCompareResults_ClassifiesGroups(t *testing.T) {
+	old := &framework.AnalysisResult{
+		Groups: []framework.CloneGroup{
+			cloneGroup("kept as is", 2),
+			cloneGroup("will grow", 2),
+			cloneGroup("will shrink", 3),
+			cloneGroup("will disappear", 2),
+		},
+		Metadata: map[string]interface{}{"source_file": "old.xml"},
+	}
+	new := &framework.AnalysisResult{
+		Groups: []framework.CloneGroup{
+			// Identity ignores case and whitespace differences.
+			cloneGroup("Kept  as is", 2),
+			cloneGroup("will grow", 4),
+			cloneGroup("will shrink", 2),
+			cloneGroup("brand new", 2),
+		},
+		Metadata: map[string]interface{}{"source_file": "new.xml"},
+	}
+
+	cmp := framework.CompareResults(old, new)
+	if len(cmp.New) != 1 || len(cmp.Removed) != 1 || len(cmp.Grown) != 1 || len(cmp.Shrunk) != 1 || len(cmp.Unchanged) != 1 {
+		t.Fatalf("unexpected classification: new=%d removed=%d grown=%d shrunk=%d unchanged=%d",
+			len(cmp.New), len(cmp.Removed), len(cmp.Grown), len(cmp.Shrunk), len(cmp.Unchanged))
+	}
+	if cmp.Grown[0].OldPower != 2 || cmp.Grown[0].NewPower != 4 {
+		t.Fatalf("unexpected grown powers: %+v", cmp.Grown[0])
+	}
+	if cmp.Removed[0].Group.Archetype != "will disappear" {
+		t.Fatalf("unexpected removed group: %+v", cmp.Removed[0])
+	}
+	if !cmp.HasChanges() {
+		t.Fatal("expected changes")
+	}
+}
+
+func TestCompareResults_RendersWithReportGenerators(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	cmp := framework.CompareResults(nil, &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{cloneGroup("brand new", 2)},
+		Metadata: map[string]interface{}{"source_file": "new.xml"},
+	})
+	result := cmp.AsAnalysisResult()
+	if got := result.Groups[0].Metadata[framework.DiffStatusMetadataKey]; got != framework.GroupNew {
+		t.Fatalf("expected diff_status=new, got %v", got)
+	}
+
+	for _, format := range []string{"html", "json", "csv"} {
+		if err := fw.GenerateReport(result, format, filepath.Join(tmpDir, "diff."+format)); err != nil {
+			t.Fatalf("GenerateReport(%s): %v", format, err)
+		}
+	}
+}
+
+// twinFinder reports the same clone group twice.
+type twinFinder struct{}
+
+func (twinFinder) Name() string        { return "test-twin" }
+func (twinFinder) Description() string { return "Reports one group twice" }
+func (twinFinder) FindClones(string, framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
+	return []framework.CloneGroup{cloneGroup("save your work", 2), cloneGroup("save your work", 2)}, nil
+}
+
+func TestGroupID_StableAcrossFragmentEdits(t *testing.T) {
+	fuzzy := func(variants ...string) framework.CloneGroup {
+		g := cloneGroup("save your work", len(variants)+1)
+		for i, v := range variants {
+			g.Fragments[i+1].Content = v
+		}
+		return g
+	}
+	// Editing one occurrence of a fuzzy group keeps its ID.
+	if framework.GroupID(fuzzy("save your work now")) != framework.GroupID(fuzzy("save all your work")) {
+		t.Fatal("the group ID should depend on the archetype only")
+	}
+	if framework.GroupID(cloneGroup("Save your  work", 3)) != framework.GroupID(framework.CloneGroup{Archetype: "save your work"}) {
+		t.Fatal("the group ID should ignore case, whitespace and power")
+	}
+
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: t.TempDir()})
+	if err := fw.GetRegistry().RegisterCloneFinder(twinFinder{}); err != nil {
+		t.Fatalf("RegisterCloneFinder: %v", err)
+	}
+	result, err := fw.AnalyzeText("save your work", "", "test-twin", framework.CloneFinderConfig{})
+	if err != nil {
+		t.Fatalf("AnalyzeText: %v", err)
+	}
+	first, second := result.Groups[0].Metadata[framework.GroupIDMetadataKey], result.Groups[1].Metadata[framework.GroupIDMetadataKey]
+	if first != framework.GroupID(result.Groups[0]) || second != first.(string)+"-2" {
+		t.Fatalf("expected the repeated ID to be suffixed, got %v and %v", first, second)
+	}
+
+	// Without stored IDs, CompareResults keeps colliding groups apart too.
+	twins := &framework.AnalysisResult{Groups: []framework.CloneGroup{cloneGroup("save your work", 2), cloneGroup("save your work", 2)}}
+	if cmp := framework.CompareResults(nil, twins); len(cmp.New) != 2 || cmp.New[0].ID == cmp.New[1].ID {
+		t.Fatalf("expected both groups to be reported as new with distinct IDs, got %+v", cmp.New)
+	}
+
+	// Groups sharing an archetype are paired by their fragment texts, whatever
+	// their order: the edited group grew, the other one is unchanged.
+	a := fuzzy("save your work now")
+	b := fuzzy("save all your work")
+	grownA := fuzzy("save your work now", "save your work today")
+	old := &framework.AnalysisResult{Groups: []framework.CloneGroup{a, b}}
+	new := &framework.AnalysisResult{Groups: []framework.CloneGroup{b, grownA}}
+	cmp := framework.CompareResults(old, new)
+	if len(cmp.New)+len(cmp.Removed) != 0 || len(cmp.Grown) != 1 || len(cmp.Unchanged) != 1 {
+		t.Fatalf("expected one grown and one unchanged group, got %d new, %d removed, %d grown, %d unchanged",
+			len(cmp.New), len(cmp.Removed), len(cmp.Grown), len(cmp.Unchanged))
+	}
+	if cmp.Grown[0].Group.Power != 3 || cmp.Grown[0].OldPower != 2 {
+		t.Fatalf("wrong groups paired: %+v", cmp.Grown[0])
+	}
+}
+
+func TestCompareResults_AsAnalysisResultStatistics(t *testing.T) {
+	old := &framework.AnalysisResult{Groups: []framework.CloneGroup{cloneGroup("will disappear from here", 2)}}
+	new := &framework.AnalysisResult{
+		Groups:     []framework.CloneGroup{cloneGroup("brand new", 3)},
+		Statistics: framework.AnalysisStatistics{TotalTokens: 100},
+	}
+
+	stats := framework.CompareResults(old, new).AsAnalysisResult().Statistics
+	want := framework.AnalysisStatistics{
+		TotalGroups:    2,
+		TotalFragments: 5,
+		MinTokens:      2,
+		MaxTokens:      4,
+		AvgTokens:      float64(3*2+2*4) / 5,
+		MaxGroupPower:  3,
+		// Only the new group: the removed one's positions are in the old text
+		DuplicatedTokens: 9,
+		TotalTokens:      100,
+	}
+	if stats != want {
+		t.Fatalf("statistics = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestConfig_ProfilesAndInputs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "docs", "guide.xml"), "<book/>")
+	writeFile(t, filepath.Join(dir, "docs", "api", "ref.xml"), "<book/>")
+	writeFile(t, filepath.Join(dir, "docs", "drafts", "wip.xml"), "<book/>")
+	writeFile(t, filepath.Join(dir, "docs", "notes.txt"), "notes")
+	writeFile(t, filepath.Join(dir, "docline.yaml"), `
+version: 1
+inputs: ["docs/**/*.xml"]
+exclude: ["docs/drafts/**"]
+finder: automatic
+min_clone_length: 20
+params: {strict_filter: true, max_group_size: 8}
+filters:
+  - name: strict
+    min_archetype_length: 5
+reports: {formats: [html, sarif]}
+default_profile: ci
+profiles:
+  ci:
+    min_clone_length: 10
+    policy: docline-policy.json
+  exploratory:
+    finder: ngram
+    params: {strict_filter: false}
+    filters: []
+`)
+
+	if got := docline.FindConfig(dir); got != filepath.Join(dir, "docline.yaml") {
+		t.Fatalf("FindConfig = %q", got)
+	}
+	cfg, err := docline.LoadConfig(filepath.Join(dir, "docline.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	ci, err := cfg.Profile("")
+	if err != nil {
+		t.Fatalf("Profile(default): %v", err)
+	}
+	if *ci.MinCloneLength != 10 || ci.Finder != "automatic" || len(ci.Filters) != 1 {
+		t.Fatalf("default profile should override min_clone_length only, got %+v", ci)
+	}
+	if ci.Policy != filepath.Join(dir, "docline-policy.json") || ci.Reports.OutputDir != filepath.Join(dir, "results") {
+		t.Fatalf("paths should be resolved against the config directory, got policy %q, output %q", ci.Policy, ci.Reports.OutputDir)
+	}
+
+	files, err := ci.Files()
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	want := []string{filepath.Join(dir, "docs", "api", "ref.xml"), filepath.Join(dir, "docs", "guide.xml")}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("Files = %v, want %v", files, want)
+	}
+
+	exploratory, err := cfg.Profile("exploratory")
+	if err != nil {
+		t.Fatalf("Profile(exploratory): %v", err)
+	}
+	fc := exploratory.FinderConfig()
+	if exploratory.Finder != "ngram" || fc.MinCloneLength != 20 || len(exploratory.Filters) != 0 {
+		t.Fatalf("unexpected exploratory settings: %+v", exploratory)
+	}
+	if fc.CustomParams["strict_filter"] != false || fc.CustomParams["max_group_size"] != 8 {
+		t.Fatalf("params should be merged key by key, got %v", fc.CustomParams)
+	}
+	// Profiles do not leak into the top-level settings.
+	if again, _ := cfg.Profile("ci"); len(again.Filters) != 1 || again.Params["strict_filter"] != true {
+		t.Fatalf("profile merge modified the top-level settings: %+v", again)
+	}
+
+	if _, err := cfg.Profile("missing"); err == nil || !strings.Contains(err.Error(), "exploratory") {
+		t.Fatalf("expected unknown profile error listing the profiles, got %v", err)
+	}
+}
+
+func TestConfig_Validation(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"unknown.yaml":  "min_clone_lenght: 10\n",
+		"range.yaml":    "similarity_threshold: 1.5\n",
+		"order.json":    `{"min_clone_length": 30, "max_clone_length": 10}`,
+		"profile.yaml":  "default_profile: ci\n",
+		"glob.yaml":     "inputs: [\"docs/[*.xml\"]\n",
+		"version.json":  `{"version": 2}`,
+		"unknown.json":  `{"reports": {"format": "html"}}`,
+		"filter.yaml":   "filters: [{min_archetype_length: 3}]\n",
+		"negative.yaml": "profiles: {ci: {min_group_power: -1}}\n",
+	} {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, content)
+		if _, err := docline.LoadConfig(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	path := filepath.Join(dir, "docline.json")
+	writeFile(t, path, `{"version": 1, "finder": "ngram", "params": {"n": 4}}`)
+	cfg, err := docline.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig(json): %v", err)
+	}
+	settings, _ := cfg.Profile("")
+	if c := settings.Config(); c.DefaultCloneFinder != "ngram" || c.DefaultReportFormat != "html" {
+		t.Fatalf("unexpected Config(): %+v", c)
+	}
+	if _, err := settings.Files(); err != nil {
+		t.Fatalf("Files without inputs: %v", err)
+	}
+
+	settings.Inputs = []string{filepath.Join(dir, "nothing", "*.xml")}
+	if _, err := settings.Files(); err == nil {
+		t.Fatal("expected an error for an input matching no files")
+	}
+}
+
+func TestConfig_AppliesParserAndFilterSettings(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "guide.xml")
+	writeFile(t, doc, `<?xml version="1.0" encoding="UTF-8"?>
+<book>
+  <title>Installation guide</title>
+  <para>Always press the save button before you close the editor window.</para>
+  <para>Always press the save button before you close the editor window.</para>
+</book>
+`)
+	cfgPath := filepath.Join(dir, "docline.yaml")
+	writeFile(t, cfgPath, `
+min_clone_length: 5
+profiles:
+  titles:
+    parsers: {docbook: {text_elements: [title]}}
+  long:
+    filters: [{name: strict, min_archetype_length: 1000}]
+`)
+	cfg, err := docline.LoadConfig(cfgPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	analyze := func(profile string) *docline.AnalysisResult {
+		t.Helper()
+		settings, err := cfg.Profile(profile)
+		if err != nil {
+			t.Fatalf("Profile(%q): %v", profile, err)
+		}
+		settings.Reports.OutputDir = t.TempDir()
+		result, err := docline.New(settings.Config()).AnalyzeDocumentWithConfig(doc, "automatic", settings.FinderConfig())
+		if err != nil {
+			t.Fatalf("analyze with profile %q: %v", profile, err)
+		}
+		return result
+	}
+
+	if result := analyze(""); len(result.Groups) == 0 {
+		t.Fatal("expected the repeated paragraph to be reported")
+	}
+	if result := analyze("titles"); len(result.Groups) != 0 {
+		t.Fatalf("paragraph text should be ignored with text_elements [title], got %d groups", len(result.Groups))
+	}
+	if result := analyze("long"); len(result.Groups) != 0 || result.Statistics.TotalGroups != 0 {
+		t.Fatalf("the configured filter should drop every group, got %d", len(result.Groups))
+	}
+}
+
+func TestAnalyze_ConfiguredInputsWithSameBaseName(t *testing.T) {
+	exe := buildDocline(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "docline.yaml"), "inputs: [\"docs/**/*.xml\"]\nreports: {formats: [json], output_dir: out}\n")
+	for _, sub := range []string{"a", "b"} {
+		writeFile(t, filepath.Join(dir, "docs", sub, "index.xml"), "<book>\n  <para>Nothing repeats in "+sub+".</para>\n</book>\n")
+	}
+
+	if out, code := runIn(t, dir, nil, exe, "analyze"); code != 0 {
+		t.Fatalf("analyze: exit %d\n%s", code, out)
+	}
+	for _, sub := range []string{"a", "b"} {
+		report := filepath.Join(dir, "out", "docs", sub, "index.json")
+		data, err := os.ReadFile(report)
+		if err != nil || !strings.Contains(string(data), filepath.Join("docs", sub, "index.xml")) {
+			t.Fatalf("report %s (%v):\n%s", report, err, data)
+		}
+	}
+
+	// Files given on the command line are named by their base name.
+	out, code := runIn(t, dir, nil, exe, "analyze", "docs/a/index.xml", "docs/b/index.xml")
+	if code != 2 || !strings.Contains(out, "would both write the report") {
+		t.Fatalf("analyze with colliding report paths: exit %d\n%s", code, out)
+	}
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"strings"
 	"testing"
 
 	rep "github.com/PavelMkr/docline-new/internal/report"
@@ -15,3 +16,10 @@ func TestIsConversionNeeded(t *testing.T) {
 		t.Error("expected no conversion needed for .xml")
 	}
 }
+
+func TestConvertReaderToDocBook_RejectsUnsupportedFormat(t *testing.T) {
+	conv := rep.NewDocumentConverter()
+	if _, err := conv.ConvertReaderToDocBook(strings.NewReader("x"), ".pdf"); err == nil {
+		t.Error("expected error for .pdf input")
+	}
+}
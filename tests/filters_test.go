@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func fragmentAt(content string, start int) framework.TextFragment {
+	return framework.TextFragment{Content: content, StartPos: start, EndPos: start + len(strings.Fields(content)) - 1}
+}
+
+func groupOf(fragments ...framework.TextFragment) framework.CloneGroup {
+	return framework.CloneGroup{Archetype: fragments[0].Content, Power: len(fragments), Fragments: fragments}
+}
+
+func archetypes(groups []framework.CloneGroup) []string {
+	out := make([]string, len(groups))
+	for i, g := range groups {
+		out[i] = g.Archetype
+	}
+	return out
+}
+
+func TestFilters_BuiltIns(t *testing.T) {
+	long := groupOf(fragmentAt("save your work before you close the editor", 0), fragmentAt("save your work before you close the editor", 40))
+	inner := groupOf(fragmentAt("save your work", 0), fragmentAt("save your work", 40))
+	triple := groupOf(fragmentAt("click OK", 10), fragmentAt("click OK", 20), fragmentAt("click OK", 30))
+	legal := groupOf(fragmentAt("Copyright 2024 Example Corp", 60), fragmentAt("Copyright 2024 Example Corp", 90))
+	groups := []framework.CloneGroup{long, inner, triple, legal}
+
+	reg := framework.NewPluginRegistry()
+	if err := framework.RegisterBuiltInPlugins(reg); err != nil {
+		t.Fatalf("RegisterBuiltInPlugins: %v", err)
+	}
+	run := func(name string, params map[string]interface{}) []string {
+		t.Helper()
+		filter, err := reg.GetFilter(name)
+		if err != nil {
+			t.Fatalf("GetFilter(%s): %v", name, err)
+		}
+		cfg := framework.FilterConfig{CustomParams: params}
+		if err := framework.ValidateFilter(filter, cfg); err != nil {
+			t.Fatalf("ValidateFilter(%s): %v", name, err)
+		}
+		return archetypes(filter.Filter(groups, cfg))
+	}
+
+	for _, tc := range []struct {
+		name   string
+		params map[string]interface{}
+		want   []string
+	}{
+		{"length", map[string]interface{}{"min_tokens": 3}, []string{long.Archetype, inner.Archetype, legal.Archetype}},
+		{"length", map[string]interface{}{"min_tokens": 3, "max_tokens": 4}, []string{inner.Archetype, legal.Archetype}},
+		{"power", map[string]interface{}{"min_power": 3}, []string{triple.Archetype}},
+		{"power", map[string]interface{}{"max_power": 2}, []string{long.Archetype, inner.Archetype, legal.Archetype}},
+		{"exclude", map[string]interface{}{"pattern": `(?i)^copyright\b`}, []string{long.Archetype, inner.Archetype, triple.Archetype}},
+		{"containment", nil, []string{long.Archetype, triple.Archetype, legal.Archetype}},
+	} {
+		if got := run(tc.name, tc.params); strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("%s %v = %q, want %q", tc.name, tc.params, got, tc.want)
+		}
+	}
+
+	// Equal groups contain each other; only the first survives.
+	containment, _ := reg.GetFilter("containment")
+	if got := containment.Filter([]framework.CloneGroup{triple, triple}, framework.FilterConfig{}); len(got) != 1 {
+		t.Fatalf("expected one of two equal groups to be kept, got %d", len(got))
+	}
+
+	exclude, _ := reg.GetFilter("exclude")
+	for _, params := range []map[string]interface{}{nil, {"pattern": "("}, {"patern": "x"}} {
+		if err := framework.ValidateFilter(exclude, framework.FilterConfig{CustomParams: params}); err == nil {
+			t.Errorf("exclude %v: expected a validation error", params)
+		}
+	}
+}
+
+// dropAllFilter is a public filter removing every group.
+type dropAllFilter struct{}
+
+func (dropAllFilter) Name() string { return "test-drop-all" }
+func (dropAllFilter) Filter([]docline.CloneGroup, docline.FilterConfig) []docline.CloneGroup {
+	return nil
+}
+
+func TestFilters_PipelineRunsAfterEveryFinder(t *testing.T) {
+	text := strings.Repeat("Always press the save button before you close the editor window. ", 3) +
+		"Copyright 2024 Example Corp. Copyright 2024 Example Corp."
+	cfg := docline.CloneFinderConfig{MinCloneLength: 4, CustomParams: map[string]interface{}{"strict_filter": false}}
+	analyze := func(filters ...docline.FilterSpec) (*docline.AnalysisResult, error) {
+		d := docline.New(&docline.Config{ResultsDirectory: t.TempDir(), DefaultTokenizer: "space", Filters: filters})
+		if err := d.RegisterFilter(dropAllFilter{}); err != nil {
+			t.Fatalf("RegisterFilter: %v", err)
+		}
+		return d.AnalyzeReaderWithConfig(strings.NewReader(text), "", "automatic", cfg)
+	}
+
+	all, err := analyze()
+	if err != nil {
+		t.Fatalf("analyze without filters: %v", err)
+	}
+	filtered, err := analyze(
+		docline.FilterSpec{Name: "exclude", Params: map[string]interface{}{"pattern": "(?i)copyright"}},
+		docline.FilterSpec{Name: "containment"},
+	)
+	if err != nil {
+		t.Fatalf("analyze with filters: %v", err)
+	}
+	if len(filtered.Groups) == 0 || len(filtered.Groups) >= len(all.Groups) || filtered.Statistics.TotalGroups != len(filtered.Groups) {
+		t.Fatalf("expected fewer but some groups after filtering: %d of %d (stats %d)", len(filtered.Groups), len(all.Groups), filtered.Statistics.TotalGroups)
+	}
+	for _, g := range filtered.Groups {
+		if strings.Contains(strings.ToLower(g.Archetype), "copyright") || g.Metadata[docline.GroupIDMetadataKey] == nil {
+			t.Errorf("unexpected group after the pipeline: %+v", g)
+		}
+	}
+
+	if custom, err := analyze(docline.FilterSpec{Name: "test-drop-all"}); err != nil || len(custom.Groups) != 0 {
+		t.Fatalf("custom filter: %d groups, %v", len(custom.Groups), err)
+	}
+	if _, err := analyze(docline.FilterSpec{Name: "length", Params: map[string]interface{}{"min_tokens": -1}}); err == nil || !strings.Contains(err.Error(), "length") {
+		t.Fatalf("expected an invalid filter parameter to fail the analysis, got %v", err)
+	}
+	if _, err := analyze(docline.FilterSpec{Name: "missing"}); err == nil {
+		t.Fatal("expected an unknown filter to fail the analysis")
+	}
+	d := docline.New(&docline.Config{})
+	if err := d.RegisterFilter(dropAllFilter{}); err != nil || d.RegisterFilter(dropAllFilter{}) == nil {
+		t.Fatalf("expected the second registration to be rejected (first: %v)", err)
+	}
+}
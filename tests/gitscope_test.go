@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/gitscope"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestGitScope_KeepsGroupsOnChangedLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+
+	old := []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<book>`,
+		`  <para>Always press the save button before you close the editor window.</para>`,
+		`  <para>Always press the save button before you close the editor window.</para>`,
+		`  <para>Nothing in this sentence repeats anywhere else in the guide.</para>`,
+		`</book>`,
+	}
+	path := filepath.Join(dir, "docs", "guide.xml")
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	os.WriteFile(path, []byte(strings.Join(old, "\n")+"\n"), 0o644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "first")
+
+	// The new revision repeats a different sentence on lines 6 and 7.
+	updated := append(append([]string{}, old[:5]...),
+		`  <para>Backups are written every night to the shared storage volume.</para>`,
+		`  <para>Backups are written every night to the shared storage volume.</para>`,
+		`</book>`)
+	os.WriteFile(path, []byte(strings.Join(updated, "\n")+"\n"), 0o644)
+	runGit(t, dir, "commit", "-q", "-am", "second")
+
+	repo, err := gitscope.Open(filepath.Join(dir, "docs"), "HEAD~1..HEAD")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	changed, err := repo.ChangedLines()
+	if err != nil {
+		t.Fatalf("ChangedLines: %v", err)
+	}
+	if want := map[string][]gitscope.LineRange{"docs/guide.xml": {{Start: 6, End: 7}}}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("ChangedLines = %v, want %v", changed, want)
+	}
+
+	// The working tree is edited after the commit; the range end is read.
+	os.WriteFile(path, []byte("<book/>"), 0o644)
+	source, err := repo.ReadFile("docs/guide.xml")
+	if err != nil || !strings.Contains(string(source), "Backups") {
+		t.Fatalf("ReadFile should return the committed content, got %q, %v", source, err)
+	}
+
+	d := docline.New(&docline.Config{DefaultTokenizer: "space"})
+	cfg := docline.CloneFinderConfig{MinCloneLength: 5}
+	result, err := gitscope.Analyze(d, "docs/guide.xml", source, changed["docs/guide.xml"], "automatic", cfg)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(result.Groups) == 0 || result.Statistics.TotalGroups != len(result.Groups) {
+		t.Fatalf("expected groups of the new sentence, got %d (stats %d)", len(result.Groups), result.Statistics.TotalGroups)
+	}
+	for _, g := range result.Groups {
+		for _, f := range g.Fragments {
+			line := f.Metadata["source_line_start"]
+			if line != 6 && line != 7 {
+				t.Errorf("fragment %q should be on source line 6 or 7, got %v", f.Content, line)
+			}
+			if f.Metadata[gitscope.ChangedMetadataKey] != true {
+				t.Errorf("fragment %q should be marked as changed", f.Content)
+			}
+		}
+	}
+
+	// Without the range the unchanged duplicate is found as well.
+	all, err := d.AnalyzeReaderWithConfig(strings.NewReader(string(source)), ".xml", "automatic", cfg)
+	if err != nil {
+		t.Fatalf("AnalyzeReaderWithConfig: %v", err)
+	}
+	if len(all.Groups) <= len(result.Groups) {
+		t.Fatalf("expected the full analysis to find more groups (%d) than the scoped one (%d)", len(all.Groups), len(result.Groups))
+	}
+}
+
+func TestGitScope_Staged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	path := filepath.Join(dir, "guide.xml")
+	os.WriteFile(path, []byte("<book>\n<para>one</para>\n</book>\n"), 0o644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "first")
+
+	os.WriteFile(path, []byte("<book>\n<para>one</para>\n<para>two</para>\n</book>\n"), 0o644)
+	runGit(t, dir, "add", "guide.xml")
+	// Unstaged edits are not part of the commit.
+	os.WriteFile(path, []byte("<book>\n<para>unstaged</para>\n</book>\n"), 0o644)
+
+	repo, err := gitscope.OpenStaged(dir)
+	if err != nil {
+		t.Fatalf("OpenStaged: %v", err)
+	}
+	changed, err := repo.ChangedLines()
+	if err != nil {
+		t.Fatalf("ChangedLines: %v", err)
+	}
+	if want := map[string][]gitscope.LineRange{"guide.xml": {{Start: 3, End: 3}}}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("ChangedLines = %v, want %v", changed, want)
+	}
+	if staged, err := repo.ReadFile("guide.xml"); err != nil || !strings.Contains(string(staged), "two") {
+		t.Fatalf("ReadFile should return the staged content, got %q, %v", staged, err)
+	}
+	if head, err := repo.ReadRevision("HEAD", "guide.xml"); err != nil || strings.Contains(string(head), "two") {
+		t.Fatalf("ReadRevision(HEAD) should return the committed content, got %q, %v", head, err)
+	}
+	if hook, err := gitscope.HookPath(dir, "pre-commit"); err != nil || !strings.HasSuffix(filepath.ToSlash(hook), ".git/hooks/pre-commit") {
+		t.Fatalf("HookPath = %q, %v", hook, err)
+	}
+}
+
+func TestGitScope_DiffHeadersAndBinaryFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes\na\nb\nc\n"), 0o644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "first")
+
+	// An added line starting with "++" shows up as "+++ ..." in the diff; the
+	// next hunk still belongs to notes.md.
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes\n++ other.md\na\nb\nc\nend\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "guide.docx"), []byte("PK\x03\x04\x00binary\x00content"), 0o644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "second")
+
+	repo, err := gitscope.Open(dir, "HEAD~1..HEAD")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	changed, err := repo.ChangedLines()
+	if err != nil {
+		t.Fatalf("ChangedLines: %v", err)
+	}
+	if want := map[string][]gitscope.LineRange{"notes.md": {{Start: 2, End: 2}, {Start: 6, End: 6}}}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("ChangedLines = %v, want %v", changed, want)
+	}
+	binaries, err := repo.BinaryFiles()
+	if err != nil {
+		t.Fatalf("BinaryFiles: %v", err)
+	}
+	if !reflect.DeepEqual(binaries, []string{"guide.docx"}) {
+		t.Fatalf("BinaryFiles = %v, want [guide.docx]", binaries)
+	}
+}
+
+func TestAnalyzeGitRange_ReportsKeepDocumentDirectories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	exe := buildDocline(t)
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	for _, sub := range []string{"a", "b"} {
+		path := filepath.Join(dir, "docs", sub, "index.xml")
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte("<book>\n  <para>Nothing repeats in "+sub+".</para>\n</book>\n"), 0o644)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	for _, sub := range []string{"a", "b"} {
+		path := filepath.Join(dir, "docs", sub, "index.xml")
+		os.WriteFile(path, []byte("<book>\n  <para>Nothing repeats in "+sub+" at all.</para>\n</book>\n"), 0o644)
+	}
+
+	if out, code := runIn(t, dir, nil, exe, "analyze", "-git-range", "HEAD", "-format", "json", "-results-dir", "results"); code != 0 {
+		t.Fatalf("analyze -git-range: exit %d\n%s", code, out)
+	}
+	for _, sub := range []string{"a", "b"} {
+		report := filepath.Join(dir, "results", "docs", sub, "index.json")
+		data, err := os.ReadFile(report)
+		if err != nil || !strings.Contains(string(data), "docs/"+sub+"/index.xml") {
+			t.Fatalf("report %s (%v):\n%s", report, err, data)
+		}
+	}
+}
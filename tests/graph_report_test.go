@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// graphTestResult has one group spread over lines 3, 4 and 120 of a.xml and
+// one group shared between a.xml line 5 and b.xml.
+func graphTestResult() *framework.AnalysisResult {
+	g1 := cloneGroup("one two three", 3)
+	g1.Fragments[0].Metadata = map[string]interface{}{"source_line_start": 3}
+	g1.Fragments[1].Metadata = map[string]interface{}{"source_line_start": 4}
+	g1.Fragments[2].Metadata = map[string]interface{}{"source_line_start": 120}
+
+	g2 := cloneGroup(`say "quoted" words`, 2)
+	g2.Fragments[0].Metadata = map[string]interface{}{"source_line_start": 5}
+	g2.Fragments[1].Metadata = map[string]interface{}{"source_file": "b.xml", "section": "Install"}
+
+	return &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{g1, g2},
+		Metadata: map[string]interface{}{"source_file": "a.xml"},
+	}
+}
+
+func TestDOTReport_SectionsAndWeights(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	outPath := filepath.Join(tmpDir, "graph.dot")
+	if err := fw.GenerateReport(graphTestResult(), "dot", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	dot := string(data)
+	for _, want := range []string{
+		`n0 [label="a.xml L1-50", tooltip="a.xml L1-50\nfragments: 3\nclone tokens: 9"]`,
+		`n1 [label="a.xml L101-150"`,
+		`n2 [label="b.xml Install"`,
+		`n0 -- n1 [weight=3, label="3", tooltip="shared clone tokens: 3\ngroups: 1"`,
+		"n0 -- n2 [weight=3,",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("expected DOT to contain %q:\n%s", want, dot)
+		}
+	}
+
+	if err := fw.GenerateReportWithParams(graphTestResult(), "dot", outPath, map[string]interface{}{"collapse": "file"}); err != nil {
+		t.Fatalf("GenerateReportWithParams: %v", err)
+	}
+	data, _ = os.ReadFile(outPath)
+	if !strings.Contains(string(data), `n0 [label="a.xml", tooltip="a.xml\nfragments: 4`) ||
+		!strings.Contains(string(data), "n0 -- n1 [weight=3,") || strings.Contains(string(data), "n2") {
+		t.Fatalf("expected two file nodes and one edge:\n%s", data)
+	}
+}
+
+func TestGraphMLReport_IsValidXML(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	outPath := filepath.Join(tmpDir, "graph.graphml")
+	if err := fw.GenerateReport(graphTestResult(), "graphml", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v\n%s", err, data)
+	}
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("expected 3 nodes and 2 edges, got %d / %d:\n%s", len(doc.Graph.Nodes), len(doc.Graph.Edges), data)
+	}
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"testing"
+
+	alg "github.com/PavelMkr/docline-new/internal/algorithms"
+	"github.com/PavelMkr/docline-new/internal/framework"
+	rep "github.com/PavelMkr/docline-new/internal/report"
+)
+
+// newTestFramework creates a framework with all built-in plugins registered.
+func newTestFramework(t *testing.T, cfg *framework.Config) *framework.Framework {
+	t.Helper()
+
+	fw := framework.NewFramework(cfg)
+	reg := fw.GetRegistry()
+	if err := framework.RegisterBuiltInPlugins(reg); err != nil {
+		t.Fatalf("RegisterBuiltInPlugins: %v", err)
+	}
+	if err := alg.RegisterCloneFinders(reg); err != nil {
+		t.Fatalf("RegisterCloneFinders: %v", err)
+	}
+	if err := rep.RegisterDocumentPlugins(reg); err != nil {
+		t.Fatalf("RegisterDocumentPlugins: %v", err)
+	}
+	if err := rep.RegisterReportGenerators(reg); err != nil {
+		t.Fatalf("RegisterReportGenerators: %v", err)
+	}
+	return fw
+}
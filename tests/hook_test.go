@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildDocline builds the docline command into a temporary directory.
+func buildDocline(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not installed")
+	}
+	exe := filepath.Join(t.TempDir(), "docline")
+	cmd := exec.Command("go", "build", "-o", exe, "github.com/PavelMkr/docline-new/cmd/docline")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	return exe
+}
+
+// runIn runs name with args in dir and returns its combined output and exit
+// code.
+func runIn(t *testing.T, dir string, env []string, name string, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	err := cmd.Run()
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		return out.String(), 0
+	case errors.As(err, &exit):
+		return out.String(), exit.ExitCode()
+	}
+	t.Fatalf("run %s %v: %v", name, args, err)
+	return "", 0
+}
+
+func TestHook_InstallRunUninstall(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	exe := buildDocline(t)
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	hook := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	gitUser := []string{"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com"}
+
+	// A hook not written by docline is kept unless -force is given.
+	os.MkdirAll(filepath.Dir(hook), 0o755)
+	os.WriteFile(hook, []byte("#!/bin/sh\nexit 0\n"), 0o755)
+	if out, code := runIn(t, dir, nil, exe, "hook", "install"); code != 2 || !strings.Contains(out, "-force") {
+		t.Fatalf("install over a foreign hook: exit %d\n%s", code, out)
+	}
+	if out, code := runIn(t, dir, nil, exe, "hook", "uninstall"); code != 2 {
+		t.Fatalf("uninstall of a foreign hook: exit %d\n%s", code, out)
+	}
+	if data, _ := os.ReadFile(hook); string(data) != "#!/bin/sh\nexit 0\n" {
+		t.Fatalf("foreign hook was modified:\n%s", data)
+	}
+	if out, code := runIn(t, dir, nil, exe, "hook", "install", "-force", "--", "-finder", "automatic", "-min-length", "5"); code != 0 {
+		t.Fatalf("install -force: exit %d\n%s", code, out)
+	}
+	script, err := os.ReadFile(hook)
+	if err != nil || !strings.Contains(string(script), "hook run -finder automatic -min-length 5") {
+		t.Fatalf("unexpected hook script (%v):\n%s", err, script)
+	}
+	if out, code := runIn(t, dir, nil, exe, "hook", "install", "--", "-no-such-flag"); code != 2 {
+		t.Fatalf("install with invalid run flags: exit %d\n%s", code, out)
+	}
+
+	para := func(s string) string { return "  <para>" + s + "</para>" }
+	doc := func(paras ...string) []byte {
+		return []byte(strings.Join(append(append([]string{`<?xml version="1.0" encoding="UTF-8"?>`, "<book>"}, paras...), "</book>"), "\n") + "\n")
+	}
+	path := filepath.Join(dir, "guide.xml")
+	os.WriteFile(path, doc(para("Nothing in this sentence repeats anywhere else in the guide.")), 0o644)
+	runGit(t, dir, "add", ".")
+	if out, code := runIn(t, dir, gitUser, "git", "commit", "-q", "-m", "first"); code != 0 {
+		t.Fatalf("commit without duplication should pass the hook: exit %d\n%s", code, out)
+	}
+
+	// A staged duplicate is a new clone group and blocks the commit.
+	dup := para("Always press the save button before you close the editor window.")
+	os.WriteFile(path, doc(para("Nothing in this sentence repeats anywhere else in the guide."), dup, dup), 0o644)
+	runGit(t, dir, "add", ".")
+	out, code := runIn(t, dir, nil, exe, "hook", "run", "-finder", "automatic", "-min-length", "5")
+	if code != 1 || !strings.Contains(out, "FAIL guide.xml") {
+		t.Fatalf("hook run with a new group: exit %d\n%s", code, out)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if !strings.HasPrefix(line, "FAIL ") && !strings.HasPrefix(line, "     ") && !strings.HasPrefix(line, "docline: ") {
+			t.Fatalf("unexpected hook output line %q:\n%s", line, out)
+		}
+	}
+	if out, code := runIn(t, dir, gitUser, "git", "commit", "-q", "-m", "second"); code == 0 || !strings.Contains(out, "commit blocked") {
+		t.Fatalf("commit with a new group should be blocked: exit %d\n%s", code, out)
+	}
+	if out, code := runIn(t, dir, append(gitUser, "DOCLINE_SKIP_HOOK=1"), "git", "commit", "-q", "-m", "second"); code != 0 || !strings.Contains(out, "skipping") {
+		t.Fatalf("DOCLINE_SKIP_HOOK should bypass the hook: exit %d\n%s", code, out)
+	}
+
+	if out, code := runIn(t, dir, nil, exe, "hook", "uninstall"); code != 0 {
+		t.Fatalf("uninstall: exit %d\n%s", code, out)
+	}
+	if _, err := os.Stat(hook); !os.IsNotExist(err) {
+		t.Fatalf("hook should be removed, stat: %v", err)
+	}
+	if out, code := runIn(t, dir, nil, exe, "hook", "uninstall"); code != 0 || !strings.Contains(out, "no pre-commit hook") {
+		t.Fatalf("uninstall without a hook: exit %d\n%s", code, out)
+	}
+}
+
+func TestHook_ChecksStagedMarkdown(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	exe := buildDocline(t)
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+
+	// Without pandoc the Markdown file is checked as plain text instead of
+	// failing the hook.
+	dup := "Always press the save button before you close the editor window."
+	md := "# Notes\n\n" + dup + "\n\nNothing in this sentence repeats anywhere else.\n\n" + dup + "\n"
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte(md), 0o644)
+	runGit(t, dir, "add", ".")
+	out, code := runIn(t, dir, nil, exe, "hook", "run", "-finder", "automatic", "-min-length", "5")
+	if code != 1 || !strings.Contains(out, "FAIL notes.md") {
+		t.Fatalf("hook run on a staged Markdown file: exit %d\n%s", code, out)
+	}
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func TestHTMLReport_EscapesQuotes(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	result := &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{cloneGroup(`say "hi" <script>x</script> it's`, 2)},
+		Metadata: map[string]interface{}{"source_file": "doc.xml"},
+	}
+	outPath := filepath.Join(tmpDir, "report.html")
+	if err := fw.GenerateReport(result, "html", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	page := string(data)
+	if strings.Contains(page, "<script>") || strings.Contains(page, `"hi"`) {
+		t.Fatalf("fragment text not escaped:\n%s", page)
+	}
+	if !strings.Contains(page, "&#34;hi&#34;") {
+		t.Fatalf("expected escaped quotes in report:\n%s", page)
+	}
+}
+
+func TestHTMLReport_CustomTemplateDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	tmplDir := filepath.Join(tmpDir, "tmpl")
+	if err := os.MkdirAll(tmplDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"report.html.tmpl": `<h1>{{.Params.brand}}: {{.Title}}</h1>{{range .Groups}}{{template "group" .}}{{end}}`,
+		"group.tmpl":       `{{define "group"}}<p data-id="{{.ID}}">{{.Power}} {{.Archetype}}</p>{{end}}`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(tmplDir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	group := cloneGroup("shared text block", 3)
+	group.Metadata = map[string]interface{}{framework.GroupIDMetadataKey: "gabc"}
+	result := &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{group},
+		Metadata: map[string]interface{}{"source_file": "doc.xml"},
+	}
+	outPath := filepath.Join(tmpDir, "custom.html")
+	params := map[string]interface{}{"template_dir": tmplDir, "brand": "ACME", "title": "Docs audit"}
+	if err := fw.GenerateReportWithParams(result, "html", outPath, params); err != nil {
+		t.Fatalf("GenerateReportWithParams: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	want := `<h1>ACME: Docs audit</h1><p data-id="gabc">3 shared text block</p>`
+	if string(data) != want {
+		t.Fatalf("got %q, want %q", data, want)
+	}
+
+	// A directory without the entry template is rejected.
+	params["template_dir"] = t.TempDir()
+	if err := fw.GenerateReportWithParams(result, "html", outPath, params); err == nil {
+		t.Fatal("expected error for template directory without report.html.tmpl")
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func TestInteractiveHTMLReport_SelfContainedWithDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	group := framework.CloneGroup{
+		Archetype: "press the red button to stop",
+		Power:     2,
+		Fragments: []framework.TextFragment{
+			{Content: "press the red button to stop", StartPos: 0, EndPos: 6},
+			{Content: "press the green button to stop <now>", StartPos: 20, EndPos: 27},
+		},
+	}
+	result := &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{group},
+		Metadata: map[string]interface{}{"source_file": "manual.xml", "total_tokens": 40},
+	}
+
+	outPath := filepath.Join(tmpDir, "report.html")
+	if err := fw.GenerateReport(result, "html-interactive", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	html := string(data)
+
+	for _, want := range []string{
+		"<del>red</del>",
+		"<ins>green</ins>",
+		"<ins>&lt;now&gt;</ins>",
+		`data-power="2"`,
+		`class="heat"`,
+		"applyFilters",
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected report to contain %q", want)
+		}
+	}
+	for _, external := range []string{"<script src=", "<link ", "http://", "https://"} {
+		if strings.Contains(html, external) {
+			t.Fatalf("report must be self-contained, found %q", external)
+		}
+	}
+}
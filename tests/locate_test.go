@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/locate"
+)
+
+// spanLines returns the zero-based source line of every analysed token, or
+// -1 for tokens without a span.
+func spanLines(source, analysed string, markup bool) []int {
+	lines := locate.NewLineIndex(source)
+	var out []int
+	for _, sp := range locate.TokenSpans(source, analysed, markup) {
+		if sp == (locate.Span{}) {
+			out = append(out, -1)
+			continue
+		}
+		out = append(out, lines.Line(sp.Start))
+	}
+	return out
+}
+
+func TestTokenSpans_RepeatedWordsInDroppedText(t *testing.T) {
+	source := strings.Join([]string{
+		`<section><title>Saving</title>`,
+		`<para>Save the file before you close the editor.</para>`,
+		`<programlisting>save file --force</programlisting>`,
+		`<para>Save the file often.</para>`,
+		`</section>`,
+	}, "\n")
+	// The DocBook parser drops the program listing.
+	analysed := "Saving\nSave the file before you close the editor.\nSave the file often."
+
+	want := []int{0, 1, 1, 1, 1, 1, 1, 1, 1, 3, 3, 3, 3}
+	if got := spanLines(source, analysed, true); !slices.Equal(got, want) {
+		t.Fatalf("token lines = %v, want %v", got, want)
+	}
+}
+
+func TestTokenSpans_RepeatedSentences(t *testing.T) {
+	source := "Close the door. Close the door.\n\nClose the window.\n"
+	analysed := "Close the door. Close the door. Close the window."
+
+	spans := locate.TokenSpans(source, analysed, false)
+	tokens := strings.Fields(analysed)
+	for i, sp := range spans {
+		if got := source[sp.Start:sp.End]; got != tokens[i] {
+			t.Fatalf("token %d %q mapped to %q", i, tokens[i], got)
+		}
+		if i > 0 && sp.Start <= spans[i-1].Start {
+			t.Fatalf("token %d mapped before token %d: %v", i, i-1, spans)
+		}
+	}
+}
+
+func TestTokenSpans_TextMissingFromSource(t *testing.T) {
+	// "&product;" was expanded by the parser; its words occur again later and
+	// must not pull the alignment past the text in between.
+	source := "Use &product; today.\nMany users like the product.\nDocline ships daily."
+	analysed := "Use Docline Pro today. Many users like the product. Docline ships daily."
+
+	want := []int{0, -1, -1, 0, 1, 1, 1, 1, 1, 2, 2, 2}
+	if got := spanLines(source, analysed, false); !slices.Equal(got, want) {
+		t.Fatalf("token lines = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/lsp"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func lspFrame(t *testing.T, buf *bytes.Buffer, msg map[string]interface{}) {
+	t.Helper()
+	msg["jsonrpc"] = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	fmt.Fprintf(buf, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+type lspMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+func readLSPFrames(t *testing.T, out []byte) []lspMessage {
+	t.Helper()
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(out)))
+	var msgs []lspMessage
+	for {
+		header, err := r.ReadMIMEHeader()
+		if err == io.EOF {
+			return msgs
+		}
+		if err != nil {
+			t.Fatalf("read header: %v", err)
+		}
+		n, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, n)
+		if _, err := io.ReadFull(r.R, body); err != nil {
+			t.Fatalf("read body: %v", err)
+		}
+		var m lspMessage
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		msgs = append(msgs, m)
+	}
+}
+
+type lspRange struct {
+	Start struct{ Line, Character int }
+	End   struct{ Line, Character int }
+}
+
+func TestLSP_DiagnosticsAndReferences(t *testing.T) {
+	const uri = "file:///docs/guide.xml"
+	doc := strings.Join([]string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<book>`,
+		`  <para>Always press the save button before you close the editor window.</para>`,
+		`  <para>This paragraph says something completely different from all others.</para>`,
+		`  <para>Always press the save button before you close the editor window.</para>`,
+		`</book>`,
+	}, "\n")
+
+	var in bytes.Buffer
+	lspFrame(t, &in, map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{
+		"initializationOptions": map[string]interface{}{"finder": "automatic", "min_clone_length": 5, "severity": 2},
+	}})
+	lspFrame(t, &in, map[string]interface{}{"method": "initialized", "params": map[string]interface{}{}})
+	lspFrame(t, &in, map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "xml", "version": 1, "text": doc},
+	}})
+	lspFrame(t, &in, map[string]interface{}{"id": 2, "method": "textDocument/references", "params": map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": 2, "character": 20},
+		"context":      map[string]interface{}{"includeDeclaration": false},
+	}})
+	lspFrame(t, &in, map[string]interface{}{"id": 3, "method": "textDocument/hover", "params": map[string]interface{}{}})
+	lspFrame(t, &in, map[string]interface{}{"id": 4, "method": "shutdown"})
+	lspFrame(t, &in, map[string]interface{}{"method": "exit"})
+
+	var out bytes.Buffer
+	d := docline.New(&docline.Config{DefaultTokenizer: "space"})
+	if err := lsp.New(d).Serve(&in, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	var diags []struct {
+		Range    lspRange
+		Severity int
+		Source   string
+		Message  string
+	}
+	var refs []struct {
+		URI   string
+		Range lspRange
+	}
+	responses := map[int]lspMessage{}
+	for _, m := range readLSPFrames(t, out.Bytes()) {
+		switch {
+		case m.Method == "textDocument/publishDiagnostics":
+			var p struct {
+				URI         string
+				Diagnostics json.RawMessage
+			}
+			json.Unmarshal(m.Params, &p)
+			json.Unmarshal(p.Diagnostics, &diags)
+		case m.ID != nil:
+			responses[*m.ID] = m
+		}
+	}
+
+	if len(diags) < 2 {
+		t.Fatalf("expected diagnostics on both paragraphs, got %+v", diags)
+	}
+	lines := map[int]bool{}
+	for _, dg := range diags {
+		lines[dg.Range.Start.Line] = true
+		if dg.Source != "docline" || dg.Severity != 2 || !strings.HasPrefix(dg.Message, "Duplicated 2 times, see line ") {
+			t.Fatalf("unexpected diagnostic: %+v", dg)
+		}
+	}
+	if !lines[2] || !lines[4] || lines[3] {
+		t.Fatalf("expected diagnostics on lines 2 and 4 only, got %v", lines)
+	}
+	first := diags[0].Range
+	if first.Start.Line != 2 || first.Start.Character != len("  <para>") {
+		t.Fatalf("first fragment should start at the paragraph text, got %+v", first)
+	}
+
+	json.Unmarshal(responses[2].Result, &refs)
+	if len(refs) != 1 || refs[0].URI != uri || refs[0].Range.Start.Line != 4 {
+		t.Fatalf("expected the other occurrence as reference, got %+v", refs)
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != -32601 {
+		t.Fatalf("expected method not found for hover, got %+v", responses[3])
+	}
+	if _, ok := responses[4]; !ok {
+		t.Fatal("expected shutdown response")
+	}
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func TestMarkdownReport_EscapingAndLocations(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	group := cloneGroup("use <b>bold</b> | *stars* here", 2)
+	group.Fragments[0].Metadata = map[string]interface{}{"source_line_start": 3, "source_line_end": 4}
+	group.Fragments[1].Metadata = map[string]interface{}{"source_line_start": 9}
+	// '-' and '.' are escaped only where they would start a list item
+	list := cloneGroup("1. Send an e-mail with version 1.2", 2)
+	list.Fragments[1].Content = "- Send an e-mail with version 1.2"
+	result := &framework.AnalysisResult{
+		Groups:     []framework.CloneGroup{group, list},
+		Statistics: framework.AnalysisStatistics{TotalGroups: 2, TotalFragments: 4, MinTokens: 7, MaxTokens: 7, AvgTokens: 7},
+		Metadata:   map[string]interface{}{"source_file": "guide.md"},
+	}
+
+	outPath := filepath.Join(tmpDir, "report.md")
+	if err := fw.GenerateReport(result, "md", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	md := string(data)
+
+	for _, want := range []string{
+		"## Summary",
+		"| Clone groups | 2 |",
+		"<details>",
+		"`guide.md:3-4`",
+		"`guide.md:9`",
+		`&lt;b&gt;bold&lt;/b&gt; \| \*stars\*`,
+		`1\. Send an e-mail with version 1.2`,
+		`\- Send an e-mail with version 1.2`,
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("expected report to contain %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "<b>") {
+		t.Fatalf("raw HTML from fragment text leaked into report:\n%s", md)
+	}
+}
+
+func TestMarkdownReport_MaxGroupsParam(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	result := &framework.AnalysisResult{
+		Groups: []framework.CloneGroup{
+			cloneGroup("first repeated sentence", 2),
+			cloneGroup("second repeated sentence", 2),
+			cloneGroup("third repeated sentence", 2),
+		},
+		Statistics: framework.AnalysisStatistics{TotalGroups: 3},
+	}
+	report := func(params map[string]interface{}) string {
+		t.Helper()
+		outPath := filepath.Join(tmpDir, "report.md")
+		if err := fw.GenerateReportWithParams(result, "md", outPath, params); err != nil {
+			t.Fatalf("GenerateReport: %v", err)
+		}
+		data, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatalf("read report: %v", err)
+		}
+		return string(data)
+	}
+
+	md := report(map[string]interface{}{"max_groups": 2})
+	if !strings.Contains(md, "_1 more groups not shown._") || strings.Contains(md, "third repeated sentence") {
+		t.Fatalf("expected the third group to be truncated:\n%s", md)
+	}
+	if !strings.Contains(md, "| Clone groups | 3 |") {
+		t.Fatalf("summary should count every group:\n%s", md)
+	}
+	if md := report(nil); strings.Contains(md, "not shown") || !strings.Contains(md, "third repeated sentence") {
+		t.Fatalf("expected every group without max_groups:\n%s", md)
+	}
+}
@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func writeTermsDoc(t *testing.T, dir string) string {
+	t.Helper()
+	docPath := filepath.Join(dir, "doc.xml")
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<book>
+	<para>Log in with your account.</para>
+	<para>The login page is slow.</para>
+	<para>Reset the password on the login page.</para>
+	<para>The password must be long.</para>
+</book>`
+	if err := os.WriteFile(docPath, []byte(doc), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+	return docPath
+}
+
+func readNDJSON(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open report: %v", err)
+	}
+	defer file.Close()
+	var records []map[string]interface{}
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		var rec map[string]interface{}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("bad ndjson line %q: %v", sc.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestAnalyzeDocumentStream_NDJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := writeTermsDoc(t, tmpDir)
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+
+	want, err := fw.AnalyzeDocument(docPath, "terminology", framework.CloneFinderConfig{})
+	if err != nil {
+		t.Fatalf("AnalyzeDocument: %v", err)
+	}
+
+	outPath := filepath.Join(tmpDir, "terms.ndjson")
+	got, err := fw.AnalyzeDocumentStream(docPath, "terminology", framework.CloneFinderConfig{}, "ndjson", outPath, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeDocumentStream: %v", err)
+	}
+	if got.Groups != nil {
+		t.Fatalf("streamed result must not hold groups")
+	}
+	if got.Statistics != want.Statistics {
+		t.Fatalf("statistics differ:\n got %+v\nwant %+v", got.Statistics, want.Statistics)
+	}
+
+	records := readNDJSON(t, outPath)
+	if len(records) != len(want.Groups)+2 {
+		t.Fatalf("expected header, %d groups and trailer, got %d records", len(want.Groups), len(records))
+	}
+	if records[0]["type"] != "header" || records[0]["finder"] != "terminology" || records[0]["schema_version"] != float64(1) {
+		t.Fatalf("unexpected header: %v", records[0])
+	}
+	// Streamed terms arrive in text order, not in the order of the result.
+	archetypes := map[interface{}]string{}
+	for _, g := range want.Groups {
+		archetypes[g.Metadata["group_id"]] = g.Archetype
+	}
+	for i, rec := range records[1 : len(records)-1] {
+		md, _ := rec["metadata"].(map[string]interface{})
+		archetype, ok := archetypes[md["group_id"]]
+		if rec["type"] != "group" || !ok || rec["archetype"] != archetype {
+			t.Fatalf("group %d: unexpected record %v", i, rec)
+		}
+		delete(archetypes, md["group_id"])
+	}
+	trailer := records[len(records)-1]
+	stats, _ := trailer["stats"].(map[string]interface{})
+	if trailer["type"] != "trailer" || trailer["groups"] != float64(len(want.Groups)) || stats["total_groups"] != float64(want.Statistics.TotalGroups) {
+		t.Fatalf("unexpected trailer: %v", trailer)
+	}
+
+	// Non-streaming finder and generator fall back to the buffered path.
+	htmlPath := filepath.Join(tmpDir, "auto.html")
+	if _, err := fw.AnalyzeDocumentStream(docPath, "automatic", framework.CloneFinderConfig{MinCloneLength: 3}, "html", htmlPath, nil); err != nil {
+		t.Fatalf("AnalyzeDocumentStream fallback: %v", err)
+	}
+	if _, err := os.Stat(htmlPath); err != nil {
+		t.Fatalf("expected html report: %v", err)
+	}
+}
+
+// brokenStreamFinder emits one group and then fails.
+type brokenStreamFinder struct{}
+
+func (brokenStreamFinder) Name() string        { return "test-broken-stream" }
+func (brokenStreamFinder) Description() string { return "Fails after the first group" }
+func (brokenStreamFinder) FindClones(string, framework.CloneFinderConfig) ([]framework.CloneGroup, error) {
+	return nil, errors.New("search failed")
+}
+func (brokenStreamFinder) FindClonesStream(_ string, _ framework.CloneFinderConfig, emit func(framework.CloneGroup) error) error {
+	if err := emit(cloneGroup("save your work", 2)); err != nil {
+		return err
+	}
+	return errors.New("search failed")
+}
+
+// unclosableReport is a streaming generator whose Close always fails.
+type unclosableReport struct{ written int }
+
+func (u *unclosableReport) Name() string   { return "test-unclosable-report" }
+func (u *unclosableReport) Format() string { return "test-unclosable" }
+func (u *unclosableReport) Generate([]framework.CloneGroup, framework.ReportConfig, string) error {
+	return nil
+}
+func (u *unclosableReport) OpenStream(framework.ReportConfig, string) (framework.GroupStreamWriter, error) {
+	return u, nil
+}
+func (u *unclosableReport) WriteGroup(framework.CloneGroup) error { u.written++; return nil }
+func (u *unclosableReport) Close(framework.StreamSummary) error   { return errors.New("disk full") }
+
+func TestAnalyzeDocumentStream_ReportsCloseErrorOnFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := writeTermsDoc(t, tmpDir)
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+	report := &unclosableReport{}
+	if err := fw.GetRegistry().RegisterCloneFinder(brokenStreamFinder{}); err != nil {
+		t.Fatalf("RegisterCloneFinder: %v", err)
+	}
+	if err := fw.GetRegistry().RegisterReportGenerator(report); err != nil {
+		t.Fatalf("RegisterReportGenerator: %v", err)
+	}
+
+	_, err := fw.AnalyzeDocumentStream(docPath, "test-broken-stream", framework.CloneFinderConfig{}, "test-unclosable", filepath.Join(tmpDir, "out"), nil)
+	if err == nil || !strings.Contains(err.Error(), "search failed") || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected both the finder and the close error, got %v", err)
+	}
+	if report.written != 1 {
+		t.Fatalf("expected the group found before the failure to be written, got %d", report.written)
+	}
+}
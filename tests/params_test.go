@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func TestParams_Validate(t *testing.T) {
+	specs := []framework.ParamSpec{
+		{Name: "strict_filter", Type: framework.ParamBool, Default: true},
+		{Name: "archetype_length", Type: framework.ParamInt, Default: 5, Min: framework.Bound(1)},
+		{Name: "ratio", Type: framework.ParamFloat, Default: 0.5, Min: framework.Bound(0), Max: framework.Bound(1)},
+		{Name: "language", Type: framework.ParamString, Default: "en"},
+	}
+
+	valid := map[string]interface{}{
+		"strict_filter":    false,
+		"archetype_length": float64(7), // as decoded from JSON
+		"ratio":            1,
+		"language":         "de",
+	}
+	if err := framework.ValidateParams(specs, valid); err != nil {
+		t.Fatalf("ValidateParams(valid): %v", err)
+	}
+	if err := framework.ValidateParams(specs, nil); err != nil {
+		t.Fatalf("ValidateParams(nil): %v", err)
+	}
+
+	for _, tc := range []struct {
+		params map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"strict_filter": "true"}, `want bool, got string "true"`},
+		{map[string]interface{}{"archetype_length": 2.5}, "want int"},
+		{map[string]interface{}{"archetype_length": 0}, "below the minimum 1"},
+		{map[string]interface{}{"ratio": 1.5}, "above the maximum 1"},
+		{map[string]interface{}{"language": 3}, "want string"},
+		{map[string]interface{}{"strict_filtr": true}, `unknown parameter "strict_filtr" (did you mean "strict_filter"?)`},
+		{map[string]interface{}{"colour": true}, `unknown parameter "colour"`},
+	} {
+		err := framework.ValidateParams(specs, tc.params)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ValidateParams(%v) = %v, want error containing %q", tc.params, err, tc.want)
+		}
+	}
+
+	err := framework.ValidateParams(specs, map[string]interface{}{"ratio": -1, "language": true})
+	if err == nil || !strings.Contains(err.Error(), `"language"`) || !strings.Contains(err.Error(), `"ratio"`) {
+		t.Fatalf("expected every problem to be reported, got %v", err)
+	}
+}
+
+// thresholdFinder declares a single float parameter.
+type thresholdFinder struct{}
+
+func (thresholdFinder) Name() string        { return "test-threshold" }
+func (thresholdFinder) Description() string { return "Finds nothing above a threshold" }
+func (thresholdFinder) Params() []docline.ParamSpec {
+	return []docline.ParamSpec{
+		{Name: "threshold", Type: docline.ParamFloat, Default: 0.5, Min: docline.Bound(0), Max: docline.Bound(1), Description: "minimum score"},
+	}
+}
+func (thresholdFinder) FindClones(string, docline.CloneFinderConfig) ([]docline.CloneGroup, error) {
+	return nil, nil
+}
+
+func TestParams_FindersAreValidatedBeforeRunning(t *testing.T) {
+	d := docline.New(&docline.Config{ResultsDirectory: t.TempDir(), DefaultTokenizer: "space"})
+	if err := d.RegisterCloneFinder(thresholdFinder{}); err != nil {
+		t.Fatalf("RegisterCloneFinder: %v", err)
+	}
+
+	text := "Save your work often. Save your work often."
+	analyze := func(finder string, params map[string]interface{}) error {
+		_, err := d.AnalyzeReaderWithConfig(strings.NewReader(text), "", finder, docline.CloneFinderConfig{MinCloneLength: 2, CustomParams: params})
+		return err
+	}
+
+	if err := analyze("automatic", map[string]interface{}{"strict_filter": false}); err != nil {
+		t.Fatalf("valid built-in params rejected: %v", err)
+	}
+	if err := analyze("automatic", map[string]interface{}{"strict_filter": "false"}); err == nil || !strings.Contains(err.Error(), "finder automatic") {
+		t.Fatalf("expected a typed error naming the finder, got %v", err)
+	}
+	if err := analyze("test-threshold", map[string]interface{}{"threshold": 2}); err == nil {
+		t.Fatal("expected the custom finder's range to be enforced")
+	}
+	if err := d.ValidateFinderParams("test-threshold", map[string]interface{}{"threshold": 0.7}); err != nil {
+		t.Fatalf("ValidateFinderParams: %v", err)
+	}
+	if err := d.ValidateFinderParams("missing", nil); err == nil {
+		t.Fatal("expected an error for an unknown finder")
+	}
+
+	infos := map[string]docline.FinderInfo{}
+	for _, f := range d.CloneFinders() {
+		infos[f.Name] = f
+	}
+	if f := infos["test-threshold"]; !f.Declared || len(f.Params) != 1 || f.Params[0].Name != "threshold" || *f.Params[0].Max != 1 {
+		t.Fatalf("unexpected custom finder info: %+v", f)
+	}
+	for _, name := range []string{"automatic", "interactive", "heuristic", "ngram", "terminology"} {
+		if !infos[name].Declared {
+			t.Errorf("built-in finder %s should declare its parameters", name)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+// ptr returns a pointer to v, for policy thresholds.
+func ptr[T any](v T) *T { return &v }
+
+func TestPolicy_Evaluate(t *testing.T) {
+	result := &framework.AnalysisResult{
+		Groups: []framework.CloneGroup{cloneGroup("already there", 2), cloneGroup("introduced now", 3)},
+		Statistics: framework.AnalysisStatistics{
+			TotalGroups:      2,
+			MaxGroupPower:    3,
+			TotalTokens:      100,
+			DuplicatedTokens: 15,
+		},
+	}
+	previous := &framework.AnalysisResult{Groups: []framework.CloneGroup{cloneGroup("already there", 2)}}
+
+	policy := &framework.Policy{MaxGroups: ptr(5), MaxDuplicatedTokenRatio: ptr(0.1), MaxGroupPower: ptr(3), NoNewGroups: true}
+	verdict := policy.Evaluate(result, previous)
+	if verdict.Passed {
+		t.Fatal("expected policy to fail")
+	}
+
+	failed := map[string]bool{}
+	for _, c := range verdict.Violations() {
+		failed[c.Rule] = true
+	}
+	if len(failed) != 2 || !failed[framework.RuleMaxDuplicatedTokenRatio] || !failed[framework.RuleNoNewGroups] {
+		t.Fatalf("unexpected violations: %+v", verdict.Violations())
+	}
+	if len(verdict.Checks) != 4 {
+		t.Fatalf("expected 4 checks, got %d", len(verdict.Checks))
+	}
+}
+
+func TestPolicy_LoadRejectsOutOfRangeRatio(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"max_duplicated_token_ratio": 1.5}`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	if _, err := framework.LoadPolicy(path); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+func TestPolicy_ZeroThresholdIsEnforced(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"max_groups": 0}`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	policy, err := framework.LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+
+	result := &framework.AnalysisResult{
+		Groups:     []framework.CloneGroup{cloneGroup("x y z", 2)},
+		Statistics: framework.AnalysisStatistics{TotalGroups: 1, MaxGroupPower: 2},
+	}
+	verdict := policy.Evaluate(result, nil)
+	if verdict.Passed || len(verdict.Checks) != 1 || verdict.Checks[0].Rule != framework.RuleMaxGroups {
+		t.Fatalf("max_groups 0 should reject any group, got %+v", verdict)
+	}
+	if verdict := (&framework.Policy{}).Evaluate(result, nil); !verdict.Passed || len(verdict.Checks) != 0 {
+		t.Fatalf("absent thresholds should be disabled, got %+v", verdict)
+	}
+}
+
+func TestPolicy_LoadRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"max_group": 3}`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	if _, err := framework.LoadPolicy(path); err == nil || !strings.Contains(err.Error(), "max_group") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestJUnitReport_PolicyVerdict(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	result := &framework.AnalysisResult{
+		Groups:     []framework.CloneGroup{cloneGroup("x y z", 4)},
+		Statistics: framework.AnalysisStatistics{TotalGroups: 1, MaxGroupPower: 4},
+		Metadata:   map[string]interface{}{"source_file": "doc.xml"},
+	}
+	policy := &framework.Policy{MaxGroups: ptr(10), MaxGroupPower: ptr(3)}
+	result.Metadata["policy_verdict"] = policy.Evaluate(result, nil)
+
+	outPath := filepath.Join(tmpDir, "junit.xml")
+	if err := fw.GenerateReport(result, "junit", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	xml := string(data)
+	if !strings.Contains(xml, `tests="2" failures="1"`) {
+		t.Fatalf("unexpected junit counters: %s", xml)
+	}
+	if !strings.Contains(xml, `name="max_group_power"`) || !strings.Contains(xml, "<failure") {
+		t.Fatalf("expected failed max_group_power test case: %s", xml)
+	}
+}
+
+func TestFramework_Statistics_DuplicatedTokens(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := filepath.Join(tmpDir, "doc.xml")
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<book>
+	<para>alpha beta gamma delta</para>
+	<para>unique words only here</para>
+	<para>alpha beta gamma delta</para>
+</book>`
+	if err := os.WriteFile(docPath, []byte(doc), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+	result, err := fw.AnalyzeDocument(docPath, "automatic", framework.CloneFinderConfig{
+		MinCloneLength: 4,
+		CustomParams:   map[string]interface{}{"convert_to_drl": false, "strict_filter": false},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeDocument: %v", err)
+	}
+
+	stats := result.Statistics
+	if stats.TotalTokens != 12 || stats.DuplicatedTokens != 8 {
+		t.Fatalf("expected 8 of 12 tokens duplicated, got %d of %d", stats.DuplicatedTokens, stats.TotalTokens)
+	}
+	if stats.MaxGroupPower != 2 {
+		t.Fatalf("expected max group power 2, got %d", stats.MaxGroupPower)
+	}
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func TestPublicAPI_AnalyzeCompareAndPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := writeTermsDoc(t, tmpDir)
+	d := docline.New(&docline.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+
+	result, err := d.AnalyzeDocumentWithConfig(docPath, "terminology", docline.CloneFinderConfig{})
+	if err != nil {
+		t.Fatalf("AnalyzeDocumentWithConfig: %v", err)
+	}
+	if len(result.Groups) == 0 || result.Statistics.TotalGroups != len(result.Groups) {
+		t.Fatalf("unexpected result: %d groups, stats %+v", len(result.Groups), result.Statistics)
+	}
+	if id, _ := result.Groups[0].Metadata[docline.GroupIDMetadataKey].(string); id == "" {
+		t.Fatalf("expected stable group id, got %v", result.Groups[0].Metadata)
+	}
+
+	cmp := docline.CompareResults(nil, result)
+	if len(cmp.New) != len(result.Groups) || !cmp.HasChanges() {
+		t.Fatalf("expected every group to be new, got %d of %d", len(cmp.New), len(result.Groups))
+	}
+	if diff := cmp.AsAnalysisResult(); diff.Groups[0].Metadata[docline.DiffStatusMetadataKey] != docline.GroupNew {
+		t.Fatalf("unexpected diff metadata: %v", diff.Groups[0].Metadata)
+	}
+
+	// The public verdict stored in the result reaches the junit report.
+	policy := &docline.Policy{MaxGroups: ptr(1)}
+	verdict := docline.EvaluatePolicy(policy, result, nil)
+	if verdict.Passed || len(verdict.Violations()) != 1 || verdict.Violations()[0].Rule != docline.RuleMaxGroups {
+		t.Fatalf("unexpected verdict: %+v", verdict)
+	}
+	if _, ok := result.Metadata["policy_verdict"].(docline.PolicyVerdict); !ok {
+		t.Fatalf("expected public verdict in metadata, got %T", result.Metadata["policy_verdict"])
+	}
+	outPath := filepath.Join(tmpDir, "junit.xml")
+	if err := d.GenerateReport(result, "junit", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	if !strings.Contains(string(data), `name="max_groups"`) || !strings.Contains(string(data), "<failure") {
+		t.Fatalf("expected failed max_groups test case: %s", data)
+	}
+
+	// JSON results round-trip through the public types.
+	jsonPath := filepath.Join(tmpDir, "result.json")
+	if err := d.GenerateReport(result, "json", jsonPath); err != nil {
+		t.Fatalf("GenerateReport json: %v", err)
+	}
+	loaded, err := docline.LoadResult(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadResult: %v", err)
+	}
+	if docline.CompareResults(result, loaded).HasChanges() || loaded.Statistics != result.Statistics {
+		t.Fatalf("loaded result differs from the original")
+	}
+}
+
+// sentenceFinder is a public-API finder that groups repeated sentences.
+type sentenceFinder struct{}
+
+func (sentenceFinder) Name() string        { return "test-sentences" }
+func (sentenceFinder) Description() string { return "repeated sentences" }
+
+func (sentenceFinder) FindClones(text string, cfg docline.CloneFinderConfig) ([]docline.CloneGroup, error) {
+	byText := map[string][]docline.TextFragment{}
+	var order []string
+	pos := 0
+	for _, s := range strings.Split(text, ".") {
+		s = strings.TrimSpace(s)
+		n := len(strings.Fields(s))
+		if n >= cfg.MinCloneLength && n > 0 {
+			if byText[s] == nil {
+				order = append(order, s)
+			}
+			byText[s] = append(byText[s], docline.TextFragment{Content: s, StartPos: pos, EndPos: pos + n})
+		}
+		pos += n
+	}
+	var groups []docline.CloneGroup
+	for _, s := range order {
+		if frags := byText[s]; len(frags) >= 2 {
+			groups = append(groups, docline.CloneGroup{Fragments: frags, Power: len(frags), Archetype: s})
+		}
+	}
+	return groups, nil
+}
+
+// countReport writes the number of groups and the stats it received.
+type countReport struct{ format string }
+
+func (c countReport) Name() string   { return c.format + "-report" }
+func (c countReport) Format() string { return c.format }
+
+func (c countReport) Generate(groups []docline.CloneGroup, cfg docline.ReportConfig, outputPath string) error {
+	stats, ok := cfg.Settings["stats"].(docline.AnalysisStatistics)
+	if !ok {
+		return fmt.Errorf("stats setting has type %T", cfg.Settings["stats"])
+	}
+	return os.WriteFile(outputPath, []byte(fmt.Sprintf("%d groups, %d fragments", len(groups), stats.TotalFragments)), 0o644)
+}
+
+// upperParser reads ".shout" files and upper-cases them.
+type upperParser struct{}
+
+func (upperParser) Name() string               { return "shout-parser" }
+func (upperParser) SupportedFormats() []string { return []string{".shout"} }
+
+func (upperParser) Parse(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return []string{strings.ToUpper(string(data))}, nil
+}
+
+func TestPublicAPI_RegisterPlugins(t *testing.T) {
+	tmpDir := t.TempDir()
+	d := docline.New(&docline.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+
+	if err := d.RegisterCloneFinder(sentenceFinder{}); err != nil {
+		t.Fatalf("RegisterCloneFinder: %v", err)
+	}
+	if err := d.RegisterReportGenerator(countReport{format: "count"}); err != nil {
+		t.Fatalf("RegisterReportGenerator: %v", err)
+	}
+	if err := d.RegisterParser(upperParser{}); err != nil {
+		t.Fatalf("RegisterParser: %v", err)
+	}
+
+	// Names, formats and extensions already taken are rejected.
+	if err := d.RegisterCloneFinder(sentenceFinder{}); err == nil {
+		t.Fatal("expected duplicate finder to be rejected")
+	}
+	if err := d.RegisterReportGenerator(countReport{format: "html"}); err == nil {
+		t.Fatal("expected built-in html format to be protected")
+	}
+
+	docPath := filepath.Join(tmpDir, "doc.shout")
+	if err := os.WriteFile(docPath, []byte("Save your work often. Then close the editor. Save your work often."), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+	result, err := d.AnalyzeDocumentWithConfig(docPath, "test-sentences", docline.CloneFinderConfig{MinCloneLength: 2})
+	if err != nil {
+		t.Fatalf("AnalyzeDocumentWithConfig: %v", err)
+	}
+	if len(result.Groups) != 1 || result.Groups[0].Archetype != "SAVE YOUR WORK OFTEN" || result.Groups[0].Power != 2 {
+		t.Fatalf("unexpected groups: %+v", result.Groups)
+	}
+
+	outPath := filepath.Join(tmpDir, "report.count")
+	if err := d.GenerateReport(result, "count", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	if string(data) != "1 groups, 2 fragments" {
+		t.Fatalf("unexpected report: %q", data)
+	}
+}
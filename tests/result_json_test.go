@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func TestLoadResult_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := filepath.Join(tmpDir, "doc.xml")
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<book>
+	<para>read the safety notice first</para>
+	<para>some text</para>
+	<para>read the safety notice first</para>
+</book>`
+	if err := os.WriteFile(docPath, []byte(doc), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+	baselinePath := filepath.Join(tmpDir, "baseline.json")
+	if err := os.WriteFile(baselinePath, []byte(`{"version":1,"entries":[{"pattern":"nothing matches","reason":"stale"}]}`), 0o644); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space", BaselineFile: baselinePath})
+	result, err := fw.AnalyzeDocument(docPath, "automatic", framework.CloneFinderConfig{
+		MinCloneLength: 5,
+		CustomParams:   map[string]interface{}{"convert_to_drl": false, "strict_filter": false},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeDocument: %v", err)
+	}
+	if len(result.Groups) == 0 {
+		t.Fatal("expected clone groups")
+	}
+
+	jsonPath := filepath.Join(tmpDir, "result.json")
+	if err := fw.GenerateReport(result, "json", jsonPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	loaded, err := framework.LoadResult(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadResult: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded.Statistics, result.Statistics) {
+		t.Fatalf("statistics lost:\n got %+v\nwant %+v", loaded.Statistics, result.Statistics)
+	}
+	if loaded.Config.MinCloneLength != 5 || loaded.Config.CustomParams["strict_filter"] != false {
+		t.Fatalf("config lost: %+v", loaded.Config)
+	}
+	if loaded.Metadata["source_file"] != docPath || loaded.Metadata["finder"] != "automatic" || loaded.Metadata["total_tokens"] != result.Metadata["total_tokens"] {
+		t.Fatalf("metadata lost: %+v", loaded.Metadata)
+	}
+	if stale, ok := loaded.Metadata["stale_baseline_entries"].([]framework.StaleBaselineEntry); !ok || len(stale) != 1 {
+		t.Fatalf("stale baseline entries lost: %#v", loaded.Metadata["stale_baseline_entries"])
+	}
+	got, want := loaded.Groups[0], result.Groups[0]
+	if got.Archetype != want.Archetype || got.Power != want.Power || len(got.Fragments) != len(want.Fragments) {
+		t.Fatalf("group lost:\n got %+v\nwant %+v", got, want)
+	}
+	if got.Fragments[1].Metadata["source_line_start"] != want.Fragments[1].Metadata["source_line_start"] {
+		t.Fatalf("fragment line lost: %+v vs %+v", got.Fragments[1].Metadata, want.Fragments[1].Metadata)
+	}
+
+	if cmp := framework.CompareResults(result, loaded); cmp.HasChanges() {
+		t.Fatalf("expected loaded result to match the original: %+v", cmp)
+	}
+	if err := fw.GenerateReport(loaded, "sarif", filepath.Join(tmpDir, "later.sarif")); err != nil {
+		t.Fatalf("render loaded result: %v", err)
+	}
+}
+
+func TestLoadResult_RejectsUnknownSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, body := range map[string]string{
+		"legacy.json": `{"title":"x","groups":[]}`,
+		"future.json": `{"schema_version":99,"groups":[]}`,
+	} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := framework.LoadResult(path); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func TestSARIFReport_ResultsPerFragment(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	group := cloneGroup("shared paragraph text", 3)
+	for i := range group.Fragments {
+		group.Fragments[i].Metadata = map[string]interface{}{
+			"source_line_start": 10 * (i + 1),
+			"source_line_end":   10*(i+1) + 1,
+		}
+	}
+	result := &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{group},
+		Metadata: map[string]interface{}{"source_file": "docs/guide.xml", "finder": "automatic"},
+	}
+
+	outPath := filepath.Join(tmpDir, "report.sarif")
+	if err := fw.GenerateReport(result, "sarif", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				RelatedLocations    []json.RawMessage `json:"relatedLocations"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("unmarshal sarif: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected sarif envelope: %s", data)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "docline/automatic" {
+		t.Fatalf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected one result per fragment, got %d", len(run.Results))
+	}
+	fingerprints := map[string]bool{}
+	for i, r := range run.Results {
+		if r.RuleID != "docline/automatic" {
+			t.Fatalf("result %d: unexpected rule %q", i, r.RuleID)
+		}
+		if len(r.RelatedLocations) != 2 {
+			t.Fatalf("result %d: expected 2 related locations, got %d", i, len(r.RelatedLocations))
+		}
+		loc := r.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URI != "docs/guide.xml" || loc.Region.StartLine != 10*(i+1) {
+			t.Fatalf("result %d: unexpected location %+v", i, loc)
+		}
+		fp := r.PartialFingerprints["doclineFragment/v1"]
+		if fp == "" || fingerprints[fp] {
+			t.Fatalf("result %d: fingerprint %q missing or shared with another result", i, fp)
+		}
+		fingerprints[fp] = true
+	}
+}
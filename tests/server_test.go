@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelMkr/docline-new/internal/server"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func uploadDocument(t *testing.T, url, name, content string, fields map[string]string) (*http.Response, server.JobResponse) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	io.WriteString(fw, content)
+	mw.Close()
+
+	resp, err := http.Post(url+"/api/jobs", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("POST /api/jobs: %v", err)
+	}
+	defer resp.Body.Close()
+	var job server.JobResponse
+	json.NewDecoder(resp.Body).Decode(&job)
+	return resp, job
+}
+
+func getJob(t *testing.T, url, id string) server.JobResponse {
+	t.Helper()
+	resp, err := http.Get(url + "/api/jobs/" + id)
+	if err != nil {
+		t.Fatalf("GET job: %v", err)
+	}
+	defer resp.Body.Close()
+	var job server.JobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	return job
+}
+
+func waitForJob(t *testing.T, url, id string, done func(status string) bool) server.JobResponse {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job := getJob(t, url, id)
+		if done(job.Status) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s stuck in status %q: %s", id, job.Status, job.Message)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_UploadPollAndDownload(t *testing.T) {
+	d := docline.New(&docline.Config{DefaultTokenizer: "space"})
+	srv := server.New(d, server.Config{Workers: 1, TempDir: t.TempDir()})
+	defer srv.Close()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	doc := `<book><para>Log in with your account.</para><para>The login page is slow.</para><para>Reset the password on the login page.</para><para>The password must be long.</para></book>`
+	resp, job := uploadDocument(t, ts.URL, "guide.xml", doc, map[string]string{"finder": "terminology"})
+	if resp.StatusCode != http.StatusAccepted || job.ID == "" {
+		t.Fatalf("upload: status %d, job %+v", resp.StatusCode, job)
+	}
+	if loc := resp.Header.Get("Location"); loc != "/api/jobs/"+job.ID {
+		t.Fatalf("unexpected Location %q", loc)
+	}
+
+	job = waitForJob(t, ts.URL, job.ID, func(s string) bool { return s == server.StatusDone || s == server.StatusFailed })
+	if job.Status != server.StatusDone || job.Stats == nil || job.Stats.TotalGroups == 0 {
+		t.Fatalf("unexpected finished job: %+v", job)
+	}
+
+	// The JSON report is a loadable result named after the upload.
+	resp, err := http.Get(ts.URL + job.ResultsFile)
+	if err != nil {
+		t.Fatalf("GET results_file: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Disposition"), "guide.json") {
+		t.Fatalf("json report: status %d, headers %v", resp.StatusCode, resp.Header)
+	}
+	var doc1 struct {
+		SchemaVersion int    `json:"schema_version"`
+		SourceFile    string `json:"source_file"`
+		Groups        []any  `json:"groups"`
+	}
+	if err := json.Unmarshal(data, &doc1); err != nil || doc1.SchemaVersion != docline.ResultSchemaVersion || doc1.SourceFile != "guide.xml" || len(doc1.Groups) != job.Stats.TotalGroups {
+		t.Fatalf("unexpected json report (%v): %s", err, data)
+	}
+
+	// Query values are report parameters.
+	resp, err = http.Get(ts.URL + "/api/jobs/" + job.ID + "/report/csv?delimiter=%3B")
+	if err != nil {
+		t.Fatalf("GET csv: %v", err)
+	}
+	data, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(data), "group_id;group;power") {
+		t.Fatalf("csv report: status %d: %s", resp.StatusCode, data)
+	}
+
+	for path, want := range map[string]int{
+		"/api/jobs/" + job.ID + "/report/nope":                   http.StatusNotFound,
+		"/api/jobs/unknown":                                      http.StatusNotFound,
+		"/api/jobs/" + job.ID + "/report/html?template_dir=/etc": http.StatusBadRequest,
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s: status %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+
+	if resp, _ := uploadDocument(t, ts.URL, "bad.txt", "x", map[string]string{"min_length": "-1"}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("negative min_length: status %d", resp.StatusCode)
+	}
+}
+
+// blockingFinder waits for release before returning no groups.
+type blockingFinder struct{ release chan struct{} }
+
+func (blockingFinder) Name() string        { return "blocking" }
+func (blockingFinder) Description() string { return "waits for the test" }
+
+func (b blockingFinder) FindClones(string, docline.CloneFinderConfig) ([]docline.CloneGroup, error) {
+	<-b.release
+	return nil, nil
+}
+
+func TestServer_BoundedQueue(t *testing.T) {
+	d := docline.New(&docline.Config{DefaultTokenizer: "space"})
+	finder := blockingFinder{release: make(chan struct{})}
+	if err := d.RegisterCloneFinder(finder); err != nil {
+		t.Fatalf("RegisterCloneFinder: %v", err)
+	}
+	srv := server.New(d, server.Config{Workers: 1, QueueSize: 1, DefaultFinder: "blocking"})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	_, running := uploadDocument(t, ts.URL, "a", "a", nil)
+	waitForJob(t, ts.URL, running.ID, func(s string) bool { return s == server.StatusRunning })
+
+	if resp, _ := uploadDocument(t, ts.URL, "b", "b", nil); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("second upload: status %d", resp.StatusCode)
+	}
+	resp, _ := uploadDocument(t, ts.URL, "c", "c", nil)
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected 503 with Retry-After when the queue is full, got %d", resp.StatusCode)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/jobs/" + running.ID + "/report/json")
+	if err != nil {
+		t.Fatalf("GET report: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("report of running job: status %d", resp.StatusCode)
+	}
+
+	close(finder.release)
+	srv.Close()
+	if job := getJob(t, ts.URL, running.ID); job.Status != server.StatusDone {
+		t.Fatalf("expected finished job after Close, got %q", job.Status)
+	}
+}
+
+func TestServer_FinderParams(t *testing.T) {
+	srv := server.New(docline.New(&docline.Config{DefaultTokenizer: "space"}), server.Config{Workers: 1, TempDir: t.TempDir()})
+	defer srv.Close()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/finders")
+	if err != nil {
+		t.Fatalf("GET /api/finders: %v", err)
+	}
+	var finders []docline.FinderInfo
+	json.NewDecoder(resp.Body).Decode(&finders)
+	resp.Body.Close()
+	if len(finders) == 0 || finders[0].Name != "automatic" || len(finders[0].Params) == 0 {
+		t.Fatalf("unexpected finders: %+v", finders)
+	}
+
+	// Invalid parameters are refused at upload instead of failing the job.
+	resp, _ = uploadDocument(t, ts.URL, "guide.txt", "Save often. Save often.", map[string]string{"finder": "automatic", "param": "strict_filtr=false"})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown parameter, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+	"github.com/PavelMkr/docline-new/pkg/docline"
+)
+
+func TestSimilarity_BuiltInCalculators(t *testing.T) {
+	reg := framework.NewPluginRegistry()
+	if err := framework.RegisterBuiltInPlugins(reg); err != nil {
+		t.Fatalf("RegisterBuiltInPlugins: %v", err)
+	}
+	score := func(name, a, b string) float64 {
+		t.Helper()
+		calc, err := reg.GetSimilarityCalculator(name)
+		if err != nil {
+			t.Fatalf("GetSimilarityCalculator(%s): %v", name, err)
+		}
+		return calc.CalculateSimilarity(a, b)
+	}
+
+	a, b := "save the file now", "now save the file"
+	for _, tc := range []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"jaccard", "a b c", "b c d", 2.0 / 4},
+		{"dice", "a b c", "b c d", 2.0 * 2 / 6},
+		{"levenshtein", a, b, 1 - 2.0/4},
+		{"lcs", a, b, 2.0 * 3 / 8},
+		{"cosine", "a b", "c d", 0},
+	} {
+		if got := score(tc.name, tc.a, tc.b); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s(%q, %q) = %v, want %v", tc.name, tc.a, tc.b, got, tc.want)
+		}
+	}
+	for _, name := range []string{"jaccard", "dice", "levenshtein", "lcs", "cosine"} {
+		if got := score(name, a, a); math.Abs(got-1) > 1e-9 {
+			t.Errorf("%s of identical texts = %v, want 1", name, got)
+		}
+		if got := score(name, "", ""); got != 0 {
+			t.Errorf("%s of empty texts = %v, want 0", name, got)
+		}
+	}
+
+	// Fitted to a corpus where "the" is everywhere, sharing only "the" scores
+	// lower than sharing a rare token.
+	calc, _ := reg.GetSimilarityCalculator("cosine")
+	fitted := calc.(framework.CorpusSimilarityCalculator).ForCorpus("The cat sat.\nThe dog ran.\nThe bird flew.\nThe fish swam.")
+	common := fitted.CalculateSimilarity("the cat", "the dog")
+	rare := fitted.CalculateSimilarity("the cat", "a cat")
+	if common >= rare {
+		t.Fatalf("expected a shared rare token to weigh more: common %v, rare %v", common, rare)
+	}
+}
+
+// countingCalculator scores every pair as identical and counts its calls.
+type countingCalculator struct{ calls int }
+
+func (c *countingCalculator) Name() string { return "test-counting" }
+func (c *countingCalculator) CalculateSimilarity(string, string) float64 {
+	c.calls++
+	return 1
+}
+
+func TestSimilarity_FindersUseConfiguredCalculator(t *testing.T) {
+	text := strings.Repeat("Always press the save button before you close the editor window. ", 3) +
+		strings.Repeat("Check the log file when the import of the data fails. ", 3)
+	params := map[string]interface{}{"strict_filter": false}
+	analyze := func(cfg docline.Config, fc docline.CloneFinderConfig) *docline.AnalysisResult {
+		t.Helper()
+		cfg.ResultsDirectory, cfg.DefaultTokenizer = t.TempDir(), "space"
+		fc.MinCloneLength, fc.CustomParams = 4, params
+		result, err := docline.New(&cfg).AnalyzeReaderWithConfig(strings.NewReader(text), "", "automatic", fc)
+		if err != nil {
+			t.Fatalf("analyze: %v", err)
+		}
+		return result
+	}
+
+	base := analyze(docline.Config{}, docline.CloneFinderConfig{})
+	if base.Config.SimilarityCalc != "jaccard" || len(base.Groups) < 2 {
+		t.Fatalf("expected several groups with the default jaccard calculator, got %d (%q)", len(base.Groups), base.Config.SimilarityCalc)
+	}
+
+	// A calculator scoring everything as identical merges every group.
+	counting := &countingCalculator{}
+	merged := analyze(docline.Config{}, docline.CloneFinderConfig{Similarity: counting})
+	if counting.calls == 0 || len(merged.Groups) != 1 || merged.Config.SimilarityCalc != "test-counting" {
+		t.Fatalf("expected the calculator to merge all groups: %d groups after %d calls", len(merged.Groups), counting.calls)
+	}
+
+	// A threshold of 0.01 merges windows sharing a single token.
+	loose := analyze(docline.Config{DefaultSimilarityCalc: "dice"}, docline.CloneFinderConfig{SimilarityThreshold: 0.01})
+	if loose.Config.SimilarityCalc != "dice" || len(loose.Groups) >= len(base.Groups) {
+		t.Fatalf("expected a low threshold to merge groups: %d of %d (%q)", len(loose.Groups), len(base.Groups), loose.Config.SimilarityCalc)
+	}
+	if got := analyze(docline.Config{DefaultSimilarityCalc: "dice"}, docline.CloneFinderConfig{SimilarityCalc: "lcs"}); got.Config.SimilarityCalc != "lcs" {
+		t.Fatalf("per-run calculator should win over the default, got %q", got.Config.SimilarityCalc)
+	}
+
+	d := docline.New(&docline.Config{DefaultTokenizer: "space"})
+	if _, err := d.AnalyzeReaderWithConfig(strings.NewReader(text), "", "automatic", docline.CloneFinderConfig{SimilarityCalc: "missing"}); err == nil {
+		t.Fatal("expected an unknown similarity calculator to fail the analysis")
+	}
+	if err := d.RegisterSimilarityCalculator(&countingCalculator{}); err != nil || d.RegisterSimilarityCalculator(&countingCalculator{}) == nil {
+		t.Fatalf("expected the second registration to be rejected (first: %v)", err)
+	}
+	if names := strings.Join(d.SimilarityCalculators(), ","); names != "cosine,dice,jaccard,lcs,levenshtein,test-counting" {
+		t.Fatalf("SimilarityCalculators() = %s", names)
+	}
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func svgTestResult() *framework.AnalysisResult {
+	g1 := cloneGroup("alpha beta gamma", 2)
+	g2 := cloneGroup("one <two> & three", 4)
+	g2.Fragments[3].Metadata = map[string]interface{}{"source_file": "other & co.xml"}
+	return &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{g1, g2},
+		Metadata: map[string]interface{}{"source_file": "guide.xml", "total_tokens": 100},
+	}
+}
+
+func TestSVGReport_ChartsAreWellFormed(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	outPath := filepath.Join(tmpDir, "charts.svg")
+	if err := fw.GenerateReport(svgTestResult(), "svg", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var doc struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil || doc.XMLName.Local != "svg" {
+		t.Fatalf("expected well-formed SVG (err %v):\n%s", err, data)
+	}
+	svg := string(data)
+	for _, want := range []string{
+		"Clone coverage: guide.xml",
+		"Clone coverage: other &amp; co.xml",
+		"Fragments by length (tokens)",
+		"Groups by power (fragments per group)",
+		">2-3</text>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Fatalf("expected SVG to contain %q:\n%s", want, svg)
+		}
+	}
+
+	if err := fw.GenerateReportWithParams(svgTestResult(), "svg", outPath, map[string]interface{}{"chart": "power"}); err != nil {
+		t.Fatalf("GenerateReportWithParams: %v", err)
+	}
+	data, _ = os.ReadFile(outPath)
+	if strings.Contains(string(data), "Clone coverage") || !strings.Contains(string(data), "Groups by power") {
+		t.Fatalf("expected only the power chart:\n%s", data)
+	}
+	if err := fw.GenerateReportWithParams(svgTestResult(), "svg", outPath, map[string]interface{}{"chart": "pie"}); err == nil {
+		t.Fatal("expected error for unknown chart")
+	}
+}
+
+func TestReports_EmbedSVGCharts(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	htmlPath := filepath.Join(tmpDir, "report.html")
+	if err := fw.GenerateReport(svgTestResult(), "html", htmlPath); err != nil {
+		t.Fatalf("GenerateReport(html): %v", err)
+	}
+	page, _ := os.ReadFile(htmlPath)
+	if !strings.Contains(string(page), `<svg xmlns="http://www.w3.org/2000/svg"`) {
+		t.Fatalf("expected inline SVG charts in HTML report:\n%s", page)
+	}
+
+	mdPath := filepath.Join(tmpDir, "report.md")
+	if err := fw.GenerateReportWithParams(svgTestResult(), "md", mdPath, map[string]interface{}{"charts": true}); err != nil {
+		t.Fatalf("GenerateReport(md): %v", err)
+	}
+	md, _ := os.ReadFile(mdPath)
+	if !strings.Contains(string(md), "](<report-power.svg>)") {
+		t.Fatalf("expected chart links in Markdown report:\n%s", md)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "report-heatmap-guide.svg")); err != nil {
+		t.Fatalf("expected sidecar heatmap: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func readTable(t *testing.T, path string, comma rune) [][]string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\uFEFF")))
+	r.Comma = comma
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("parse report: %v\n%s", err, data)
+	}
+	return records
+}
+
+func TestFragmentTableReport_OneRowPerFragment(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir})
+
+	long := cloneGroup(`a long "quoted" text; with, delimiters and more than three tokens`, 3)
+	long.Metadata = map[string]interface{}{
+		framework.GroupIDMetadataKey:    "g1",
+		framework.SuppressedMetadataKey: true,
+		framework.DiffStatusMetadataKey: framework.GroupGrown,
+		framework.OldPowerMetadataKey:   2,
+		framework.NewPowerMetadataKey:   3,
+	}
+	long.Fragments[1].Content = "second\nline"
+	long.Fragments[1].Metadata = map[string]interface{}{"source_line_start": 4, "source_line_end": 5}
+	long.Fragments[2].Metadata = map[string]interface{}{"source_file": "other.xml"}
+	result := &framework.AnalysisResult{
+		Groups:   []framework.CloneGroup{long, cloneGroup("short term", 2)},
+		Metadata: map[string]interface{}{"source_file": "doc.xml"},
+	}
+
+	csvPath := filepath.Join(tmpDir, "report.csv")
+	if err := fw.GenerateReport(result, "csv", csvPath); err != nil {
+		t.Fatalf("GenerateReport(csv): %v", err)
+	}
+	if data, _ := os.ReadFile(csvPath); strings.HasPrefix(string(data), "\uFEFF") {
+		t.Fatal("expected no BOM unless the bom parameter is set")
+	}
+	records := readTable(t, csvPath, ',')
+	if len(records) != 1+5 {
+		t.Fatalf("expected header and 5 fragment rows, got %d: %v", len(records), records)
+	}
+	if got := strings.Join(records[0], ","); got != "group_id,group,power,fragment,start_pos,end_pos,line_start,line_end,file,status,text" {
+		t.Fatalf("unexpected header %q", got)
+	}
+	if records[1][0] != "g1" || records[1][10] != long.Archetype {
+		t.Fatalf("text not preserved: %v", records[1])
+	}
+	if records[1][9] != "accepted, grown (2 → 3)" {
+		t.Fatalf("expected all badges in the status column, got %q", records[1][9])
+	}
+	if records[2][6] != "4" || records[2][7] != "5" || records[2][10] != "second\nline" {
+		t.Fatalf("unexpected second row: %v", records[2])
+	}
+	if records[3][8] != "other.xml" || records[4][8] != "doc.xml" {
+		t.Fatalf("unexpected file columns: %v / %v", records[3], records[4])
+	}
+
+	tsvPath := filepath.Join(tmpDir, "report.tsv")
+	if err := fw.GenerateReport(result, "tsv", tsvPath); err != nil {
+		t.Fatalf("GenerateReport(tsv): %v", err)
+	}
+	if got := len(readTable(t, tsvPath, '\t')); got != 6 {
+		t.Fatalf("expected 6 tsv records, got %d", got)
+	}
+
+	semiPath := filepath.Join(tmpDir, "semi.csv")
+	if err := fw.GenerateReportWithParams(result, "csv", semiPath, map[string]interface{}{"delimiter": ";"}); err != nil {
+		t.Fatalf("GenerateReportWithParams: %v", err)
+	}
+	if got := readTable(t, semiPath, ';'); got[1][10] != long.Archetype {
+		t.Fatalf("text not preserved with ';' delimiter: %v", got[1])
+	}
+	if err := fw.GenerateReportWithParams(result, "csv", semiPath, map[string]interface{}{"delimiter": `"`}); err == nil {
+		t.Fatal("expected error for quote delimiter")
+	}
+
+	bomPath := filepath.Join(tmpDir, "bom.csv")
+	if err := fw.GenerateReportWithParams(result, "csv", bomPath, map[string]interface{}{"bom": true}); err != nil {
+		t.Fatalf("GenerateReportWithParams(bom): %v", err)
+	}
+	if data, _ := os.ReadFile(bomPath); !strings.HasPrefix(string(data), "\uFEFF") {
+		t.Fatal("expected a BOM with bom=true")
+	}
+
+	shortPath := filepath.Join(tmpDir, "short.csv")
+	if err := fw.GenerateReport(result, "short-terms-csv", shortPath); err != nil {
+		t.Fatalf("GenerateReport(short-terms-csv): %v", err)
+	}
+	short := readTable(t, shortPath, ';')
+	if len(short) != 2 || short[1][2] != "short term" {
+		t.Fatalf("expected only the short term, got %v", short)
+	}
+}
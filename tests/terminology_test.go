@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	alg "github.com/PavelMkr/docline-new/internal/algorithms"
+	"github.com/PavelMkr/docline-new/internal/framework"
+)
+
+func TestFindTerminology_ClustersVariants(t *testing.T) {
+	text := "Log in to the portal. After login, open the dashboard. " +
+		"Use the log-in page (login) to sign in. The dashboard shows the API key. " +
+		"Copy the API key from the dashboard."
+
+	clusters := alg.FindTerminology(text, alg.TerminologySettings{MaxPhraseLength: 5, MinFrequency: 2})
+	byKey := map[string]alg.TermCluster{}
+	for _, c := range clusters {
+		byKey[c.Key] = c
+	}
+
+	login, ok := byKey["login"]
+	if !ok {
+		t.Fatalf("expected a login cluster, got %+v", clusters)
+	}
+	if !login.Inconsistent() || len(login.Occurrences) != 4 {
+		t.Fatalf("expected 4 inconsistent login occurrences, got %+v", login)
+	}
+	for _, v := range []string{"log in", "login", "log-in"} {
+		if login.Variants[v] == 0 {
+			t.Fatalf("expected variant %q in %v", v, login.Variants)
+		}
+	}
+	if login.Preferred != "login" {
+		t.Fatalf("expected preferred spelling login, got %q", login.Preferred)
+	}
+
+	apiKey, ok := byKey["apikey"]
+	if !ok || apiKey.Inconsistent() || apiKey.Preferred != "API key" {
+		t.Fatalf("expected consistent API key term, got %+v", apiKey)
+	}
+	if dash := byKey["dashboard"]; len(dash.Occurrences) != 3 {
+		t.Fatalf("expected dashboard 3 times, got %+v", dash)
+	}
+	if !clusters[0].Inconsistent() {
+		t.Fatalf("expected inconsistent terms first, got %+v", clusters[0])
+	}
+	if _, ok := byKey["portalafter"]; ok {
+		t.Fatal("phrases must not cross sentence boundaries")
+	}
+}
+
+func TestStreamTerminology_EmitsTermsWhenComplete(t *testing.T) {
+	text := "Open the dashboard. The dashboard loads. " +
+		"Log in first. Use the login page. The login page and the login form share the login button."
+	settings := alg.TerminologySettings{MaxPhraseLength: 3, MinFrequency: 2}
+
+	var order []string
+	err := alg.StreamTerminology(text, settings, func(c alg.TermCluster) error {
+		order = append(order, c.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTerminology: %v", err)
+	}
+	// The dashboard is complete before any login occurrence is read, so it is
+	// emitted first although the sorted result lists the login term first.
+	sorted := alg.FindTerminology(text, settings)
+	if len(order) != len(sorted) || order[0] != "dashboard" || sorted[0].Key != "login" {
+		t.Fatalf("streamed %v, sorted %+v", order, sorted)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = alg.StreamTerminology(text, settings, func(alg.TermCluster) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Fatalf("expected the emit error after one cluster, got %v after %d", err, calls)
+	}
+}
+
+func TestGlossaryReport_FromTerminologyFinder(t *testing.T) {
+	tmpDir := t.TempDir()
+	docPath := filepath.Join(tmpDir, "doc.xml")
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<book>
+	<para>Log in with your account.</para>
+	<para>The login page is slow.</para>
+	<para>Reset the password on the login page.</para>
+</book>`
+	if err := os.WriteFile(docPath, []byte(doc), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+
+	fw := newTestFramework(t, &framework.Config{ResultsDirectory: tmpDir, DefaultTokenizer: "space"})
+	result, err := fw.AnalyzeDocument(docPath, "terminology", framework.CloneFinderConfig{
+		CustomParams: map[string]interface{}{"inconsistent_only": true},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeDocument: %v", err)
+	}
+	if len(result.Groups) != 1 || result.Groups[0].Archetype != "login" {
+		t.Fatalf("expected a single inconsistent login term, got %+v", result.Groups)
+	}
+
+	outPath := filepath.Join(tmpDir, "glossary.md")
+	if err := fw.GenerateReport(result, "glossary", outPath); err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	for _, want := range []string{"| login | 3 |", "login (2), log in (1)", "**inconsistent**", "doc.xml:2`"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("expected glossary to contain %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PavelMkr/docline-new/internal/watch"
+)
+
+func TestWatcher_DebouncesAndReportsRemovals(t *testing.T) {
+	dir := t.TempDir()
+	guide := filepath.Join(dir, "guide.xml")
+	notes := filepath.Join(dir, "notes.log")
+	hidden := filepath.Join(dir, ".git", "index.xml")
+	for _, p := range []string{guide, notes, hidden} {
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte("v1"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := watch.New([]string{dir}, watch.Options{
+		Interval: 5 * time.Millisecond,
+		Debounce: 60 * time.Millisecond,
+		Match:    func(p string) bool { return strings.HasSuffix(p, ".xml") },
+	})
+	changes := make(chan watch.Change, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx, func(c watch.Change) { changes <- c }) }()
+
+	next := func() watch.Change {
+		t.Helper()
+		select {
+		case c := <-changes:
+			return c
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a change")
+			return watch.Change{}
+		}
+	}
+
+	if c := next(); !reflect.DeepEqual(c.Modified, []string{guide}) || len(c.Removed) != 0 {
+		t.Fatalf("initial change should list guide.xml only, got %+v", c)
+	}
+
+	// A burst of writes within the debounce time is reported once.
+	added := filepath.Join(dir, "sub", "added.xml")
+	os.MkdirAll(filepath.Dir(added), 0o755)
+	for i, content := range []string{"v22", "v333", "v4444"} {
+		os.WriteFile(guide, []byte(content), 0o644)
+		if i == 1 {
+			os.WriteFile(added, []byte("new"), 0o644)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if c := next(); !reflect.DeepEqual(c.Modified, []string{guide, added}) {
+		t.Fatalf("expected one debounced change for guide.xml and sub/added.xml, got %+v", c)
+	}
+
+	os.Remove(added)
+	if c := next(); len(c.Modified) != 0 || !reflect.DeepEqual(c.Removed, []string{added}) {
+		t.Fatalf("expected removal of sub/added.xml, got %+v", c)
+	}
+
+	select {
+	case c := <-changes:
+		t.Fatalf("unexpected change %+v", c)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestWatcher_MissingPathFailsAtStart(t *testing.T) {
+	w := watch.New([]string{filepath.Join(t.TempDir(), "missing.xml")}, watch.Options{})
+	if err := w.Run(context.Background(), func(watch.Change) {}); err == nil {
+		t.Fatal("expected an error for a missing path")
+	}
+}